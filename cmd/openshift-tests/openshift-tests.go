@@ -23,6 +23,7 @@ import (
 	run_disruption "github.com/openshift/origin/pkg/cmd/openshift-tests/run-disruption"
 	run_test "github.com/openshift/origin/pkg/cmd/openshift-tests/run-test"
 	run_upgrade "github.com/openshift/origin/pkg/cmd/openshift-tests/run-upgrade"
+	simulate_offline_expiry "github.com/openshift/origin/pkg/cmd/openshift-tests/simulate-offline-expiry"
 	run_resourcewatch "github.com/openshift/origin/pkg/resourcewatch/cmd"
 	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
 	exutil "github.com/openshift/origin/test/extended/util"
@@ -86,10 +87,13 @@ func main() {
 		disruption.NewDisruptionCommand(ioStreams),
 		risk_analysis.NewTestFailureRiskAnalysisCommand(),
 		run_resourcewatch.NewRunResourceWatchCommand(),
+		run_resourcewatch.NewResourceWatchHistoryCommand(ioStreams),
+		run_resourcewatch.NewResourceWatchIntervalsCommand(ioStreams),
 		timeline.NewTimelineCommand(ioStreams),
 		run_disruption.NewRunInClusterDisruptionMonitorCommand(ioStreams),
 		collectdiskcertificates.NewRunCollectDiskCertificatesCommand(ioStreams),
 		render.NewRenderCommand(ioStreams),
+		simulate_offline_expiry.NewSimulateOfflineExpiryCommand(ioStreams),
 	)
 
 	f := flag.CommandLine.Lookup("v")
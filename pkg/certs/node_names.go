@@ -0,0 +1,93 @@
+package certs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// NodeRole is the role node names are rewritten with: master for control plane nodes, the first custom role of a
+// node in a custom pool like infra, and worker otherwise.
+func NodeRole(node *corev1.Node) string {
+	roles := []string{}
+	for label := range node.Labels {
+		if !strings.HasPrefix(label, nodeRoleLabelPrefix) {
+			continue
+		}
+		role := strings.TrimPrefix(label, nodeRoleLabelPrefix)
+		if role == "control-plane" || role == "master" {
+			return "master"
+		}
+		if role != "worker" && len(role) > 0 {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return "worker"
+	}
+	sort.Strings(roles)
+	return roles[0]
+}
+
+// NodeNameReplacements maps the names of the nodes to <role-N>, numbering the nodes of each role in name order, so
+// that the PKI lists of different runs can be compared.  Control plane nodes are named <master-N>, like
+// certgraphanalysis.RewriteNodeNames does.
+func NodeNameReplacements(nodes []*corev1.Node, bootstrapHostname string) map[string]string {
+	sorted := append([]*corev1.Node{}, nodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	ret := map[string]string{}
+	countByRole := map[string]int{}
+	for _, node := range sorted {
+		role := NodeRole(node)
+		ret[node.Name] = fmt.Sprintf("<%s-%d>", role, countByRole[role])
+		countByRole[role]++
+	}
+	if len(bootstrapHostname) != 0 {
+		ret[bootstrapHostname] = "<bootstrap>"
+	}
+	return ret
+}
+
+// RewriteOnDiskNodeNames replaces the node names in the on-disk locations of the PKI list.  Longer names are replaced
+// first, so that a node name that is a prefix of another, like ip-10-0-1-1 of ip-10-0-1-10, does not corrupt it.
+func RewriteOnDiskNodeNames(pkiList *certgraphapi.PKIList, replacements map[string]string) {
+	nodeNames := []string{}
+	for nodeName := range replacements {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Slice(nodeNames, func(i, j int) bool {
+		if len(nodeNames[i]) != len(nodeNames[j]) {
+			return len(nodeNames[i]) > len(nodeNames[j])
+		}
+		return nodeNames[i] < nodeNames[j]
+	})
+	rewrite := func(path string) string {
+		for _, nodeName := range nodeNames {
+			path = strings.ReplaceAll(path, nodeName, replacements[nodeName])
+		}
+		return path
+	}
+
+	for i := range pkiList.CertKeyPairs.Items {
+		locations := pkiList.CertKeyPairs.Items[i].Spec.OnDiskLocations
+		for j := range locations {
+			locations[j].Cert.Path = rewrite(locations[j].Cert.Path)
+			locations[j].Key.Path = rewrite(locations[j].Key.Path)
+		}
+	}
+	for i := range pkiList.CertificateAuthorityBundles.Items {
+		locations := pkiList.CertificateAuthorityBundles.Items[i].Spec.OnDiskLocations
+		for j := range locations {
+			locations[j].Path = rewrite(locations[j].Path)
+		}
+	}
+	for i := range pkiList.OnDiskResourceData.TLSArtifact {
+		pkiList.OnDiskResourceData.TLSArtifact[i].Path = rewrite(pkiList.OnDiskResourceData.TLSArtifact[i].Path)
+	}
+}
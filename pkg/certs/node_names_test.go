@@ -0,0 +1,55 @@
+package certs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func node(name string, roles ...string) *corev1.Node {
+	labels := map[string]string{}
+	for _, role := range roles {
+		labels[nodeRoleLabelPrefix+role] = ""
+	}
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestNodeNameReplacements(t *testing.T) {
+	nodes := []*corev1.Node{
+		node("ip-10-0-1-10", "worker"),
+		node("ip-10-0-1-1", "control-plane", "master"),
+		node("ip-10-0-1-2", "worker"),
+		node("ip-10-0-1-3", "worker", "infra"),
+		node("ip-10-0-1-4", "worker", "gpu", "storage"),
+	}
+	expected := map[string]string{
+		"ip-10-0-1-1":  "<master-0>",
+		"ip-10-0-1-10": "<worker-0>",
+		"ip-10-0-1-2":  "<worker-1>",
+		"ip-10-0-1-3":  "<infra-0>",
+		"ip-10-0-1-4":  "<gpu-0>",
+		"bootstrap":    "<bootstrap>",
+	}
+	replacements := NodeNameReplacements(nodes, "bootstrap")
+	if !reflect.DeepEqual(replacements, expected) {
+		t.Errorf("expected %v, got %v", expected, replacements)
+	}
+
+	pkiList := &certgraphapi.PKIList{}
+	pkiList.CertKeyPairs.Items = []certgraphapi.CertKeyPair{{
+		Spec: certgraphapi.CertKeyPairSpec{
+			OnDiskLocations: []certgraphapi.OnDiskCertKeyPairLocation{{
+				Cert: certgraphapi.OnDiskLocation{Path: "/etc/kubernetes/etcd-peer-ip-10-0-1-10.crt"},
+				Key:  certgraphapi.OnDiskLocation{Path: "/etc/kubernetes/etcd-peer-ip-10-0-1-1.key"},
+			}},
+		},
+	}}
+	RewriteOnDiskNodeNames(pkiList, replacements)
+	actual := pkiList.CertKeyPairs.Items[0].Spec.OnDiskLocations[0]
+	if actual.Cert.Path != "/etc/kubernetes/etcd-peer-<worker-0>.crt" || actual.Key.Path != "/etc/kubernetes/etcd-peer-<master-0>.key" {
+		t.Errorf("unexpected rewritten paths %+v", actual)
+	}
+}
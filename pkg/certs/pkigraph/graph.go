@@ -0,0 +1,301 @@
+package pkigraph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type NodeKind string
+
+const (
+	SignerNode      NodeKind = "Signer"
+	CertKeyPairNode NodeKind = "CertKeyPair"
+	CABundleNode    NodeKind = "CABundle"
+)
+
+// Change is how a node or edge differs from the base graph of a diff.
+type Change string
+
+const (
+	Unchanged Change = ""
+	Added     Change = "Added"
+	Removed   Change = "Removed"
+	// Reparented nodes are signed by, or for CA bundles contain, different signers than in the base graph.
+	Reparented Change = "Reparented"
+)
+
+// Node is a signer, cert/key pair or CA bundle.  Nodes are identified by where they are stored rather than by their
+// serial numbers, so that the graphs of different clusters can be compared.
+type Node struct {
+	ID   string
+	Kind NodeKind
+	// CommonName is the common name of the certificate, without the timestamp suffix operators add to signers.
+	CommonName string
+	// Locations are the secrets, configmaps and files the node is stored in.  Signers that are not stored in the
+	// cluster, like the signers of proxy CAs, have none.
+	Locations []string
+	Change    Change
+}
+
+// Edge is from a signer to a cert/key pair it signed, or to a CA bundle that contains it.
+type Edge struct {
+	From   string
+	To     string
+	Change Change
+}
+
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+func (g *Graph) node(id string) (Node, bool) {
+	i := sort.Search(len(g.Nodes), func(i int) bool { return g.Nodes[i].ID >= id })
+	if i < len(g.Nodes) && g.Nodes[i].ID == id {
+		return g.Nodes[i], true
+	}
+	return Node{}, false
+}
+
+// parents returns the signers of every node.
+func (g *Graph) parents() map[string]sets.String {
+	ret := map[string]sets.String{}
+	for _, edge := range g.Edges {
+		if _, ok := ret[edge.To]; !ok {
+			ret[edge.To] = sets.NewString()
+		}
+		ret[edge.To].Insert(edge.From)
+	}
+	return ret
+}
+
+var signerTimestamp = regexp.MustCompile(`@\d+(\||$)`)
+
+// normalizeCommonName removes the timestamp operators add to the common names of the signers they generate.  CA bundles
+// that are only on disk are named after all of their certificates, separated by |.
+func normalizeCommonName(commonName string) string {
+	return signerTimestamp.ReplaceAllString(commonName, "$1")
+}
+
+func secretLocations(locations []certgraphapi.InClusterSecretLocation) []string {
+	ret := []string{}
+	for _, location := range locations {
+		ret = append(ret, fmt.Sprintf("secret/%s/%s", location.Namespace, location.Name))
+	}
+	return ret
+}
+
+func configMapLocations(locations []certgraphapi.InClusterConfigMapLocation) []string {
+	ret := []string{}
+	for _, location := range locations {
+		ret = append(ret, fmt.Sprintf("configmap/%s/%s", location.Namespace, location.Name))
+	}
+	return ret
+}
+
+func certKeyPairOnDiskLocations(locations []certgraphapi.OnDiskCertKeyPairLocation) []string {
+	ret := []string{}
+	for _, location := range locations {
+		path := location.Cert.Path
+		if len(path) == 0 {
+			path = location.Key.Path
+		}
+		if len(path) > 0 {
+			ret = append(ret, "file/"+path)
+		}
+	}
+	return ret
+}
+
+func onDiskLocations(locations []certgraphapi.OnDiskLocation) []string {
+	ret := []string{}
+	for _, location := range locations {
+		if len(location.Path) > 0 {
+			ret = append(ret, "file/"+location.Path)
+		}
+	}
+	return ret
+}
+
+// nodeID is the first location of the node, or its kind and common name if it is not stored anywhere.
+func nodeID(kind NodeKind, commonName string, locations []string) string {
+	if len(locations) > 0 {
+		return locations[0]
+	}
+	return fmt.Sprintf("%s/%s", strings.ToLower(string(kind)), commonName)
+}
+
+// graphBuilder merges the PKILists of several clusters into one graph.
+type graphBuilder struct {
+	nodes map[string]*Node
+	edges map[Edge]bool
+}
+
+// NewGraph builds the signer to cert/key pair and CA bundle graph of the raw TLS data.
+func NewGraph(rawData []*certgraphapi.PKIList) *Graph {
+	b := &graphBuilder{
+		nodes: map[string]*Node{},
+		edges: map[Edge]bool{},
+	}
+	for _, pkiList := range rawData {
+		b.add(pkiList)
+	}
+
+	g := &Graph{}
+	for _, node := range b.nodes {
+		g.Nodes = append(g.Nodes, *node)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	for edge := range b.edges {
+		g.Edges = append(g.Edges, edge)
+	}
+	sortEdges(g.Edges)
+	return g
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+}
+
+func (b *graphBuilder) addNode(kind NodeKind, commonName string, locations []string) string {
+	sort.Strings(locations)
+	id := nodeID(kind, commonName, locations)
+	existing, ok := b.nodes[id]
+	if !ok {
+		b.nodes[id] = &Node{ID: id, Kind: kind, CommonName: commonName, Locations: locations}
+		return id
+	}
+	existing.Locations = sets.NewString(existing.Locations...).Insert(locations...).List()
+	return id
+}
+
+func (b *graphBuilder) add(pkiList *certgraphapi.PKIList) {
+	// signers are found by the common name certificates record for their issuer, which is only unique in a cluster.
+	signersByCommonName := map[string]string{}
+	certKeyPairIDs := make([]string, len(pkiList.CertKeyPairs.Items))
+	for i, certKeyPair := range pkiList.CertKeyPairs.Items {
+		kind := CertKeyPairNode
+		if certKeyPair.Spec.Details.CertType == "SignerCertDetails" {
+			kind = SignerNode
+		}
+		commonName := certKeyPair.Spec.CertMetadata.CertIdentifier.CommonName
+		locations := append(secretLocations(certKeyPair.Spec.SecretLocations), certKeyPairOnDiskLocations(certKeyPair.Spec.OnDiskLocations)...)
+		id := b.addNode(kind, normalizeCommonName(commonName), locations)
+		certKeyPairIDs[i] = id
+		if kind == SignerNode || len(signersByCommonName[commonName]) == 0 {
+			signersByCommonName[commonName] = id
+		}
+	}
+	signerID := func(commonName string) string {
+		if id, ok := signersByCommonName[commonName]; ok {
+			return id
+		}
+		// a signer that is not stored in the cluster.
+		return b.addNode(SignerNode, normalizeCommonName(commonName), nil)
+	}
+
+	for i, certKeyPair := range pkiList.CertKeyPairs.Items {
+		identifier := certKeyPair.Spec.CertMetadata.CertIdentifier
+		if identifier.Issuer == nil || len(identifier.Issuer.CommonName) == 0 || identifier.Issuer.CommonName == identifier.CommonName {
+			// self-signed
+			continue
+		}
+		b.edges[Edge{From: signerID(identifier.Issuer.CommonName), To: certKeyPairIDs[i]}] = true
+	}
+
+	for _, caBundle := range pkiList.CertificateAuthorityBundles.Items {
+		locations := append(configMapLocations(caBundle.Spec.ConfigMapLocations), onDiskLocations(caBundle.Spec.OnDiskLocations)...)
+		id := b.addNode(CABundleNode, normalizeCommonName(caBundle.Name), locations)
+		for _, certificate := range caBundle.Spec.CertificateMetadata {
+			b.edges[Edge{From: signerID(certificate.CertIdentifier.CommonName), To: id}] = true
+		}
+	}
+}
+
+// Diff returns the union of the graphs, with what was added to, removed from and re-parented in updated compared to
+// base.
+func Diff(base, updated *Graph) *Graph {
+	ret := &Graph{}
+
+	baseParents := base.parents()
+	updatedParents := updated.parents()
+	ids := sets.NewString()
+	for _, node := range base.Nodes {
+		ids.Insert(node.ID)
+	}
+	for _, node := range updated.Nodes {
+		ids.Insert(node.ID)
+	}
+	for _, id := range ids.List() {
+		baseNode, inBase := base.node(id)
+		updatedNode, inUpdated := updated.node(id)
+		switch {
+		case !inBase:
+			updatedNode.Change = Added
+			ret.Nodes = append(ret.Nodes, updatedNode)
+		case !inUpdated:
+			baseNode.Change = Removed
+			ret.Nodes = append(ret.Nodes, baseNode)
+		default:
+			if !baseParents[id].Equal(updatedParents[id]) && len(baseParents[id]) > 0 && len(updatedParents[id]) > 0 {
+				updatedNode.Change = Reparented
+			}
+			updatedNode.Locations = sets.NewString(baseNode.Locations...).Insert(updatedNode.Locations...).List()
+			ret.Nodes = append(ret.Nodes, updatedNode)
+		}
+	}
+
+	baseEdges := map[Edge]bool{}
+	for _, edge := range base.Edges {
+		baseEdges[edge] = true
+	}
+	for _, edge := range updated.Edges {
+		if baseEdges[edge] {
+			delete(baseEdges, edge)
+			ret.Edges = append(ret.Edges, edge)
+			continue
+		}
+		ret.Edges = append(ret.Edges, Edge{From: edge.From, To: edge.To, Change: Added})
+	}
+	for edge := range baseEdges {
+		ret.Edges = append(ret.Edges, Edge{From: edge.From, To: edge.To, Change: Removed})
+	}
+	sortEdges(ret.Edges)
+	return ret
+}
+
+// DiffSummary lists the nodes that changed, one per line.
+func (g *Graph) DiffSummary() []string {
+	parents := map[Change]map[string]sets.String{Added: {}, Removed: {}}
+	for _, edge := range g.Edges {
+		if edge.Change == Unchanged {
+			continue
+		}
+		if _, ok := parents[edge.Change][edge.To]; !ok {
+			parents[edge.Change][edge.To] = sets.NewString()
+		}
+		parents[edge.Change][edge.To].Insert(edge.From)
+	}
+
+	ret := []string{}
+	for _, node := range g.Nodes {
+		switch node.Change {
+		case Added, Removed:
+			ret = append(ret, fmt.Sprintf("%s %s %s", node.Change, node.Kind, node.ID))
+		case Reparented:
+			ret = append(ret, fmt.Sprintf("%s %s %s: signers %v added, %v removed", node.Change, node.Kind, node.ID,
+				parents[Added][node.ID].List(), parents[Removed][node.ID].List()))
+		}
+	}
+	return ret
+}
@@ -0,0 +1,108 @@
+package pkigraph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+)
+
+func certKeyPair(commonName, issuer, certType, namespace, name string) certgraphapi.CertKeyPair {
+	ret := certgraphapi.CertKeyPair{}
+	ret.Spec.CertMetadata.CertIdentifier = certgraphapi.CertIdentifier{
+		CommonName: commonName,
+		Issuer:     &certgraphapi.CertIdentifier{CommonName: issuer},
+	}
+	ret.Spec.Details.CertType = certType
+	ret.Spec.SecretLocations = []certgraphapi.InClusterSecretLocation{{Namespace: namespace, Name: name}}
+	return ret
+}
+
+func caBundle(namespace, name string, commonNames ...string) certgraphapi.CertificateAuthorityBundle {
+	ret := certgraphapi.CertificateAuthorityBundle{}
+	ret.Spec.ConfigMapLocations = []certgraphapi.InClusterConfigMapLocation{{Namespace: namespace, Name: name}}
+	for _, commonName := range commonNames {
+		ret.Spec.CertificateMetadata = append(ret.Spec.CertificateMetadata, certgraphapi.CertKeyMetadata{
+			CertIdentifier: certgraphapi.CertIdentifier{CommonName: commonName},
+		})
+	}
+	return ret
+}
+
+func pkiList(certKeyPairs []certgraphapi.CertKeyPair, caBundles ...certgraphapi.CertificateAuthorityBundle) *certgraphapi.PKIList {
+	ret := &certgraphapi.PKIList{}
+	ret.CertKeyPairs.Items = certKeyPairs
+	ret.CertificateAuthorityBundles.Items = caBundles
+	return ret
+}
+
+func TestNewGraph(t *testing.T) {
+	graph := NewGraph([]*certgraphapi.PKIList{pkiList(
+		[]certgraphapi.CertKeyPair{
+			certKeyPair("signer@1700000000", "signer@1700000000", "SignerCertDetails", "ns", "signer"),
+			certKeyPair("serving", "signer@1700000000", "ServingCertDetails", "ns", "serving"),
+			certKeyPair("client", "external-signer", "ClientCertDetails", "ns", "client"),
+		},
+		caBundle("ns", "ca", "signer@1700000000", "external-signer"),
+	)})
+
+	actual := []string{}
+	for _, node := range graph.Nodes {
+		actual = append(actual, string(node.Kind)+" "+node.ID+" "+node.CommonName)
+	}
+	for _, edge := range graph.Edges {
+		actual = append(actual, edge.From+" -> "+edge.To)
+	}
+	expected := []string{
+		"CABundle configmap/ns/ca ",
+		"CertKeyPair secret/ns/client client",
+		"CertKeyPair secret/ns/serving serving",
+		"Signer secret/ns/signer signer",
+		"Signer signer/external-signer external-signer",
+		"secret/ns/signer -> configmap/ns/ca",
+		"secret/ns/signer -> secret/ns/serving",
+		"signer/external-signer -> configmap/ns/ca",
+		"signer/external-signer -> secret/ns/client",
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	base := NewGraph([]*certgraphapi.PKIList{pkiList(
+		[]certgraphapi.CertKeyPair{
+			certKeyPair("signer@1700000000", "signer@1700000000", "SignerCertDetails", "ns", "signer"),
+			certKeyPair("serving", "signer@1700000000", "ServingCertDetails", "ns", "serving"),
+			certKeyPair("old", "signer@1700000000", "ServingCertDetails", "ns", "old"),
+		},
+		caBundle("ns", "ca", "signer@1700000000"),
+	)})
+	// the signer was rotated, which must not be a change, and serving is now signed by a new signer.
+	updated := NewGraph([]*certgraphapi.PKIList{pkiList(
+		[]certgraphapi.CertKeyPair{
+			certKeyPair("signer@1800000000", "signer@1800000000", "SignerCertDetails", "ns", "signer"),
+			certKeyPair("new-signer", "new-signer", "SignerCertDetails", "ns", "new-signer"),
+			certKeyPair("serving", "new-signer", "ServingCertDetails", "ns", "serving"),
+		},
+		caBundle("ns", "ca", "signer@1800000000"),
+	)})
+
+	diff := Diff(base, updated)
+	expected := []string{
+		"Added Signer secret/ns/new-signer",
+		"Removed CertKeyPair secret/ns/old",
+		"Reparented CertKeyPair secret/ns/serving: signers [secret/ns/new-signer] added, [secret/ns/signer] removed",
+	}
+	if actual := diff.DiffSummary(); strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	dot := string(diff.DOT())
+	if !strings.Contains(dot, `"secret/ns/signer" -> "secret/ns/old" [color=red, style=dashed];`) {
+		t.Errorf("expected the removed edge to be dashed red, got:\n%s", dot)
+	}
+	if _, err := diff.HTML(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,111 @@
+package pkigraph
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed pki-graph.html
+var htmlTemplate string
+
+var (
+	nodeShapes = map[NodeKind]string{
+		SignerNode:      "octagon",
+		CertKeyPairNode: "box",
+		CABundleNode:    "folder",
+	}
+	changeColors = map[Change]string{
+		Unchanged:  "black",
+		Added:      "green",
+		Removed:    "red",
+		Reparented: "orange",
+	}
+)
+
+func (n Node) label() string {
+	lines := []string{fmt.Sprintf("%s: %s", n.Kind, n.CommonName)}
+	lines = append(lines, n.Locations...)
+	if n.Change != Unchanged {
+		lines = append(lines, string(n.Change))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DOT returns the graph in the graphviz DOT language.
+func (g *Graph) DOT() []byte {
+	out := &bytes.Buffer{}
+	fmt.Fprintln(out, "digraph PKI {")
+	fmt.Fprintln(out, "  rankdir=LR;")
+	fmt.Fprintln(out, "  node [fontsize=10];")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(out, "  %q [label=%q, shape=%s, color=%s];\n", node.ID, node.label(), nodeShapes[node.Kind], changeColors[node.Change])
+	}
+	for _, edge := range g.Edges {
+		style := "solid"
+		if edge.Change == Removed {
+			style = "dashed"
+		}
+		fmt.Fprintf(out, "  %q -> %q [color=%s, style=%s];\n", edge.From, edge.To, changeColors[edge.Change], style)
+	}
+	fmt.Fprintln(out, "}")
+	return out.Bytes()
+}
+
+type visNode struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Title string `json:"title"`
+	Shape string `json:"shape"`
+	Color string `json:"color"`
+	Group string `json:"group"`
+}
+
+type visEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Color  string `json:"color"`
+	Dashes bool   `json:"dashes"`
+}
+
+var visShapes = map[NodeKind]string{
+	SignerNode:      "diamond",
+	CertKeyPairNode: "box",
+	CABundleNode:    "database",
+}
+
+// HTML returns a page that draws the graph with vis-network, so it can be explored in a browser.
+func (g *Graph) HTML() ([]byte, error) {
+	data := struct {
+		Nodes []visNode `json:"nodes"`
+		Edges []visEdge `json:"edges"`
+	}{
+		Nodes: []visNode{},
+		Edges: []visEdge{},
+	}
+	for _, node := range g.Nodes {
+		data.Nodes = append(data.Nodes, visNode{
+			ID:    node.ID,
+			Label: node.CommonName,
+			Title: node.label(),
+			Shape: visShapes[node.Kind],
+			Color: changeColors[node.Change],
+			Group: string(node.Kind),
+		})
+	}
+	for _, edge := range g.Edges {
+		data.Edges = append(data.Edges, visEdge{
+			From:   edge.From,
+			To:     edge.To,
+			Color:  changeColors[edge.Change],
+			Dashes: edge.Change == Removed,
+		})
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.Replace(htmlTemplate, "PKI_GRAPH_DATA_GOES_HERE", string(dataJSON), 1)), nil
+}
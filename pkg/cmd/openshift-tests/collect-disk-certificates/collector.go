@@ -11,6 +11,7 @@ import (
 
 	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphanalysis"
 	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/certs"
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -22,7 +23,6 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 )
 
 type RunCollectDiskCertificatesFlags struct {
@@ -162,16 +162,17 @@ func (o *RunCollectDiskCertificatesOptions) Run(ctx context.Context) error {
 	pkiList := &certgraphapi.PKIList{}
 	errs := []error{}
 
-	controlPlaneLabel := labels.SelectorFromSet(map[string]string{"node-role.kubernetes.io/control-plane": ""})
-
-	nodeList, err := o.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: controlPlaneLabel.String()})
+	// the names of every node are rewritten, whatever its role, so that the paths of certificates named after the
+	// node are the same in every run.
+	nodeList, err := o.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to list nodes: %v", err)
 	}
-	masters := []*corev1.Node{}
+	nodes := []*corev1.Node{}
 	for i := range nodeList.Items {
-		masters = append(masters, &nodeList.Items[i])
+		nodes = append(nodes, &nodeList.Items[i])
 	}
+	nodeNameReplacements := certs.NodeNameReplacements(nodes, "")
 
 	for _, srcDir := range o.CollectDirs {
 		dirPKIList, err := certgraphanalysis.GatherCertsFromDisk(ctx, o.KubeClient, srcDir,
@@ -180,11 +181,13 @@ func (o *RunCollectDiskCertificatesOptions) Run(ctx context.Context) error {
 			certgraphanalysis.SkipHashed,
 			certgraphanalysis.SkipRevisionedLocations,
 			certgraphanalysis.StripTimestamps,
-			certgraphanalysis.StripRootFSMountPoint(o.RootFSMountpoint),
-			certgraphanalysis.RewriteNodeNames(masters, ""))
+			certgraphanalysis.StripRootFSMountPoint(o.RootFSMountpoint))
 		if err != nil {
 			errs = append(errs, fmt.Errorf("%s: %s", srcDir, err))
 		}
+		if dirPKIList != nil {
+			certs.RewriteOnDiskNodeNames(dirPKIList, nodeNameReplacements)
+		}
 		pkiList = certgraphanalysis.MergePKILists(ctx, pkiList, dirPKIList)
 	}
 	if len(errs) > 0 {
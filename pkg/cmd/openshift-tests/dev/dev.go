@@ -1,17 +1,25 @@
 package dev
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/origin/pkg/alerts"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/monitortestlibrary/alertreferences"
 	"github.com/openshift/origin/pkg/monitortestlibrary/allowedalerts"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/monitortestlibrary/prometheussnapshot"
 	"github.com/openshift/origin/pkg/monitortests/network/legacynetworkmonitortests"
 	"github.com/openshift/origin/pkg/monitortests/testframework/legacytestframeworkmonitortests"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -27,6 +35,9 @@ func NewDevCommand() *cobra.Command {
 	cmd.AddCommand(
 		newRunAlertInvariantsCommand(),
 		newRunDisruptionInvariantsCommand(),
+		newCheckAlertReferencesCommand(),
+		newQueryPrometheusSnapshotCommand(),
+		newBuildHistoricalDataCommand(),
 	)
 	return cmd
 }
@@ -204,3 +215,195 @@ a running cluster.
 		"Topology for simulated cluster under test when intervals were gathered (ha, single)")
 	return cmd
 }
+
+func newCheckAlertReferencesCommand() *cobra.Command {
+	rulesPaths := []string{}
+
+	cmd := &cobra.Command{
+		Use:   "check-alert-references",
+		Short: "Check the alerts origin allows and tests against PrometheusRules on disk",
+		Long: templates.LongDesc(`
+Check the alert allowances and alert tests against the alerting rules of PrometheusRules
+on disk, for instance the manifests of a release payload or a must-gather.
+
+Fails when origin refers to alerts no rule defines, and lists critical alerts without
+an alert test.
+`),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(rulesPaths) == 0 {
+				return fmt.Errorf("--rules is required")
+			}
+			rules, err := alertreferences.ReadAlertRules(rulesPaths)
+			if err != nil {
+				return err
+			}
+			logrus.Infof("loaded %d alerting rules", len(rules))
+
+			report := alertreferences.Check(rules, alertreferences.OriginReferences())
+			fmt.Fprintln(cmd.OutOrStdout(), report.String())
+			if len(report.StaleReferences) > 0 {
+				return fmt.Errorf("%d references to alerts without an alerting rule", len(report.StaleReferences))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&rulesPaths,
+		"rules", rulesPaths,
+		"Files or directories with PrometheusRule manifests, directories are read recursively.")
+	return cmd
+}
+
+type queryPrometheusSnapshotOpts struct {
+	snapshotFile string
+	query        string
+	time         string
+	start        string
+	end          string
+	step         time.Duration
+}
+
+func newQueryPrometheusSnapshotCommand() *cobra.Command {
+	o := queryPrometheusSnapshotOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "query-prometheus-snapshot",
+		Short: "Query a prometheus snapshot captured during a CI run",
+		Long: templates.LongDesc(`
+Query a prometheus-snapshot json.gz file from a CI run.  Only the queries captured
+in the snapshot can be answered, refer to them by name or by the query itself.
+Captured selectors like ALERTS can be narrowed with label matchers, like
+ALERTS{alertstate="firing"}.  Without --query the captured queries are listed.
+
+Instant queries are answered with --time, range queries with --start and --end.
+Times are RFC3339.
+`),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshot, err := prometheussnapshot.ReadSnapshot(o.snapshotFile)
+			if err != nil {
+				return err
+			}
+			if len(o.query) == 0 {
+				for _, result := range snapshot.Results {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", result.Name, result.Query)
+				}
+				return nil
+			}
+
+			parseTime := func(value string, defaultTime time.Time) (time.Time, error) {
+				if len(value) == 0 {
+					return defaultTime, nil
+				}
+				return time.Parse(time.RFC3339, value)
+			}
+			var result interface{}
+			var warnings prometheusv1.Warnings
+			if len(o.start) > 0 || len(o.end) > 0 {
+				start, err := parseTime(o.start, snapshot.Start)
+				if err != nil {
+					return err
+				}
+				end, err := parseTime(o.end, snapshot.End)
+				if err != nil {
+					return err
+				}
+				result, warnings, err = snapshot.QueryRange(context.Background(), o.query, prometheusv1.Range{Start: start, End: end, Step: o.step})
+				if err != nil {
+					return err
+				}
+			} else {
+				ts, err := parseTime(o.time, snapshot.End)
+				if err != nil {
+					return err
+				}
+				result, warnings, err = snapshot.Query(context.Background(), o.query, ts)
+				if err != nil {
+					return err
+				}
+			}
+			for _, warning := range warnings {
+				logrus.Warn(warning)
+			}
+
+			jsonContent, err := json.MarshalIndent(result, "", "    ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(jsonContent))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&o.snapshotFile,
+		"snapshot", "prometheus-snapshot.json.gz",
+		"Path to a snapshot (i.e. prometheus-snapshot_20230214-203340.json.gz). Can be obtained from a CI run in openshift-tests junit artifacts.")
+	cmd.Flags().StringVar(&o.query, "query", o.query, "Name or query of a captured query.")
+	cmd.Flags().StringVar(&o.time, "time", o.time, "Time of an instant query, defaults to the end of the snapshot.")
+	cmd.Flags().StringVar(&o.start, "start", o.start, "Start of a range query, defaults to the start of the snapshot.")
+	cmd.Flags().StringVar(&o.end, "end", o.end, "End of a range query, defaults to the end of the snapshot.")
+	cmd.Flags().DurationVar(&o.step, "step", 30*time.Second, "Step of a range query, the snapshot answers with the step it was captured with.")
+	return cmd
+}
+
+type buildHistoricalDataOpts struct {
+	artifactsDir     string
+	disruptionOutput string
+	alertOutput      string
+}
+
+func newBuildHistoricalDataCommand() *cobra.Command {
+	o := buildHistoricalDataOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "build-historical-data",
+		Short: "Build historical disruption and alert data from the artifacts of past runs",
+		Long: templates.LongDesc(`
+Build historical disruption and alert data from the artifacts of past runs, for job
+types the upstream data does not cover.  Every directory under --artifacts-dir with a
+cluster-data json file is a run, its backend-disruption and alerts json files are read
+from the same directory.
+
+The P50, P75, P95 and P99 of every backend and alert are written in the format of the
+upstream query_results.json, with a sha256 file next to them.  Point
+DISRUPTION_HISTORICAL_DATA and ALERT_HISTORICAL_DATA at the output to use it.  The
+tests only use data from at least 100 runs of a job type.
+`),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseline, err := historicaldata.BuildBaseline(o.artifactsDir)
+			if err != nil {
+				return err
+			}
+
+			disruptionData, err := baseline.DisruptionJSON()
+			if err != nil {
+				return err
+			}
+			if err := historicaldata.WriteDataset(o.disruptionOutput, disruptionData); err != nil {
+				return err
+			}
+			logrus.Infof("wrote %d disruption entries to %s", len(baseline.Disruptions), o.disruptionOutput)
+
+			alertData, err := baseline.AlertJSON()
+			if err != nil {
+				return err
+			}
+			if err := historicaldata.WriteDataset(o.alertOutput, alertData); err != nil {
+				return err
+			}
+			logrus.Infof("wrote %d alert entries to %s", len(baseline.Alerts), o.alertOutput)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&o.artifactsDir,
+		"artifacts-dir", o.artifactsDir,
+		"Directory with the openshift-tests junit artifacts of past runs, one directory per run.")
+	cmd.Flags().StringVar(&o.disruptionOutput,
+		"disruption-output", "disruption-query_results.json",
+		"File to write the historical disruption data to.")
+	cmd.Flags().StringVar(&o.alertOutput,
+		"alert-output", "alert-query_results.json",
+		"File to write the historical alert data to.")
+	cmd.MarkFlagRequired("artifacts-dir")
+	return cmd
+}
@@ -9,6 +9,7 @@ import (
 	"syscall"
 
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
+	sampler_metrics "github.com/openshift/origin/pkg/cmd/openshift-tests/disruption/sampler-metrics"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
@@ -19,6 +20,7 @@ import (
 type PollServiceFlags struct {
 	ConfigFlags       *genericclioptions.ConfigFlags
 	OutputFlags       *iooptions.OutputFlags
+	MetricsFlags      *sampler_metrics.SamplerMetricsFlags
 	BackendPrefix     string
 	MyNodeName        string
 	StopConfigMapName string
@@ -30,9 +32,10 @@ type PollServiceFlags struct {
 
 func NewPollServiceFlags(streams genericclioptions.IOStreams) *PollServiceFlags {
 	return &PollServiceFlags{
-		ConfigFlags: genericclioptions.NewConfigFlags(false),
-		OutputFlags: iooptions.NewOutputOptions(),
-		IOStreams:   streams,
+		ConfigFlags:  genericclioptions.NewConfigFlags(false),
+		OutputFlags:  iooptions.NewOutputOptions(),
+		MetricsFlags: sampler_metrics.NewSamplerMetricsFlags(),
+		IOStreams:    streams,
 	}
 
 }
@@ -89,6 +92,7 @@ func (f *PollServiceFlags) BindOptions(flags *pflag.FlagSet) {
 	flags.StringVar(&f.BackendPrefix, "disruption-backend-prefix", f.BackendPrefix, "classification of disruption for the disruption summery")
 	f.ConfigFlags.AddFlags(flags)
 	f.OutputFlags.BindFlags(flags)
+	f.MetricsFlags.BindFlags(flags)
 }
 
 func (f *PollServiceFlags) Validate() error {
@@ -102,6 +106,9 @@ func (f *PollServiceFlags) Validate() error {
 	if len(f.BackendPrefix) == 0 {
 		return fmt.Errorf("must specify disruption-backend-prefix")
 	}
+	if err := f.MetricsFlags.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -132,6 +139,10 @@ func (f *PollServiceFlags) ToOptions() (*PollServiceOptions, error) {
 	if err != nil {
 		return nil, err
 	}
+	samplerMetrics, err := f.MetricsFlags.ToOptions()
+	if err != nil {
+		return nil, err
+	}
 	return &PollServiceOptions{
 		KubeClient:        kubeClient,
 		Namespace:         namespace,
@@ -141,6 +152,7 @@ func (f *PollServiceFlags) ToOptions() (*PollServiceOptions, error) {
 		Port:              f.ServicePort,
 		StopConfigMapName: f.StopConfigMapName,
 		MyNodeName:        f.MyNodeName,
+		SamplerMetrics:    samplerMetrics,
 		CloseFn:           closeFn,
 
 		OriginalOutFile: originalOutStream,
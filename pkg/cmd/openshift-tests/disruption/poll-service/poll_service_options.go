@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
+	sampler_metrics "github.com/openshift/origin/pkg/cmd/openshift-tests/disruption/sampler-metrics"
 	"github.com/openshift/origin/pkg/monitor"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/informers"
@@ -25,6 +26,9 @@ type PollServiceOptions struct {
 	MyNodeName        string
 	StopConfigMapName string
 
+	// SamplerMetrics is nil when metrics are disabled.
+	SamplerMetrics *sampler_metrics.SamplerMetricsOptions
+
 	OriginalOutFile io.Writer
 	CloseFn         iooptions.CloseFunc
 	genericclioptions.IOStreams
@@ -42,6 +46,10 @@ func (o *PollServiceOptions) Run(ctx context.Context) error {
 		o.OriginalOutFile.Write(startingContent)
 	}
 
+	if err := o.SamplerMetrics.Listen(); err != nil {
+		return err
+	}
+
 	recorder := monitor.WrapWithJSONLRecorder(monitor.NewRecorder(), o.IOStreams.Out, nil)
 
 	kubeInformers := informers.NewSharedInformerFactory(o.KubeClient, 0)
@@ -57,10 +65,16 @@ func (o *PollServiceOptions) Run(ctx context.Context) error {
 		recorder,
 		o.OriginalOutFile,
 		o.StopConfigMapName,
+		o.SamplerMetrics.SampleObserver(),
 		namespacedScopedCoreInformers.ConfigMaps(),
 	)
 
 	go podToServiceChecker.Run(ctx, cleanupFinished)
+	go func() {
+		if err := o.SamplerMetrics.Run(ctx, o.OriginalOutFile); err != nil {
+			fmt.Fprintf(o.OriginalOutFile, "Metrics server failed: %v\n", err)
+		}
+	}()
 	go kubeInformers.Start(ctx.Done())
 
 	fmt.Fprintf(o.OriginalOutFile, "Watching configmaps...\n")
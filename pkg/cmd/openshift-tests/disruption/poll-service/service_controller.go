@@ -30,6 +30,7 @@ type PollServiceController struct {
 	stopConfigMapName string
 	recorder          monitorapi.RecorderWriter
 	outFile           io.Writer
+	sampleObserver    backenddisruption.SampleObserver
 
 	configmapLister corelisters.ConfigMapLister
 
@@ -58,6 +59,7 @@ func NewPollServiceWatcher(
 	recorder monitorapi.RecorderWriter,
 	outFile io.Writer,
 	stopConfigMapName string,
+	sampleObserver backenddisruption.SampleObserver,
 	configmapInformer coreinformers.ConfigMapInformer,
 ) *PollServiceController {
 
@@ -70,6 +72,7 @@ func NewPollServiceWatcher(
 		recorder:          recorder,
 		stopConfigMapName: stopConfigMapName,
 		outFile:           outFile,
+		sampleObserver:    sampleObserver,
 
 		configmapLister: configmapInformer.Lister(),
 		informersToSync: []cache.InformerSynced{
@@ -135,6 +138,10 @@ func (c *PollServiceController) syncServicePoller(ctx context.Context, key strin
 				monitorapi.ReusedConnectionType,
 			),
 		}
+		if c.sampleObserver != nil {
+			c.watcher.newConnectionSampler = c.watcher.newConnectionSampler.WithSampleObserver(c.sampleObserver)
+			c.watcher.reusedConnectionSampler = c.watcher.reusedConnectionSampler.WithSampleObserver(c.sampleObserver)
+		}
 		c.watcher.newConnectionSampler.StartEndpointMonitoring(ctx, c.recorder, nil)
 		c.watcher.reusedConnectionSampler.StartEndpointMonitoring(ctx, c.recorder, nil)
 
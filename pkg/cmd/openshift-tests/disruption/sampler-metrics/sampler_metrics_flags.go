@@ -0,0 +1,127 @@
+package sampler_metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/pflag"
+)
+
+// SamplerMetricsFlags configures the optional /metrics endpoint exposed by the in-cluster disruption pollers.
+type SamplerMetricsFlags struct {
+	ListenAddress string
+}
+
+func NewSamplerMetricsFlags() *SamplerMetricsFlags {
+	return &SamplerMetricsFlags{}
+}
+
+func (f *SamplerMetricsFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.ListenAddress, "metrics-listen-address", f.ListenAddress, "address, like :9090, to serve prometheus metrics about the samples on.  Metrics are disabled if empty.")
+}
+
+func (f *SamplerMetricsFlags) Validate() error {
+	if len(f.ListenAddress) == 0 {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(f.ListenAddress); err != nil {
+		return fmt.Errorf("metrics-listen-address must be a host:port: %w", err)
+	}
+	return nil
+}
+
+// ToOptions returns nil if metrics are disabled.
+func (f *SamplerMetricsFlags) ToOptions() (*SamplerMetricsOptions, error) {
+	if len(f.ListenAddress) == 0 {
+		return nil, nil
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	samplerMetrics, err := backenddisruption.NewSamplerMetrics(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SamplerMetricsOptions{
+		ListenAddress:  f.ListenAddress,
+		Registry:       registry,
+		SamplerMetrics: samplerMetrics,
+	}, nil
+}
+
+type SamplerMetricsOptions struct {
+	ListenAddress  string
+	Registry       *prometheus.Registry
+	SamplerMetrics *backenddisruption.SamplerMetrics
+
+	// listener is bound by Listen.
+	listener net.Listener
+}
+
+// SampleObserver returns the observer to wire into the samplers, or nil if metrics are disabled.
+func (o *SamplerMetricsOptions) SampleObserver() backenddisruption.SampleObserver {
+	if o == nil {
+		return nil
+	}
+	return o.SamplerMetrics
+}
+
+// Listen binds the listen address before the pollers start, so that an address that is taken stops the poller instead
+// of leaving it running without metrics.  It does nothing if metrics are disabled.
+func (o *SamplerMetricsOptions) Listen() error {
+	if o == nil || o.listener != nil {
+		return nil
+	}
+	listener, err := net.Listen("tcp", o.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("unable to serve metrics on %v: %w", o.ListenAddress, err)
+	}
+	o.listener = listener
+	return nil
+}
+
+// Run serves /metrics until the context is closed.  It does nothing if metrics are disabled.
+func (o *SamplerMetricsOptions) Run(ctx context.Context, out io.Writer) error {
+	if o == nil {
+		return nil
+	}
+	if err := o.Listen(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(o.Registry, promhttp.HandlerOpts{}))
+	server := &http.Server{
+		Addr:              o.ListenAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			fmt.Fprintf(out, "Error shutting down metrics server: %v\n", err)
+		}
+	}()
+
+	fmt.Fprintf(out, "Serving metrics on %v\n", o.ListenAddress)
+	if err := server.Serve(o.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package sampler_metrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestListenFailsOnATakenAddress(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	options, err := (&SamplerMetricsFlags{ListenAddress: taken.Addr().String()}).ToOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := options.Listen(); err == nil || !strings.Contains(err.Error(), "unable to serve metrics on "+taken.Addr().String()) {
+		t.Errorf("expected the taken address to fail, got %v", err)
+	}
+
+	var disabled *SamplerMetricsOptions
+	if err := disabled.Listen(); err != nil {
+		t.Errorf("expected disabled metrics not to listen, got %v", err)
+	}
+}
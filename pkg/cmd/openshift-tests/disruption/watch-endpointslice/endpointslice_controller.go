@@ -38,6 +38,7 @@ type EndpointSliceController struct {
 	expectedStatusCode int
 	recorder           monitorapi.RecorderWriter
 	outFile            io.Writer
+	sampleObserver     backenddisruption.SampleObserver
 
 	endpointSliceLister discoverylisters.EndpointSliceLister
 	configmapLister     corelisters.ConfigMapLister
@@ -69,6 +70,7 @@ func NewEndpointWatcher(
 	expectedStatusCode int,
 	recorder monitorapi.RecorderWriter,
 	outFile io.Writer,
+	sampleObserver backenddisruption.SampleObserver,
 
 	endpointSliceInformer discoveryinformers.EndpointSliceInformer,
 	configmapInformer coreinformers.ConfigMapInformer,
@@ -84,6 +86,7 @@ func NewEndpointWatcher(
 		expectedStatusCode: expectedStatusCode,
 		recorder:           recorder,
 		outFile:            outFile,
+		sampleObserver:     sampleObserver,
 
 		endpointSliceLister: endpointSliceInformer.Lister(),
 		configmapLister:     configmapInformer.Lister(),
@@ -215,6 +218,9 @@ func (c *EndpointSliceController) syncEndpointSlice(ctx context.Context, key str
 		if c.expectedStatusCode > 0 {
 			newWatcher.newConnectionSampler = newWatcher.newConnectionSampler.WithExpectedStatusCode(c.expectedStatusCode)
 		}
+		if c.sampleObserver != nil {
+			newWatcher.newConnectionSampler = newWatcher.newConnectionSampler.WithSampleObserver(c.sampleObserver)
+		}
 		newWatcher.newConnectionSampler.StartEndpointMonitoring(ctx, c.recorder, nil)
 
 		newWatcher.reusedConnectionSampler = backenddisruption.NewSimpleBackendWithLocator(
@@ -226,6 +232,9 @@ func (c *EndpointSliceController) syncEndpointSlice(ctx context.Context, key str
 		if c.expectedStatusCode > 0 {
 			newWatcher.reusedConnectionSampler = newWatcher.reusedConnectionSampler.WithExpectedStatusCode(c.expectedStatusCode)
 		}
+		if c.sampleObserver != nil {
+			newWatcher.reusedConnectionSampler = newWatcher.reusedConnectionSampler.WithSampleObserver(c.sampleObserver)
+		}
 		newWatcher.reusedConnectionSampler.StartEndpointMonitoring(ctx, c.recorder, nil)
 
 		c.watchers[watcherKey] = newWatcher
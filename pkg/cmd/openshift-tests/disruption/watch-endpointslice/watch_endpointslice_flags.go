@@ -10,6 +10,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
+	sampler_metrics "github.com/openshift/origin/pkg/cmd/openshift-tests/disruption/sampler-metrics"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -20,6 +21,7 @@ import (
 type WatchEndpointSliceFlags struct {
 	ConfigFlags        *genericclioptions.ConfigFlags
 	OutputFlags        *iooptions.OutputFlags
+	MetricsFlags       *sampler_metrics.SamplerMetricsFlags
 	ServiceName        string
 	BackendPrefix      string
 	Scheme             string
@@ -33,10 +35,11 @@ type WatchEndpointSliceFlags struct {
 
 func NewWatchEndpointSliceFlags(streams genericclioptions.IOStreams) *WatchEndpointSliceFlags {
 	return &WatchEndpointSliceFlags{
-		ConfigFlags: genericclioptions.NewConfigFlags(false),
-		OutputFlags: iooptions.NewOutputOptions(),
-		Scheme:      "https",
-		IOStreams:   streams,
+		ConfigFlags:  genericclioptions.NewConfigFlags(false),
+		OutputFlags:  iooptions.NewOutputOptions(),
+		MetricsFlags: sampler_metrics.NewSamplerMetricsFlags(),
+		Scheme:       "https",
+		IOStreams:    streams,
 	}
 }
 
@@ -94,6 +97,7 @@ func (f *WatchEndpointSliceFlags) BindOptions(flags *pflag.FlagSet) {
 	flags.IntVar(&f.ExpectedStatusCode, "expected-status-code", f.ExpectedStatusCode, "status code to expect from the sampler")
 	f.ConfigFlags.AddFlags(flags)
 	f.OutputFlags.BindFlags(flags)
+	f.MetricsFlags.BindFlags(flags)
 }
 
 func (f *WatchEndpointSliceFlags) SetIOStreams(streams genericclioptions.IOStreams) {
@@ -110,6 +114,9 @@ func (f *WatchEndpointSliceFlags) Validate() error {
 	if len(f.BackendPrefix) == 0 {
 		return fmt.Errorf("disruption-backend-prefix must be specified")
 	}
+	if err := f.MetricsFlags.Validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -137,6 +144,10 @@ func (f *WatchEndpointSliceFlags) ToOptions() (*WatchEndpointSliceOptions, error
 	if err != nil {
 		return nil, err
 	}
+	samplerMetrics, err := f.MetricsFlags.ToOptions()
+	if err != nil {
+		return nil, err
+	}
 
 	return &WatchEndpointSliceOptions{
 		KubeClient:         kubeClient,
@@ -149,6 +160,7 @@ func (f *WatchEndpointSliceFlags) ToOptions() (*WatchEndpointSliceOptions, error
 		MyNodeName:         f.MyNodeName,
 		BackendPrefix:      f.BackendPrefix,
 		ExpectedStatusCode: f.ExpectedStatusCode,
+		SamplerMetrics:     samplerMetrics,
 		CloseFn:            closeFn,
 		OriginalOutFile:    originalOutStream,
 		IOStreams:          f.IOStreams,
@@ -9,6 +9,7 @@ import (
 	coreinformers "k8s.io/client-go/informers/core/v1"
 
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
+	sampler_metrics "github.com/openshift/origin/pkg/cmd/openshift-tests/disruption/sampler-metrics"
 	"github.com/openshift/origin/pkg/monitor"
 	"k8s.io/client-go/informers"
 
@@ -32,6 +33,9 @@ type WatchEndpointSliceOptions struct {
 	ExpectedStatusCode int
 	StopConfigMapName  string
 
+	// SamplerMetrics is nil when metrics are disabled.
+	SamplerMetrics *sampler_metrics.SamplerMetricsOptions
+
 	OriginalOutFile io.Writer
 	CloseFn         iooptions.CloseFunc
 	genericclioptions.IOStreams
@@ -49,6 +53,10 @@ func (o *WatchEndpointSliceOptions) Run(ctx context.Context) error {
 		o.OriginalOutFile.Write(startingContent)
 	}
 
+	if err := o.SamplerMetrics.Listen(); err != nil {
+		return err
+	}
+
 	recorder := monitor.WrapWithJSONLRecorder(monitor.NewRecorder(), o.IOStreams.Out, nil)
 
 	kubeInformers := informers.NewSharedInformerFactory(o.KubeClient, 0)
@@ -67,10 +75,16 @@ func (o *WatchEndpointSliceOptions) Run(ctx context.Context) error {
 		o.ExpectedStatusCode,
 		recorder,
 		o.OriginalOutFile,
+		o.SamplerMetrics.SampleObserver(),
 		namespaceScopedEndpointSliceInformers.EndpointSlices(),
 		namespaceScopedCoreInformers.ConfigMaps(),
 	)
 	go podToPodChecker.Run(ctx, cleanupFinished)
+	go func() {
+		if err := o.SamplerMetrics.Run(ctx, o.OriginalOutFile); err != nil {
+			fmt.Fprintf(o.OriginalOutFile, "Metrics server failed: %v\n", err)
+		}
+	}()
 
 	go kubeInformers.Start(ctx.Done())
 
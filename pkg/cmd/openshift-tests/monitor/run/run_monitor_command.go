@@ -32,6 +32,7 @@ type RunMonitorFlags struct {
 	DisplayFromNow      bool
 	ExactMonitorTests   []string
 	DisableMonitorTests []string
+	EnableMonitorTests  []string
 	FromRepository      string
 
 	genericclioptions.IOStreams
@@ -89,6 +90,8 @@ func (f *RunMonitorFlags) BindFlags(flags *pflag.FlagSet) {
 	flags.StringSliceVar(&f.ExactMonitorTests, "monitor", f.ExactMonitorTests,
 		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
 	flags.StringSliceVar(&f.DisableMonitorTests, "disable-monitor", f.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
+	flags.StringSliceVar(&f.EnableMonitorTests, "enable-monitor", f.EnableMonitorTests,
+		fmt.Sprintf("list of opt-in monitors to enable in addition to the defaults.  Opt-in monitors are: [%s]", strings.Join(defaultmonitortests.ListOptInMonitorTests(), ", ")))
 	flags.StringVar(&f.FromRepository, "from-repository", f.FromRepository, "A container image repository to retrieve test images from.")
 }
 
@@ -123,6 +126,7 @@ func (f *RunMonitorFlags) getMonitorTestRegistry() (monitortestframework.Monitor
 		ClusterStabilityDuringTest: monitortestframework.Stable,
 		ExactMonitorTests:          f.ExactMonitorTests,
 		DisableMonitorTests:        f.DisableMonitorTests,
+		EnableMonitorTests:         f.EnableMonitorTests,
 	}
 	return defaultmonitortests.NewMonitorTestsFor(monitorTestInfo)
 }
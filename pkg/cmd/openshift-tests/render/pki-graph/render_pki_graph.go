@@ -0,0 +1,137 @@
+package pki_graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/certs/pkigraph"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type RenderPKIGraphFlags struct {
+	RawDataFiles     []string
+	BaseRawDataFiles []string
+	OutputDir        string
+
+	genericclioptions.IOStreams
+}
+
+func NewRenderPKIGraphFlags(streams genericclioptions.IOStreams) *RenderPKIGraphFlags {
+	return &RenderPKIGraphFlags{
+		OutputDir: ".",
+		IOStreams: streams,
+	}
+}
+
+func NewRenderPKIGraphCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewRenderPKIGraphFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "pki-graph",
+		Short: "Write the signer, cert/key pair and CA bundle graph of raw TLS data as DOT and HTML.",
+		Long: `Write the signer, cert/key pair and CA bundle graph of raw TLS data as DOT and HTML.
+
+The raw data files are PKILists, like those in tls/raw-data, and the graphs of several files are merged.  When
+--base-raw-data is set, the graph highlights the TLS artifacts added, removed and re-parented since the base and the
+changes are listed on stdout.`,
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (f *RenderPKIGraphFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&f.RawDataFiles, "raw-data", f.RawDataFiles, "The PKIList files to graph.")
+	flags.StringSliceVar(&f.BaseRawDataFiles, "base-raw-data", f.BaseRawDataFiles, "The PKIList files to compare the raw data to.")
+	flags.StringVar(&f.OutputDir, "output-dir", f.OutputDir, "The directory where pki-graph.dot and pki-graph.html are written.")
+}
+
+func (f *RenderPKIGraphFlags) ToOptions() (*RenderPKIGraphOptions, error) {
+	if len(f.RawDataFiles) == 0 {
+		return nil, fmt.Errorf("--raw-data is required")
+	}
+	rawData, err := readRawData(f.RawDataFiles)
+	if err != nil {
+		return nil, err
+	}
+	baseRawData, err := readRawData(f.BaseRawDataFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RenderPKIGraphOptions{
+		RawData:     rawData,
+		BaseRawData: baseRawData,
+		OutputDir:   f.OutputDir,
+		IOStreams:   f.IOStreams,
+	}, nil
+}
+
+func readRawData(filenames []string) ([]*certgraphapi.PKIList, error) {
+	ret := []*certgraphapi.PKIList{}
+	for _, filename := range filenames {
+		currBytes, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		currPKI := &certgraphapi.PKIList{}
+		if err := json.Unmarshal(currBytes, currPKI); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", filename, err)
+		}
+		ret = append(ret, currPKI)
+	}
+	return ret, nil
+}
+
+type RenderPKIGraphOptions struct {
+	RawData []*certgraphapi.PKIList
+	// BaseRawData is compared to RawData when set.
+	BaseRawData []*certgraphapi.PKIList
+	OutputDir   string
+
+	genericclioptions.IOStreams
+}
+
+func (o *RenderPKIGraphOptions) Run() error {
+	graph := pkigraph.NewGraph(o.RawData)
+	if len(o.BaseRawData) > 0 {
+		graph = pkigraph.Diff(pkigraph.NewGraph(o.BaseRawData), graph)
+		changes := graph.DiffSummary()
+		for _, change := range changes {
+			fmt.Fprintln(o.Out, change)
+		}
+		fmt.Fprintf(o.Out, "%d TLS artifacts changed\n", len(changes))
+	}
+
+	if err := os.MkdirAll(o.OutputDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(o.OutputDir, "pki-graph.dot"), graph.DOT(), 0644); err != nil {
+		return err
+	}
+	htmlBytes, err := graph.HTML()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(o.OutputDir, "pki-graph.html"), htmlBytes, 0644); err != nil {
+		return err
+	}
+
+	return nil
+}
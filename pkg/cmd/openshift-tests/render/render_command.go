@@ -1,6 +1,7 @@
 package render
 
 import (
+	pki_graph "github.com/openshift/origin/pkg/cmd/openshift-tests/render/pki-graph"
 	test_report "github.com/openshift/origin/pkg/cmd/openshift-tests/render/test-report"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -13,6 +14,7 @@ func NewRenderCommand(streams genericclioptions.IOStreams) *cobra.Command {
 	}
 	cmd.AddCommand(
 		test_report.NewRenderTestReportCommand(streams),
+		pki_graph.NewRenderPKIGraphCommand(streams),
 	)
 	return cmd
 }
@@ -1,7 +1,10 @@
 package risk_analysis
 
 import (
+	"net/http"
+
 	"github.com/openshift/origin/pkg/riskanalysis"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"k8s.io/kubectl/pkg/util/templates"
 )
@@ -21,6 +24,10 @@ files after an invocation of openshift-tests. If multiple files are present
 Results are then submitted to sippy which will return an analysis of per-test
 and overall risk level given historical pass rates on the failed tests.
 The resulting analysis is then also written to the junit artifacts directory.
+
+In environments without access to sippy, --pass-rate-history analyzes the
+failures locally against a json or csv file of test pass rates, or --sippy-url
+can point at "risk-analysis serve" with that file.
 `),
 
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,5 +41,40 @@ The resulting analysis is then also written to the junit artifacts directory.
 	cmd.Flags().StringVar(&riskAnalysisOpts.SippyURL,
 		"sippy-url", sippyDefaultURL,
 		"Sippy URL API endpoint")
+	cmd.Flags().StringVar(&riskAnalysisOpts.PassRateHistory,
+		"pass-rate-history", riskAnalysisOpts.PassRateHistory,
+		"A json or csv file of test pass rates to analyze failures against locally instead of submitting them to sippy.")
+
+	cmd.AddCommand(newServeCommand())
+	return cmd
+}
+
+func newServeCommand() *cobra.Command {
+	passRateHistory := ""
+	listen := ":8080"
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serves the sippy risk analysis API from a local pass rate history",
+		Long: templates.LongDesc(`
+Serves the sippy risk analysis API, scoring the failures it is sent against a
+json or csv file of test pass rates, for environments without access to sippy.
+Point risk-analysis --sippy-url at it.
+`),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passRates, err := riskanalysis.ReadTestPassRates(passRateHistory)
+			if err != nil {
+				return err
+			}
+			logrus.Infof("Serving risk analysis against %d pass rates on %s", len(passRates), listen)
+			return http.ListenAndServe(listen, riskanalysis.NewLocalRiskAnalyzer(passRates))
+		},
+	}
+	cmd.Flags().StringVar(&passRateHistory,
+		"pass-rate-history", passRateHistory,
+		"A json or csv file of test pass rates to analyze failures against.")
+	cmd.MarkFlagRequired("pass-rate-history")
+	cmd.Flags().StringVar(&listen, "listen", listen, "The address to serve on.")
 	return cmd
 }
@@ -78,5 +78,7 @@ func NewRunTestCommand(streams genericclioptions.IOStreams) *cobra.Command {
 	cmd.Flags().StringSliceVar(&testOpt.ExactMonitorTests, "monitor", testOpt.ExactMonitorTests,
 		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
 	cmd.Flags().StringSliceVar(&testOpt.DisableMonitorTests, "disable-monitor", testOpt.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
+	cmd.Flags().StringSliceVar(&testOpt.EnableMonitorTests, "enable-monitor", testOpt.EnableMonitorTests,
+		fmt.Sprintf("list of opt-in monitors to enable in addition to the defaults.  Opt-in monitors are: [%s]", strings.Join(defaultmonitortests.ListOptInMonitorTests(), ", ")))
 	return cmd
 }
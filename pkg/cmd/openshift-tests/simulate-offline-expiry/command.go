@@ -0,0 +1,164 @@
+package simulate_offline_expiry
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type SimulateOfflineExpiryFlags struct {
+	RawDataFiles    []string
+	OfflineDuration string
+	ArtifactDir     string
+
+	genericclioptions.IOStreams
+}
+
+func NewSimulateOfflineExpiryFlags(streams genericclioptions.IOStreams) *SimulateOfflineExpiryFlags {
+	return &SimulateOfflineExpiryFlags{
+		OfflineDuration: "90d",
+		ArtifactDir:     ".",
+		IOStreams:       streams,
+	}
+}
+
+func NewSimulateOfflineExpiryCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewSimulateOfflineExpiryFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "simulate-offline-expiry",
+		Short: "Find the certificates that would be expired after the cluster is shut down",
+		Long: templates.LongDesc(`
+		Find the certificates and CA bundles that would be expired when a cluster is restarted after being shut down
+		for --offline-duration, using the PKI data of collect-disk-certificates and of the in-cluster secrets and
+		configmaps.
+
+		Artifacts that may be expired and are not annotated with
+		certificates.openshift.io/auto-regenerate-after-offline-expiry would leave the cluster unrecoverable.  They
+		are listed in offline-expiry.md and fail the tests of the junit written to --artifact-dir.
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (f *SimulateOfflineExpiryFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&f.RawDataFiles, "raw-data", f.RawDataFiles, "The PKIList files of the cluster and its nodes.")
+	flags.StringVar(&f.OfflineDuration, "offline-duration", f.OfflineDuration, "How long the cluster is shut down, like 90d or 1y.")
+	flags.StringVar(&f.ArtifactDir, "artifact-dir", f.ArtifactDir, "The directory where the report and junit are written.")
+}
+
+func (f *SimulateOfflineExpiryFlags) ToOptions() (*SimulateOfflineExpiryOptions, error) {
+	if len(f.RawDataFiles) == 0 {
+		return nil, fmt.Errorf("--raw-data is required")
+	}
+	rawData := []*certgraphapi.PKIList{}
+	for _, filename := range f.RawDataFiles {
+		currBytes, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		currPKI := &certgraphapi.PKIList{}
+		if err := json.Unmarshal(currBytes, currPKI); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", filename, err)
+		}
+		rawData = append(rawData, currPKI)
+	}
+
+	return &SimulateOfflineExpiryOptions{
+		RawData:         rawData,
+		OfflineDuration: f.OfflineDuration,
+		ArtifactDir:     f.ArtifactDir,
+		IOStreams:       f.IOStreams,
+	}, nil
+}
+
+type SimulateOfflineExpiryOptions struct {
+	RawData         []*certgraphapi.PKIList
+	OfflineDuration string
+	ArtifactDir     string
+
+	genericclioptions.IOStreams
+}
+
+func (o *SimulateOfflineExpiryOptions) Run() error {
+	simulation, err := Simulate(o.RawData, o.OfflineDuration)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(o.ArtifactDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(o.ArtifactDir, "offline-expiry.md"), simulation.Markdown(), 0644); err != nil {
+		return err
+	}
+	junitSuite := simulation.JUnit()
+	out, err := xml.MarshalIndent(junitSuite, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(o.ArtifactDir, "junit_offline-expiry.xml"), out, 0644); err != nil {
+		return err
+	}
+
+	certKeyPairs, caBundles := simulation.Unrecoverable()
+	fmt.Fprintf(o.Out, "After %s offline, %d certificates and %d CA bundles would be expired without being regenerated\n",
+		o.OfflineDuration, len(certKeyPairs), len(caBundles))
+	return nil
+}
+
+// JUnit has a test for the cert/key pairs and one for the CA bundles, failing with the artifacts that would leave the
+// cluster unrecoverable.
+func (s *Simulation) JUnit() *junitapi.JUnitTestSuite {
+	certKeyPairs, caBundles := s.Unrecoverable()
+	junitSuite := &junitapi.JUnitTestSuite{
+		Name: "offline-expiry",
+	}
+	for _, test := range []struct {
+		name          string
+		unrecoverable []ArtifactResult
+	}{
+		{
+			name:          fmt.Sprintf("[sig-auth][Feature:OfflineExpiry] cert/key pairs expired after %s offline must be regenerated", s.OfflineDuration),
+			unrecoverable: certKeyPairs,
+		},
+		{
+			name:          fmt.Sprintf("[sig-auth][Feature:OfflineExpiry] CA bundles expired after %s offline must be regenerated", s.OfflineDuration),
+			unrecoverable: caBundles,
+		},
+	} {
+		testCase := &junitapi.JUnitTestCase{Name: test.name}
+		junitSuite.NumTests++
+		if len(test.unrecoverable) > 0 {
+			junitSuite.NumFailed++
+			testCase.FailureOutput = &junitapi.FailureOutput{
+				Message: fmt.Sprintf("%d would be expired without being annotated with auto-regenerate-after-offline-expiry", len(test.unrecoverable)),
+				Output:  describeUnrecoverable(test.unrecoverable),
+			}
+		}
+		junitSuite.TestCases = append(junitSuite.TestCases, testCase)
+	}
+	return junitSuite
+}
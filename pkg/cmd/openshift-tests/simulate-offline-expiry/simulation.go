@@ -0,0 +1,321 @@
+package simulate_offline_expiry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/autoregenerate_after_expiry"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/validity_refresh"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadatainterfaces"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Expiry is whether a TLS artifact is expired when the cluster is restarted.  The raw data records how long
+// certificates are valid for, not when they were issued, so the simulation assumes the worst: that the cluster was
+// shut down just before the certificates were refreshed.
+type Expiry string
+
+const (
+	NotExpired Expiry = "NotExpired"
+	// MayExpire artifacts are expired if the cluster was shut down late in their refresh period.
+	MayExpire Expiry = "MayExpire"
+	// Expired artifacts are valid for less than the shutdown, so they are expired whenever the cluster was shut down.
+	Expired Expiry = "Expired"
+)
+
+var expirySeverity = map[Expiry]int{NotExpired: 0, MayExpire: 1, Expired: 2}
+
+type ArtifactResult struct {
+	// Location is secret/<namespace>/<name>, configmap/<namespace>/<name> or file/<path>.
+	Location string
+	Owner    string
+	// Validity is the validity of the certificate, or for CA bundles the longest validity of their certificates.
+	Validity string
+	Expiry   Expiry
+	// AutoRegenerates is set when the artifact is annotated as regenerated after it expires while the cluster is offline.
+	// Files on disk cannot be annotated, they regenerate when an in-cluster copy of the same certificate or CA bundle
+	// does.
+	AutoRegenerates bool
+}
+
+// Unrecoverable artifacts may be expired on restart and nothing asserts they are regenerated.
+func (r ArtifactResult) Unrecoverable() bool {
+	return r.Expiry != NotExpired && !r.AutoRegenerates
+}
+
+type Simulation struct {
+	// OfflineDuration is how long the cluster was shut down, like 90d.
+	OfflineDuration string
+	CertKeyPairs    []ArtifactResult
+	CABundles       []ArtifactResult
+}
+
+// certificateExpiry is the worst case expiry of a certificate after the cluster was offline.  A certificate is
+// refreshed at the latest after refreshPeriod, so at least validity-refreshPeriod is left when the cluster is shut down.
+func certificateExpiry(validity, refreshPeriod, offline time.Duration) Expiry {
+	switch {
+	case validity <= offline:
+		return Expired
+	case validity-refreshPeriod <= offline:
+		return MayExpire
+	default:
+		return NotExpired
+	}
+}
+
+// Simulate finds the cert/key pairs and CA bundles of the raw data that may be expired after the cluster is offline.
+// The raw data of several clusters or nodes is merged by location, keeping the worst result.
+func Simulate(rawData []*certgraphapi.PKIList, offlineDuration string) (*Simulation, error) {
+	offline, err := tlsmetadatainterfaces.ParseDuration(offlineDuration)
+	if err != nil {
+		return nil, err
+	}
+	certKeyPairs := map[string]ArtifactResult{}
+	caBundles := map[string]ArtifactResult{}
+	merge := func(results map[string]ArtifactResult, result ArtifactResult) {
+		if existing, ok := results[result.Location]; ok && expirySeverity[existing.Expiry] >= expirySeverity[result.Expiry] {
+			return
+		}
+		results[result.Location] = result
+	}
+
+	regeneratedCertKeyPairs, regeneratedCABundles := autoRegenerated(rawData)
+	for _, pkiList := range rawData {
+		certKeyInfo := map[certgraphapi.InClusterSecretLocation]certgraphapi.PKIRegistryCertKeyPairInfo{}
+		for _, curr := range pkiList.InClusterResourceData.CertKeyPairs {
+			certKeyInfo[curr.SecretLocation] = curr.CertKeyInfo
+		}
+		caBundleInfo := map[certgraphapi.InClusterConfigMapLocation]certgraphapi.PKIRegistryCertificateAuthorityInfo{}
+		for _, curr := range pkiList.InClusterResourceData.CertificateAuthorityBundles {
+			caBundleInfo[curr.ConfigMapLocation] = curr.CABundleInfo
+		}
+
+		for _, certKeyPair := range pkiList.CertKeyPairs.Items {
+			validityDuration := certKeyPair.Spec.CertMetadata.ValidityDuration
+			if len(validityDuration) == 0 {
+				// the certificate could not be read, there is nothing to simulate.
+				continue
+			}
+			validity, err := tlsmetadatainterfaces.ParseDuration(validityDuration)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", certKeyPair.Name, err)
+			}
+
+			for _, location := range certKeyPair.Spec.SecretLocations {
+				info := certKeyInfo[location]
+				_, autoRegenerates := tlsmetadatainterfaces.AnnotationValue(info.SelectedCertMetadataAnnotations, autoregenerate_after_expiry.AnnotationName)
+				merge(certKeyPairs, ArtifactResult{
+					Location:        fmt.Sprintf("secret/%s/%s", location.Namespace, location.Name),
+					Owner:           ownerOrUnknown(info.OwningJiraComponent),
+					Validity:        validityDuration,
+					Expiry:          certificateExpiry(validity, validity_refresh.LatestRefreshPeriod(validity), offline),
+					AutoRegenerates: autoRegenerates,
+				})
+			}
+			for _, location := range certKeyPair.Spec.OnDiskLocations {
+				path := location.Cert.Path
+				if len(path) == 0 {
+					path = location.Key.Path
+				}
+				merge(certKeyPairs, ArtifactResult{
+					Location:        "file/" + path,
+					Owner:           tlsmetadatainterfaces.UnknownOwner,
+					Validity:        validityDuration,
+					Expiry:          certificateExpiry(validity, validity_refresh.LatestRefreshPeriod(validity), offline),
+					AutoRegenerates: regeneratedCertKeyPairs.Has(certKeyPair.Name),
+				})
+			}
+		}
+
+		for _, caBundle := range pkiList.CertificateAuthorityBundles.Items {
+			expiry, validityDuration, err := caBundleExpiry(caBundle, offline)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", caBundle.Name, err)
+			}
+			if len(validityDuration) == 0 {
+				continue
+			}
+			for _, location := range caBundle.Spec.ConfigMapLocations {
+				info := caBundleInfo[location]
+				_, autoRegenerates := tlsmetadatainterfaces.AnnotationValue(info.SelectedCertMetadataAnnotations, autoregenerate_after_expiry.AnnotationName)
+				merge(caBundles, ArtifactResult{
+					Location:        fmt.Sprintf("configmap/%s/%s", location.Namespace, location.Name),
+					Owner:           ownerOrUnknown(info.OwningJiraComponent),
+					Validity:        validityDuration,
+					Expiry:          expiry,
+					AutoRegenerates: autoRegenerates,
+				})
+			}
+			for _, location := range caBundle.Spec.OnDiskLocations {
+				merge(caBundles, ArtifactResult{
+					Location:        "file/" + location.Path,
+					Owner:           tlsmetadatainterfaces.UnknownOwner,
+					Validity:        validityDuration,
+					Expiry:          expiry,
+					AutoRegenerates: regeneratedCABundles.Has(caBundle.Name),
+				})
+			}
+		}
+	}
+
+	return &Simulation{
+		OfflineDuration: offlineDuration,
+		CertKeyPairs:    sortedResults(certKeyPairs),
+		CABundles:       sortedResults(caBundles),
+	}, nil
+}
+
+// autoRegenerated returns the names of the cert/key pairs and CA bundles with an in-cluster location annotated as
+// regenerated after offline expiry.  The raw data of a node may only hold the on-disk copies, so all of it is searched.
+func autoRegenerated(rawData []*certgraphapi.PKIList) (certKeyPairs, caBundles sets.String) {
+	certKeyPairs, caBundles = sets.NewString(), sets.NewString()
+	for _, pkiList := range rawData {
+		regeneratedSecrets := map[certgraphapi.InClusterSecretLocation]bool{}
+		for _, curr := range pkiList.InClusterResourceData.CertKeyPairs {
+			_, regeneratedSecrets[curr.SecretLocation] = tlsmetadatainterfaces.AnnotationValue(curr.CertKeyInfo.SelectedCertMetadataAnnotations, autoregenerate_after_expiry.AnnotationName)
+		}
+		regeneratedConfigMaps := map[certgraphapi.InClusterConfigMapLocation]bool{}
+		for _, curr := range pkiList.InClusterResourceData.CertificateAuthorityBundles {
+			_, regeneratedConfigMaps[curr.ConfigMapLocation] = tlsmetadatainterfaces.AnnotationValue(curr.CABundleInfo.SelectedCertMetadataAnnotations, autoregenerate_after_expiry.AnnotationName)
+		}
+
+		for _, certKeyPair := range pkiList.CertKeyPairs.Items {
+			for _, location := range certKeyPair.Spec.SecretLocations {
+				if regeneratedSecrets[location] {
+					certKeyPairs.Insert(certKeyPair.Name)
+				}
+			}
+		}
+		for _, caBundle := range pkiList.CertificateAuthorityBundles.Items {
+			for _, location := range caBundle.Spec.ConfigMapLocations {
+				if regeneratedConfigMaps[location] {
+					caBundles.Insert(caBundle.Name)
+				}
+			}
+		}
+	}
+	return certKeyPairs, caBundles
+}
+
+// caBundleExpiry is the expiry of the longest valid certificate in the bundle, a bundle trusts something as long as
+// one of its certificates is valid.
+func caBundleExpiry(caBundle certgraphapi.CertificateAuthorityBundle, offline time.Duration) (Expiry, string, error) {
+	expiry := Expired
+	longestValidity := ""
+	var longest time.Duration
+	for _, certificate := range caBundle.Spec.CertificateMetadata {
+		if len(certificate.ValidityDuration) == 0 {
+			continue
+		}
+		validity, err := tlsmetadatainterfaces.ParseDuration(certificate.ValidityDuration)
+		if err != nil {
+			return "", "", err
+		}
+		if validity > longest {
+			longest = validity
+			longestValidity = certificate.ValidityDuration
+		}
+		if certificateExpiry := certificateExpiry(validity, validity_refresh.LatestRefreshPeriod(validity), offline); expirySeverity[certificateExpiry] < expirySeverity[expiry] {
+			expiry = certificateExpiry
+		}
+	}
+	return expiry, longestValidity, nil
+}
+
+func ownerOrUnknown(owner string) string {
+	if len(owner) == 0 {
+		return tlsmetadatainterfaces.UnknownOwner
+	}
+	return owner
+}
+
+func sortedResults(results map[string]ArtifactResult) []ArtifactResult {
+	ret := []ArtifactResult{}
+	for _, result := range results {
+		ret = append(ret, result)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Location < ret[j].Location })
+	return ret
+}
+
+func (s *Simulation) Unrecoverable() (certKeyPairs, caBundles []ArtifactResult) {
+	for _, result := range s.CertKeyPairs {
+		if result.Unrecoverable() {
+			certKeyPairs = append(certKeyPairs, result)
+		}
+	}
+	for _, result := range s.CABundles {
+		if result.Unrecoverable() {
+			caBundles = append(caBundles, result)
+		}
+	}
+	return certKeyPairs, caBundles
+}
+
+// Markdown lists the artifacts that would leave the cluster unrecoverable, those that regenerate and how many stay
+// valid.
+func (s *Simulation) Markdown() []byte {
+	md := tlsmetadatainterfaces.NewMarkdown(fmt.Sprintf("Certificate Expiry After %s Offline", s.OfflineDuration))
+	md.Text("Certificates are assumed to have been shut down just before they were refreshed, after")
+	md.Textf("%d%% of their validity like library-go certrotation does at the latest.", int(validity_refresh.LatestRefreshFraction*100))
+	md.Text("Expired artifacts are valid for less than the shutdown, those that may expire are expired if the")
+	md.Text("cluster was shut down late in their refresh period.")
+	md.Text("")
+
+	sections := []struct {
+		title   string
+		include func(ArtifactResult) bool
+	}{
+		{title: "Unrecoverable", include: ArtifactResult.Unrecoverable},
+		{title: "Regenerated", include: func(r ArtifactResult) bool { return r.Expiry != NotExpired && r.AutoRegenerates }},
+	}
+	for _, section := range sections {
+		for _, kind := range []struct {
+			name    string
+			results []ArtifactResult
+		}{
+			{name: "Certificates", results: s.CertKeyPairs},
+			{name: "Certificate Authority Bundles", results: s.CABundles},
+		} {
+			included := []ArtifactResult{}
+			for _, result := range kind.results {
+				if section.include(result) {
+					included = append(included, result)
+				}
+			}
+			md.Title(2, fmt.Sprintf("%s %s (%d)", section.title, kind.name, len(included)))
+			if len(included) == 0 {
+				continue
+			}
+			md.OrderedListStart()
+			for _, result := range included {
+				md.NewOrderedListItem()
+				md.Textf("%s\n", result.Location)
+				md.Textf("**Owner:** %s, **Validity:** %s, **Expiry:** %s", result.Owner, result.Validity, result.Expiry)
+				md.Text("\n")
+			}
+			md.OrderedListEnd()
+			md.Text("\n")
+		}
+	}
+
+	notExpired := 0
+	for _, result := range append(append([]ArtifactResult{}, s.CertKeyPairs...), s.CABundles...) {
+		if result.Expiry == NotExpired {
+			notExpired++
+		}
+	}
+	md.Title(2, fmt.Sprintf("Valid After Restart (%d)", notExpired))
+	return md.Bytes()
+}
+
+func describeUnrecoverable(results []ArtifactResult) string {
+	lines := []string{}
+	for _, result := range results {
+		lines = append(lines, fmt.Sprintf("%s (owner %s, valid for %s): %s", result.Location, result.Owner, result.Validity, result.Expiry))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,141 @@
+package simulate_offline_expiry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/autoregenerate_after_expiry"
+)
+
+func TestSimulate(t *testing.T) {
+	pkiList := &certgraphapi.PKIList{}
+	addCertKeyPair := func(name, validity string, annotations ...certgraphapi.AnnotationValue) {
+		certKeyPair := certgraphapi.CertKeyPair{Name: name}
+		certKeyPair.Spec.CertMetadata.ValidityDuration = validity
+		location := certgraphapi.InClusterSecretLocation{Namespace: "ns", Name: name}
+		certKeyPair.Spec.SecretLocations = []certgraphapi.InClusterSecretLocation{location}
+		pkiList.CertKeyPairs.Items = append(pkiList.CertKeyPairs.Items, certKeyPair)
+		pkiList.InClusterResourceData.CertKeyPairs = append(pkiList.InClusterResourceData.CertKeyPairs, certgraphapi.PKIRegistryInClusterCertKeyPair{
+			SecretLocation: location,
+			CertKeyInfo:    certgraphapi.PKIRegistryCertKeyPairInfo{OwningJiraComponent: "owner", SelectedCertMetadataAnnotations: annotations},
+		})
+	}
+	regenerates := certgraphapi.AnnotationValue{Key: autoregenerate_after_expiry.AnnotationName, Value: "https://github.com/link, \"test\""}
+
+	addCertKeyPair("short-lived", "30d")
+	addCertKeyPair("short-lived-regenerated", "30d", regenerates)
+	// refreshed after 80% of a year, so at most 73 days are left when the cluster is shut down.
+	addCertKeyPair("year", "1y")
+	// refreshed after 80% of two years, so at least 146 days are left.
+	addCertKeyPair("two-years", "2y")
+	addCertKeyPair("long-lived", "10y")
+
+	onDisk := certgraphapi.CertKeyPair{Name: "kubelet"}
+	onDisk.Spec.CertMetadata.ValidityDuration = "30d"
+	onDisk.Spec.OnDiskLocations = []certgraphapi.OnDiskCertKeyPairLocation{{Cert: certgraphapi.OnDiskLocation{Path: "/var/lib/kubelet/pki/kubelet-client.pem"}}}
+	pkiList.CertKeyPairs.Items = append(pkiList.CertKeyPairs.Items, onDisk)
+
+	caBundle := certgraphapi.CertificateAuthorityBundle{Name: "ca"}
+	caBundle.Spec.ConfigMapLocations = []certgraphapi.InClusterConfigMapLocation{{Namespace: "ns", Name: "ca"}}
+	caBundle.Spec.CertificateMetadata = []certgraphapi.CertKeyMetadata{{ValidityDuration: "30d"}, {ValidityDuration: "60d"}}
+	pkiList.CertificateAuthorityBundles.Items = append(pkiList.CertificateAuthorityBundles.Items, caBundle)
+
+	simulation, err := Simulate([]*certgraphapi.PKIList{pkiList}, "90d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiries := map[string]Expiry{}
+	for _, result := range append(append([]ArtifactResult{}, simulation.CertKeyPairs...), simulation.CABundles...) {
+		expiries[result.Location] = result.Expiry
+	}
+	expectedExpiries := map[string]Expiry{
+		"secret/ns/short-lived":                        Expired,
+		"secret/ns/short-lived-regenerated":            Expired,
+		"secret/ns/year":                               MayExpire,
+		"secret/ns/two-years":                          NotExpired,
+		"secret/ns/long-lived":                         NotExpired,
+		"file//var/lib/kubelet/pki/kubelet-client.pem": Expired,
+		"configmap/ns/ca":                              Expired,
+	}
+	if !reflect.DeepEqual(expiries, expectedExpiries) {
+		t.Errorf("expected %v, got %v", expectedExpiries, expiries)
+	}
+
+	certKeyPairs, caBundles := simulation.Unrecoverable()
+	unrecoverable := []string{}
+	for _, result := range append(certKeyPairs, caBundles...) {
+		unrecoverable = append(unrecoverable, result.Location)
+	}
+	expectedUnrecoverable := []string{"file//var/lib/kubelet/pki/kubelet-client.pem", "secret/ns/short-lived", "secret/ns/year", "configmap/ns/ca"}
+	if !reflect.DeepEqual(unrecoverable, expectedUnrecoverable) {
+		t.Errorf("expected %v to be unrecoverable, got %v", expectedUnrecoverable, unrecoverable)
+	}
+
+	junitSuite := simulation.JUnit()
+	if junitSuite.NumTests != 2 || junitSuite.NumFailed != 2 {
+		t.Errorf("expected both tests to fail, got %d of %d", junitSuite.NumFailed, junitSuite.NumTests)
+	}
+}
+
+func TestSimulateOnDiskCopies(t *testing.T) {
+	regenerates := certgraphapi.AnnotationValue{Key: autoregenerate_after_expiry.AnnotationName, Value: "https://github.com/link, \"test\""}
+
+	// the cluster holds the cert/key pair and CA bundle in annotated resources.
+	clusterPKIList := &certgraphapi.PKIList{}
+	certKeyPair := certgraphapi.CertKeyPair{Name: "kube-apiserver-localhost::1"}
+	certKeyPair.Spec.CertMetadata.ValidityDuration = "30d"
+	certKeyPair.Spec.SecretLocations = []certgraphapi.InClusterSecretLocation{{Namespace: "ns", Name: "localhost-serving"}}
+	clusterPKIList.CertKeyPairs.Items = []certgraphapi.CertKeyPair{certKeyPair}
+	clusterPKIList.InClusterResourceData.CertKeyPairs = []certgraphapi.PKIRegistryInClusterCertKeyPair{{
+		SecretLocation: certKeyPair.Spec.SecretLocations[0],
+		CertKeyInfo:    certgraphapi.PKIRegistryCertKeyPairInfo{SelectedCertMetadataAnnotations: []certgraphapi.AnnotationValue{regenerates}},
+	}}
+	caBundle := certgraphapi.CertificateAuthorityBundle{Name: "localhost-ca"}
+	caBundle.Spec.CertificateMetadata = []certgraphapi.CertKeyMetadata{{ValidityDuration: "30d"}}
+	caBundle.Spec.ConfigMapLocations = []certgraphapi.InClusterConfigMapLocation{{Namespace: "ns", Name: "localhost-ca"}}
+	clusterPKIList.CertificateAuthorityBundles.Items = []certgraphapi.CertificateAuthorityBundle{caBundle}
+	clusterPKIList.InClusterResourceData.CertificateAuthorityBundles = []certgraphapi.PKIRegistryInClusterCABundle{{
+		ConfigMapLocation: caBundle.Spec.ConfigMapLocations[0],
+		CABundleInfo:      certgraphapi.PKIRegistryCertificateAuthorityInfo{SelectedCertMetadataAnnotations: []certgraphapi.AnnotationValue{regenerates}},
+	}}
+
+	// a node only holds the files the resources are installed to, and a file that is not in the cluster.
+	nodePKIList := &certgraphapi.PKIList{}
+	onDiskCertKeyPair := certKeyPair
+	onDiskCertKeyPair.Spec.SecretLocations = nil
+	onDiskCertKeyPair.Spec.OnDiskLocations = []certgraphapi.OnDiskCertKeyPairLocation{{Cert: certgraphapi.OnDiskLocation{Path: "/etc/kubernetes/localhost-serving/tls.crt"}}}
+	kubeletCertKeyPair := certgraphapi.CertKeyPair{Name: "kubelet::2"}
+	kubeletCertKeyPair.Spec.CertMetadata.ValidityDuration = "30d"
+	kubeletCertKeyPair.Spec.OnDiskLocations = []certgraphapi.OnDiskCertKeyPairLocation{{Cert: certgraphapi.OnDiskLocation{Path: "/var/lib/kubelet/pki/kubelet-client.pem"}}}
+	nodePKIList.CertKeyPairs.Items = []certgraphapi.CertKeyPair{onDiskCertKeyPair, kubeletCertKeyPair}
+	onDiskCABundle := caBundle
+	onDiskCABundle.Spec.ConfigMapLocations = nil
+	onDiskCABundle.Spec.OnDiskLocations = []certgraphapi.OnDiskLocation{{Path: "/etc/kubernetes/localhost-ca/ca-bundle.crt"}}
+	nodePKIList.CertificateAuthorityBundles.Items = []certgraphapi.CertificateAuthorityBundle{onDiskCABundle}
+
+	simulation, err := Simulate([]*certgraphapi.PKIList{nodePKIList, clusterPKIList}, "90d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	autoRegenerates := map[string]bool{}
+	for _, result := range append(append([]ArtifactResult{}, simulation.CertKeyPairs...), simulation.CABundles...) {
+		autoRegenerates[result.Location] = result.AutoRegenerates
+	}
+	expected := map[string]bool{
+		"secret/ns/localhost-serving":                     true,
+		"file//etc/kubernetes/localhost-serving/tls.crt":  true,
+		"file//var/lib/kubelet/pki/kubelet-client.pem":    false,
+		"configmap/ns/localhost-ca":                       true,
+		"file//etc/kubernetes/localhost-ca/ca-bundle.crt": true,
+	}
+	if !reflect.DeepEqual(autoRegenerates, expected) {
+		t.Errorf("expected %v, got %v", expected, autoRegenerates)
+	}
+
+	junitSuite := simulation.JUnit()
+	if junitSuite.NumTests != 2 || junitSuite.NumFailed != 1 {
+		t.Errorf("expected only the cert/key pairs to fail, for the kubelet client certificate, got %d of %d", junitSuite.NumFailed, junitSuite.NumTests)
+	}
+}
@@ -2,7 +2,7 @@ package autoregenerate_after_expiry
 
 import "github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadatainterfaces"
 
-const annotationName string = "certificates.openshift.io/auto-regenerate-after-offline-expiry"
+const AnnotationName string = "certificates.openshift.io/auto-regenerate-after-offline-expiry"
 
 type AutoRegenerateAfterOfflineExpiryRequirement struct{}
 
@@ -17,7 +17,7 @@ func NewAutoRegenerateAfterOfflineExpiryRequirement() tlsmetadatainterfaces.Requ
 	md.Text("To assert that a particular cert/key pair or CA bundle can do this, add the annotation to the secret or configmap.")
 	md.Text("```yaml")
 	md.Text("  annotations:")
-	md.Textf("    %v: https//github.com/link/to/pr/adding/annotation, \"quote escaped formatted name of e2e test that ensures the PKI artifact functions properly\"", annotationName)
+	md.Textf("    %v: https//github.com/link/to/pr/adding/annotation, \"quote escaped formatted name of e2e test that ensures the PKI artifact functions properly\"", AnnotationName)
 	md.Text("```")
 	md.Text("")
 	md.Text("This assertion means that you have")
@@ -34,7 +34,7 @@ func NewAutoRegenerateAfterOfflineExpiryRequirement() tlsmetadatainterfaces.Requ
 		// requirement name
 		"autoregenerate-after-expiry",
 		// cert or configmap annotation
-		annotationName,
+		AnnotationName,
 		"Auto Regenerate After Offline Expiry",
 		string(md.ExactBytes()),
 	)
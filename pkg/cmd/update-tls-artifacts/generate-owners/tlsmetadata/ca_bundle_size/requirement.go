@@ -0,0 +1,44 @@
+package ca_bundle_size
+
+import (
+	"fmt"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadatainterfaces"
+)
+
+// maxCABundleCertificates is the number of certificates above which a CA bundle is trusting more than it should.
+const maxCABundleCertificates = 10
+
+type caBundleSizePolicy struct{}
+
+func NewCABundleSizeRequirement() tlsmetadatainterfaces.Requirement {
+	md := tlsmetadatainterfaces.NewMarkdown("")
+	md.Textf("CA bundles in openshift namespaces must not contain more than %d certificates.", maxCABundleCertificates)
+	md.Text("Every certificate in a CA bundle is trusted by its consumers, so bundles that keep growing usually mean")
+	md.Text("that expired or rotated signers are never pruned, or that unrelated trust has been merged together.")
+	md.Text("")
+	md.Text("To meet the requirement, prune signers from the bundle once the certificates they signed have expired,")
+	md.Text("and keep separate bundles for separate purposes.")
+
+	return tlsmetadatainterfaces.NewPolicyRequirement(
+		// requirement name
+		"ca-bundle-size",
+		"CA Bundle Size",
+		string(md.ExactBytes()),
+		func([]*certgraphapi.PKIList) tlsmetadatainterfaces.Policy {
+			return caBundleSizePolicy{}
+		},
+	)
+}
+
+func (caBundleSizePolicy) CertKeyPairViolation(certgraphapi.CertKeyPair, certgraphapi.PKIRegistryCertKeyPairInfo) string {
+	return ""
+}
+
+func (caBundleSizePolicy) CABundleViolation(caBundle certgraphapi.CertificateAuthorityBundle, _ certgraphapi.PKIRegistryCertificateAuthorityInfo) string {
+	if numCerts := len(caBundle.Spec.CertificateMetadata); numCerts > maxCABundleCertificates {
+		return fmt.Sprintf("contains %d certificates, at most %d are allowed", numCerts, maxCABundleCertificates)
+	}
+	return ""
+}
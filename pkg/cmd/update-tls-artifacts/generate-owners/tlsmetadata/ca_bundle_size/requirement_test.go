@@ -0,0 +1,28 @@
+package ca_bundle_size
+
+import (
+	"testing"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+)
+
+func TestCABundleSizePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		numCerts int
+		expected string
+	}{
+		{name: "empty", numCerts: 0, expected: ""},
+		{name: "one signer", numCerts: 1, expected: ""},
+		{name: "at the limit", numCerts: 10, expected: ""},
+		{name: "over the limit", numCerts: 11, expected: "contains 11 certificates, at most 10 are allowed"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			caBundle := certgraphapi.CertificateAuthorityBundle{}
+			caBundle.Spec.CertificateMetadata = make([]certgraphapi.CertKeyMetadata, tc.numCerts)
+			if actual := (caBundleSizePolicy{}).CABundleViolation(caBundle, certgraphapi.PKIRegistryCertificateAuthorityInfo{}); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
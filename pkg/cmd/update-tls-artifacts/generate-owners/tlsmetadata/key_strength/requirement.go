@@ -0,0 +1,73 @@
+package key_strength
+
+import (
+	"fmt"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadatainterfaces"
+)
+
+// minimumKeyBits is the smallest key size accepted for each public key algorithm.
+var minimumKeyBits = map[string]int{
+	"RSA":     2048,
+	"ECDSA":   256,
+	"Ed25519": 256,
+}
+
+// fixedKeyBits are the sizes of the algorithms whose keys always have the same size.  The raw data only records the size
+// of RSA and ECDSA keys.
+var fixedKeyBits = map[string]int{
+	"Ed25519": 256,
+}
+
+type keyStrengthPolicy struct{}
+
+func NewKeyStrengthRequirement() tlsmetadatainterfaces.Requirement {
+	md := tlsmetadatainterfaces.NewMarkdown("")
+	md.Text("Cert/key pairs must use a public key algorithm and size that is accepted by FIPS and current guidance:")
+	md.Text("")
+	md.Text("| Algorithm | Minimum Size |")
+	md.Text("| ----------- | ----------- |")
+	md.Textf("| RSA | %d bit |", minimumKeyBits["RSA"])
+	md.Textf("| ECDSA | %d bit |", minimumKeyBits["ECDSA"])
+	md.Textf("| Ed25519 | %d bit |", minimumKeyBits["Ed25519"])
+	md.Text("")
+	md.Text("To meet the requirement, generate the key with an accepted algorithm and size.")
+
+	return tlsmetadatainterfaces.NewPolicyRequirement(
+		// requirement name
+		"key-strength",
+		"Key Algorithm and Size",
+		string(md.ExactBytes()),
+		func([]*certgraphapi.PKIList) tlsmetadatainterfaces.Policy {
+			return keyStrengthPolicy{}
+		},
+	)
+}
+
+func (keyStrengthPolicy) CertKeyPairViolation(certKeyPair certgraphapi.CertKeyPair, _ certgraphapi.PKIRegistryCertKeyPairInfo) string {
+	metadata := certKeyPair.Spec.CertMetadata
+	if len(metadata.PublicKeyAlgorithm) == 0 {
+		// the certificate could not be read, there is nothing to check.
+		return ""
+	}
+	minimum, ok := minimumKeyBits[metadata.PublicKeyAlgorithm]
+	if !ok {
+		return fmt.Sprintf("%s keys are not accepted", metadata.PublicKeyAlgorithm)
+	}
+	bits, ok := fixedKeyBits[metadata.PublicKeyAlgorithm]
+	if !ok {
+		// sizes are recorded as "2048 bit" or "256 bit, P-256 curve".
+		if _, err := fmt.Sscanf(metadata.PublicKeyBitSize, "%d bit", &bits); err != nil {
+			return fmt.Sprintf("%s key has an unrecognized size %q", metadata.PublicKeyAlgorithm, metadata.PublicKeyBitSize)
+		}
+	}
+	if bits < minimum {
+		return fmt.Sprintf("%s key is %d bit, at least %d bit is required", metadata.PublicKeyAlgorithm, bits, minimum)
+	}
+	return ""
+}
+
+func (keyStrengthPolicy) CABundleViolation(certgraphapi.CertificateAuthorityBundle, certgraphapi.PKIRegistryCertificateAuthorityInfo) string {
+	return ""
+}
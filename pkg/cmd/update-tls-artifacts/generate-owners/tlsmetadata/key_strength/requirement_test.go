@@ -0,0 +1,35 @@
+package key_strength
+
+import (
+	"testing"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+)
+
+func TestKeyStrengthPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		algorithm string
+		bitSize   string
+		expected  string
+	}{
+		{name: "unreadable certificate", algorithm: "", bitSize: "", expected: ""},
+		{name: "RSA 2048", algorithm: "RSA", bitSize: "2048 bit", expected: ""},
+		{name: "RSA 4096", algorithm: "RSA", bitSize: "4096 bit", expected: ""},
+		{name: "RSA 1024", algorithm: "RSA", bitSize: "1024 bit", expected: "RSA key is 1024 bit, at least 2048 bit is required"},
+		{name: "ECDSA P-256", algorithm: "ECDSA", bitSize: "256 bit, P-256 curve", expected: ""},
+		{name: "ECDSA P-224", algorithm: "ECDSA", bitSize: "224 bit, P-224 curve", expected: "ECDSA key is 224 bit, at least 256 bit is required"},
+		{name: "Ed25519 without a size", algorithm: "Ed25519", bitSize: "", expected: ""},
+		{name: "DSA", algorithm: "DSA", bitSize: "2048 bit", expected: "DSA keys are not accepted"},
+		{name: "unrecognized size", algorithm: "RSA", bitSize: "large", expected: `RSA key has an unrecognized size "large"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			certKeyPair := certgraphapi.CertKeyPair{}
+			certKeyPair.Spec.CertMetadata.PublicKeyAlgorithm = tc.algorithm
+			certKeyPair.Spec.CertMetadata.PublicKeyBitSize = tc.bitSize
+			if actual := (keyStrengthPolicy{}).CertKeyPairViolation(certKeyPair, certgraphapi.PKIRegistryCertKeyPairInfo{}); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
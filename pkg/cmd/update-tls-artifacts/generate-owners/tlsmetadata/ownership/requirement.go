@@ -53,20 +53,14 @@ func generateViolationJSON(pkiInfo *certs.PKIRegistryInfo) *certs.PKIRegistryInf
 
 	for i := range pkiInfo.CertKeyPairs {
 		curr := pkiInfo.CertKeyPairs[i]
-		if curr.InClusterLocation == nil {
-			continue
-		}
-		owner := curr.InClusterLocation.CertKeyInfo.OwningJiraComponent
+		owner := tlsmetadatainterfaces.CertKeyPairInfo(curr).OwningJiraComponent
 		if len(owner) == 0 || owner == tlsmetadatainterfaces.UnknownOwner {
 			ret.CertKeyPairs = append(ret.CertKeyPairs, curr)
 		}
 	}
 	for i := range pkiInfo.CertificateAuthorityBundles {
 		curr := pkiInfo.CertificateAuthorityBundles[i]
-		if curr.InClusterLocation == nil {
-			continue
-		}
-		owner := curr.InClusterLocation.CABundleInfo.OwningJiraComponent
+		owner := tlsmetadatainterfaces.CABundleInfo(curr).OwningJiraComponent
 		if len(owner) == 0 || owner == tlsmetadatainterfaces.UnknownOwner {
 			ret.CertificateAuthorityBundles = append(ret.CertificateAuthorityBundles, curr)
 		}
@@ -83,10 +77,7 @@ func generateOwnershipMarkdown(pkiInfo *certs.PKIRegistryInfo) ([]byte, error) {
 
 	for i := range pkiInfo.CertKeyPairs {
 		curr := pkiInfo.CertKeyPairs[i]
-		if curr.InClusterLocation == nil {
-			continue
-		}
-		owner := curr.InClusterLocation.CertKeyInfo.OwningJiraComponent
+		owner := tlsmetadatainterfaces.CertKeyPairInfo(curr).OwningJiraComponent
 		if len(owner) == 0 || owner == tlsmetadatainterfaces.UnknownOwner {
 			certsWithoutOwners = append(certsWithoutOwners, curr)
 			continue
@@ -95,10 +86,7 @@ func generateOwnershipMarkdown(pkiInfo *certs.PKIRegistryInfo) ([]byte, error) {
 	}
 	for i := range pkiInfo.CertificateAuthorityBundles {
 		curr := pkiInfo.CertificateAuthorityBundles[i]
-		if curr.InClusterLocation == nil {
-			continue
-		}
-		owner := curr.InClusterLocation.CABundleInfo.OwningJiraComponent
+		owner := tlsmetadatainterfaces.CABundleInfo(curr).OwningJiraComponent
 		if len(owner) == 0 || owner == tlsmetadatainterfaces.UnknownOwner {
 			caBundlesWithoutOwners = append(caBundlesWithoutOwners, curr)
 			continue
@@ -114,13 +102,7 @@ func generateOwnershipMarkdown(pkiInfo *certs.PKIRegistryInfo) ([]byte, error) {
 			md.Title(3, fmt.Sprintf("Certificates (%d)", len(certsWithoutOwners)))
 			md.OrderedListStart()
 			for _, curr := range certsWithoutOwners {
-				if curr.InClusterLocation == nil {
-					continue
-				}
-				md.NewOrderedListItem()
-				md.Textf("ns/%v secret/%v\n", curr.InClusterLocation.SecretLocation.Namespace, curr.InClusterLocation.SecretLocation.Name)
-				md.Textf("**Description:** %v", curr.InClusterLocation.CertKeyInfo.Description)
-				md.Text("\n")
+				tlsmetadatainterfaces.CertKeyPairListItem(md, curr)
 			}
 			md.OrderedListEnd()
 			md.Text("\n")
@@ -129,13 +111,7 @@ func generateOwnershipMarkdown(pkiInfo *certs.PKIRegistryInfo) ([]byte, error) {
 			md.Title(3, fmt.Sprintf("Certificate Authority Bundles (%d)", len(caBundlesWithoutOwners)))
 			md.OrderedListStart()
 			for _, curr := range caBundlesWithoutOwners {
-				if curr.InClusterLocation == nil {
-					continue
-				}
-				md.NewOrderedListItem()
-				md.Textf("ns/%v configmap/%v\n", curr.InClusterLocation.ConfigMapLocation.Namespace, curr.InClusterLocation.ConfigMapLocation.Name)
-				md.Textf("**Description:** %v", curr.InClusterLocation.CABundleInfo.Description)
-				md.Text("\n")
+				tlsmetadatainterfaces.CABundleListItem(md, curr)
 			}
 			md.OrderedListEnd()
 			md.Text("\n")
@@ -151,13 +127,7 @@ func generateOwnershipMarkdown(pkiInfo *certs.PKIRegistryInfo) ([]byte, error) {
 			md.Title(3, fmt.Sprintf("Certificates (%d)", len(certs)))
 			md.OrderedListStart()
 			for _, curr := range certs {
-				if curr.InClusterLocation == nil {
-					continue
-				}
-				md.NewOrderedListItem()
-				md.Textf("ns/%v secret/%v\n", curr.InClusterLocation.SecretLocation.Namespace, curr.InClusterLocation.SecretLocation.Name)
-				md.Textf("**Description:** %v", curr.InClusterLocation.CertKeyInfo.Description)
-				md.Text("\n")
+				tlsmetadatainterfaces.CertKeyPairListItem(md, curr)
 			}
 			md.OrderedListEnd()
 			md.Text("\n")
@@ -168,13 +138,7 @@ func generateOwnershipMarkdown(pkiInfo *certs.PKIRegistryInfo) ([]byte, error) {
 			md.Title(3, fmt.Sprintf("Certificate Authority Bundles (%d)", len(caBundles)))
 			md.OrderedListStart()
 			for _, curr := range caBundles {
-				if curr.InClusterLocation == nil {
-					continue
-				}
-				md.NewOrderedListItem()
-				md.Textf("ns/%v configmap/%v\n", curr.InClusterLocation.ConfigMapLocation.Namespace, curr.InClusterLocation.ConfigMapLocation.Name)
-				md.Textf("**Description:** %v", curr.InClusterLocation.CABundleInfo.Description)
-				md.Text("\n")
+				tlsmetadatainterfaces.CABundleListItem(md, curr)
 			}
 			md.OrderedListEnd()
 			md.Text("\n")
@@ -0,0 +1,82 @@
+package signer_chain_depth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadatainterfaces"
+)
+
+// maxSignerChainDepth is the number of signers allowed between a certificate and its root signer, including the root.
+const maxSignerChainDepth = 2
+
+type signerChainDepthPolicy struct {
+	// signersByCommonName are the certificates of the raw data, by their common name.
+	signersByCommonName map[string]certgraphapi.CertKeyPair
+}
+
+func NewSignerChainDepthRequirement() tlsmetadatainterfaces.Requirement {
+	md := tlsmetadatainterfaces.NewMarkdown("")
+	md.Textf("Cert/key pairs must be signed by a root signer, or by an intermediate signer of a root signer: at most %d signers", maxSignerChainDepth)
+	md.Text("in the chain.  Every additional signer is another key to protect and another certificate that must be")
+	md.Text("rotated before the certificates it signed, and deep chains make rotation failures hard to diagnose.")
+	md.Text("")
+	md.Text("To meet the requirement, sign the certificate with a signer closer to the root.")
+
+	return tlsmetadatainterfaces.NewPolicyRequirement(
+		// requirement name
+		"signer-chain-depth",
+		"Signer Chain Depth",
+		string(md.ExactBytes()),
+		newSignerChainDepthPolicy,
+	)
+}
+
+func newSignerChainDepthPolicy(rawData []*certgraphapi.PKIList) tlsmetadatainterfaces.Policy {
+	policy := signerChainDepthPolicy{signersByCommonName: map[string]certgraphapi.CertKeyPair{}}
+	for _, currPKI := range rawData {
+		for _, certKeyPair := range currPKI.CertKeyPairs.Items {
+			commonName := certKeyPair.Spec.CertMetadata.CertIdentifier.CommonName
+			if len(commonName) > 0 {
+				policy.signersByCommonName[commonName] = certKeyPair
+			}
+		}
+	}
+	return policy
+}
+
+// signerChain returns the common names of the signers of the certificate, from its issuer to the root.  Signers that
+// are not in the raw data end the chain.
+func (p signerChainDepthPolicy) signerChain(certKeyPair certgraphapi.CertKeyPair) []string {
+	chain := []string{}
+	seen := map[string]bool{certKeyPair.Spec.CertMetadata.CertIdentifier.CommonName: true}
+	for curr := certKeyPair.Spec.CertMetadata.CertIdentifier; curr.Issuer != nil; {
+		issuer := curr.Issuer.CommonName
+		if len(issuer) == 0 || seen[issuer] {
+			// self-signed, or a loop we stop following.
+			break
+		}
+		seen[issuer] = true
+		chain = append(chain, issuer)
+
+		signer, ok := p.signersByCommonName[issuer]
+		if !ok {
+			break
+		}
+		curr = signer.Spec.CertMetadata.CertIdentifier
+	}
+	return chain
+}
+
+func (p signerChainDepthPolicy) CertKeyPairViolation(certKeyPair certgraphapi.CertKeyPair, _ certgraphapi.PKIRegistryCertKeyPairInfo) string {
+	chain := p.signerChain(certKeyPair)
+	if len(chain) > maxSignerChainDepth {
+		return fmt.Sprintf("signed through %d signers: %s", len(chain), strings.Join(chain, " <- "))
+	}
+	return ""
+}
+
+func (signerChainDepthPolicy) CABundleViolation(certgraphapi.CertificateAuthorityBundle, certgraphapi.PKIRegistryCertificateAuthorityInfo) string {
+	return ""
+}
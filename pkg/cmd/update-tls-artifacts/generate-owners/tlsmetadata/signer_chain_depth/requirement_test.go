@@ -0,0 +1,54 @@
+package signer_chain_depth
+
+import (
+	"testing"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+)
+
+// certKeyPair returns a certificate with the common name, issued by issuer or self-signed if issuer is empty.
+func certKeyPair(commonName, issuer string) certgraphapi.CertKeyPair {
+	ret := certgraphapi.CertKeyPair{}
+	ret.Spec.CertMetadata.CertIdentifier.CommonName = commonName
+	if len(issuer) == 0 {
+		issuer = commonName
+	}
+	ret.Spec.CertMetadata.CertIdentifier.Issuer = &certgraphapi.CertIdentifier{CommonName: issuer}
+	return ret
+}
+
+func TestSignerChainDepthPolicy(t *testing.T) {
+	rawData := []*certgraphapi.PKIList{{
+		CertKeyPairs: certgraphapi.CertKeyPairList{Items: []certgraphapi.CertKeyPair{
+			certKeyPair("root", ""),
+			certKeyPair("intermediate", "root"),
+			certKeyPair("second-intermediate", "intermediate"),
+			certKeyPair("loop-a", "loop-b"),
+			certKeyPair("loop-b", "loop-a"),
+		}},
+	}}
+	policy := newSignerChainDepthPolicy(rawData)
+
+	for _, tc := range []struct {
+		name        string
+		certKeyPair certgraphapi.CertKeyPair
+		expected    string
+	}{
+		{name: "self-signed", certKeyPair: certKeyPair("root", ""), expected: ""},
+		{name: "signed by the root", certKeyPair: certKeyPair("leaf", "root"), expected: ""},
+		{name: "signed by an intermediate", certKeyPair: certKeyPair("leaf", "intermediate"), expected: ""},
+		{
+			name:        "signed by a second intermediate",
+			certKeyPair: certKeyPair("leaf", "second-intermediate"),
+			expected:    "signed through 3 signers: second-intermediate <- intermediate <- root",
+		},
+		{name: "signer not in the raw data", certKeyPair: certKeyPair("leaf", "external"), expected: ""},
+		{name: "signer loop", certKeyPair: certKeyPair("leaf", "loop-a"), expected: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := policy.CertKeyPairViolation(tc.certKeyPair, certgraphapi.PKIRegistryCertKeyPairInfo{}); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package validity_refresh
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadatainterfaces"
+)
+
+// LatestRefreshFraction is the part of the validity after which library-go certrotation refreshes a certificate at the
+// latest, whatever refresh period it is configured with.
+const LatestRefreshFraction = 0.8
+
+// minTimeToRecover is the time that must be left after the latest refresh to notice and fix a rotation that does not
+// happen before the certificate expires.
+const minTimeToRecover = 24 * time.Hour
+
+// LatestRefreshPeriod is how long after it is issued library-go certrotation refreshes a certificate at the latest.
+func LatestRefreshPeriod(validity time.Duration) time.Duration {
+	return time.Duration(LatestRefreshFraction * float64(validity))
+}
+
+type validityRefreshPolicy struct{}
+
+func NewValidityRefreshRequirement() tlsmetadatainterfaces.Requirement {
+	md := tlsmetadatainterfaces.NewMarkdown("")
+	md.Textf("library-go certrotation refreshes cert/key pairs after %d%% of their validity at the latest.  The rest of the", int(LatestRefreshFraction*100))
+	md.Text("validity is the time there is to notice and fix a rotation that does not happen before the certificate")
+	md.Textf("expires, so cert/key pairs must be valid long enough to leave at least %v after their latest refresh.", formatDuration(minTimeToRecover))
+	md.Text("")
+	md.Textf("To meet the requirement, issue the certificate for at least %v.", formatDuration(minimumValidity()))
+
+	return tlsmetadatainterfaces.NewPolicyRequirement(
+		// requirement name
+		"validity-and-refresh-period",
+		"Validity and Refresh Period",
+		string(md.ExactBytes()),
+		func([]*certgraphapi.PKIList) tlsmetadatainterfaces.Policy {
+			return validityRefreshPolicy{}
+		},
+	)
+}
+
+// minimumValidity is the shortest validity that leaves minTimeToRecover after the latest refresh.
+func minimumValidity() time.Duration {
+	return time.Duration(float64(minTimeToRecover) / (1 - LatestRefreshFraction)).Round(time.Hour)
+}
+
+// formatDuration formats whole days like the ValidityDuration of the raw data.
+func formatDuration(d time.Duration) string {
+	if day := 24 * time.Hour; d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.Round(time.Minute).String()
+}
+
+func (validityRefreshPolicy) CertKeyPairViolation(certKeyPair certgraphapi.CertKeyPair, _ certgraphapi.PKIRegistryCertKeyPairInfo) string {
+	validityDuration := certKeyPair.Spec.CertMetadata.ValidityDuration
+	if len(validityDuration) == 0 {
+		// the certificate could not be read, there is nothing to check.
+		return ""
+	}
+	validity, err := tlsmetadatainterfaces.ParseDuration(validityDuration)
+	if err != nil {
+		return fmt.Sprintf("unrecognized validity %q", validityDuration)
+	}
+	if timeToRecover := validity - LatestRefreshPeriod(validity); timeToRecover < minTimeToRecover {
+		return fmt.Sprintf("valid for %v and refreshed after %v at the latest, leaving %v to recover from a failed rotation, at least %v is required",
+			validityDuration, formatDuration(LatestRefreshPeriod(validity)), formatDuration(timeToRecover), formatDuration(minTimeToRecover))
+	}
+	return ""
+}
+
+func (validityRefreshPolicy) CABundleViolation(certgraphapi.CertificateAuthorityBundle, certgraphapi.PKIRegistryCertificateAuthorityInfo) string {
+	return ""
+}
@@ -0,0 +1,33 @@
+package validity_refresh
+
+import (
+	"testing"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+)
+
+func TestValidityRefreshPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		validity string
+		expected string
+	}{
+		{validity: "", expected: ""},
+		{validity: "2y", expected: ""},
+		{validity: "5d", expected: ""},
+		{validity: "24h", expected: "valid for 24h and refreshed after 19h12m0s at the latest, leaving 4h48m0s to recover from a failed rotation, at least 1d is required"},
+		{validity: "4d", expected: "valid for 4d and refreshed after 76h48m0s at the latest, leaving 19h12m0s to recover from a failed rotation, at least 1d is required"},
+		{validity: "1mo", expected: `unrecognized validity "1mo"`},
+	} {
+		certKeyPair := certgraphapi.CertKeyPair{}
+		certKeyPair.Spec.CertMetadata.ValidityDuration = tc.validity
+		if actual := (validityRefreshPolicy{}).CertKeyPairViolation(certKeyPair, certgraphapi.PKIRegistryCertKeyPairInfo{}); actual != tc.expected {
+			t.Errorf("validity %q: expected %q, got %q", tc.validity, tc.expected, actual)
+		}
+	}
+}
+
+func TestMinimumValidity(t *testing.T) {
+	if actual := formatDuration(minimumValidity()); actual != "5d" {
+		t.Errorf("expected 5d, got %v", actual)
+	}
+}
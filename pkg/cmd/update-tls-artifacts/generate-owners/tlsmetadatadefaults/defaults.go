@@ -2,8 +2,12 @@ package tlsmetadatadefaults
 
 import (
 	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/autoregenerate_after_expiry"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/ca_bundle_size"
 	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/descriptions"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/key_strength"
 	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/ownership"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/signer_chain_depth"
+	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadata/validity_refresh"
 	"github.com/openshift/origin/pkg/cmd/update-tls-artifacts/generate-owners/tlsmetadatainterfaces"
 )
 
@@ -12,5 +16,9 @@ func GetDefaultTLSRequirements() []tlsmetadatainterfaces.Requirement {
 		ownership.NewOwnerRequirement(),
 		autoregenerate_after_expiry.NewAutoRegenerateAfterOfflineExpiryRequirement(),
 		descriptions.NewDescriptionRequirement(),
+		key_strength.NewKeyStrengthRequirement(),
+		validity_refresh.NewValidityRefreshRequirement(),
+		ca_bundle_size.NewCABundleSizeRequirement(),
+		signer_chain_depth.NewSignerChainDepthRequirement(),
 	}
 }
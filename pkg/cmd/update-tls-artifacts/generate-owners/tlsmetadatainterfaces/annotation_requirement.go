@@ -72,12 +72,8 @@ func (o annotationRequirement) generateInspectionMarkdown(pkiInfo *certs.PKIRegi
 
 	for i := range pkiInfo.CertKeyPairs {
 		curr := pkiInfo.CertKeyPairs[i]
-		if curr.InClusterLocation == nil {
-			continue
-		}
-		// TODO[vrutkovs]: fetch ondisk metadata here
-		owner := curr.InClusterLocation.CertKeyInfo.OwningJiraComponent
-		regenerates, _ := AnnotationValue(curr.InClusterLocation.CertKeyInfo.SelectedCertMetadataAnnotations, o.GetAnnotationName())
+		owner := CertKeyPairInfo(curr).OwningJiraComponent
+		regenerates, _ := AnnotationValue(CertKeyPairInfo(curr).SelectedCertMetadataAnnotations, o.GetAnnotationName())
 		if len(regenerates) == 0 {
 			violatingCertsByOwner[owner] = append(violatingCertsByOwner[owner], curr)
 			continue
@@ -87,11 +83,8 @@ func (o annotationRequirement) generateInspectionMarkdown(pkiInfo *certs.PKIRegi
 	}
 	for i := range pkiInfo.CertificateAuthorityBundles {
 		curr := pkiInfo.CertificateAuthorityBundles[i]
-		if curr.InClusterLocation == nil {
-			continue
-		}
-		owner := curr.InClusterLocation.CABundleInfo.OwningJiraComponent
-		regenerates, _ := AnnotationValue(curr.InClusterLocation.CABundleInfo.SelectedCertMetadataAnnotations, o.GetAnnotationName())
+		owner := CABundleInfo(curr).OwningJiraComponent
+		regenerates, _ := AnnotationValue(CABundleInfo(curr).SelectedCertMetadataAnnotations, o.GetAnnotationName())
 		if len(regenerates) == 0 {
 			violatingCABundlesByOwner[owner] = append(violatingCABundlesByOwner[owner], curr)
 			continue
@@ -121,15 +114,7 @@ func (o annotationRequirement) generateInspectionMarkdown(pkiInfo *certs.PKIRegi
 				md.Title(4, fmt.Sprintf("Certificates (%d)", len(certs)))
 				md.OrderedListStart()
 				for _, curr := range certs {
-					if curr.InClusterLocation == nil {
-						continue
-					}
-					md.NewOrderedListItem()
-					md.Textf("ns/%v secret/%v\n", curr.InClusterLocation.SecretLocation.Namespace, curr.InClusterLocation.SecretLocation.Name)
-					md.Textf("**Description:** %v", curr.InClusterLocation.CertKeyInfo.Description)
-					md.Text("\n")
-
-					//TODO[vrutkovs]: on disk case
+					CertKeyPairListItem(md, curr)
 				}
 				md.OrderedListEnd()
 				md.Text("\n")
@@ -140,15 +125,7 @@ func (o annotationRequirement) generateInspectionMarkdown(pkiInfo *certs.PKIRegi
 				md.Title(4, fmt.Sprintf("Certificate Authority Bundles (%d)", len(caBundles)))
 				md.OrderedListStart()
 				for _, curr := range caBundles {
-					if curr.InClusterLocation == nil {
-						continue
-					}
-					md.NewOrderedListItem()
-					md.Textf("ns/%v configmap/%v\n", curr.InClusterLocation.ConfigMapLocation.Namespace, curr.InClusterLocation.ConfigMapLocation.Name)
-					md.Textf("**Description:** %v", curr.InClusterLocation.CABundleInfo.Description)
-					md.Text("\n")
-
-					//TODO[vrutkovs]: on disk case
+					CABundleListItem(md, curr)
 				}
 				md.OrderedListEnd()
 				md.Text("\n")
@@ -173,13 +150,8 @@ func (o annotationRequirement) generateInspectionMarkdown(pkiInfo *certs.PKIRegi
 			md.Title(4, fmt.Sprintf("Certificates (%d)", len(certs)))
 			md.OrderedListStart()
 			for _, curr := range certs {
-				md.NewOrderedListItem()
-				md.Textf("ns/%v secret/%v\n", curr.InClusterLocation.SecretLocation.Namespace, curr.InClusterLocation.SecretLocation.Name)
-				md.Textf("**Description:** %v", curr.InClusterLocation.CertKeyInfo.Description)
-				md.Text("\n")
+				CertKeyPairListItem(md, curr)
 			}
-			//TODO[vrutkovs]: on disk case
-
 			md.OrderedListEnd()
 			md.Text("\n")
 		}
@@ -189,17 +161,8 @@ func (o annotationRequirement) generateInspectionMarkdown(pkiInfo *certs.PKIRegi
 			md.Title(4, fmt.Sprintf("Certificate Authority Bundles (%d)", len(caBundles)))
 			md.OrderedListStart()
 			for _, curr := range caBundles {
-				if curr.InClusterLocation == nil {
-					continue
-				}
-
-				md.NewOrderedListItem()
-				md.Textf("ns/%v configmap/%v\n", curr.InClusterLocation.ConfigMapLocation.Namespace, curr.InClusterLocation.ConfigMapLocation.Name)
-				md.Textf("**Description:** %v", curr.InClusterLocation.CABundleInfo.Description)
-				md.Text("\n")
+				CABundleListItem(md, curr)
 			}
-			//TODO[vrutkovs]: on disk case
-
 			md.OrderedListEnd()
 			md.Text("\n")
 		}
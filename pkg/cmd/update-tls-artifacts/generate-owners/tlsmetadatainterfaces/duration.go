@@ -0,0 +1,37 @@
+package tlsmetadatainterfaces
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var durationPart = regexp.MustCompile(`(\d+)([ydhms])`)
+
+var durationUnits = map[string]time.Duration{
+	"y": 365 * 24 * time.Hour,
+	"d": 24 * time.Hour,
+	"h": time.Hour,
+	"m": time.Minute,
+	"s": time.Second,
+}
+
+// ParseDuration parses durations like the ValidityDuration of the raw data, for instance 2y60d or 12h.
+func ParseDuration(value string) (time.Duration, error) {
+	parts := durationPart.FindAllStringSubmatch(value, -1)
+	matched := 0
+	var ret time.Duration
+	for _, part := range parts {
+		matched += len(part[0])
+		count, err := strconv.Atoi(part[1])
+		if err != nil {
+			return 0, err
+		}
+		ret += time.Duration(count) * durationUnits[part[2]]
+	}
+	if len(parts) == 0 || matched != len(value) {
+		return 0, fmt.Errorf("%q is not a duration like 2y60d", value)
+	}
+	return ret, nil
+}
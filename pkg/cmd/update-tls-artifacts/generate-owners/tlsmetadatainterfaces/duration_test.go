@@ -0,0 +1,30 @@
+package tlsmetadatainterfaces
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"2y":    2 * 365 * 24 * time.Hour,
+		"2y60d": (2*365 + 60) * 24 * time.Hour,
+		"12h":   12 * time.Hour,
+		"1h30m": 90 * time.Minute,
+	} {
+		actual, err := ParseDuration(value)
+		if err != nil {
+			t.Errorf("%q: %v", value, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("%q: expected %v, got %v", value, expected, actual)
+		}
+	}
+
+	for _, value := range []string{"", "2 years", "30", "1w"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
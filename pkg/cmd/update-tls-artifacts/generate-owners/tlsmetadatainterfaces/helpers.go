@@ -38,6 +38,46 @@ func AnnotationValue(whitelistedAnnotations []certgraphapi.AnnotationValue, key
 	return "", false
 }
 
+// CertKeyPairInfo is the metadata of the secret or file.
+func CertKeyPairInfo(curr certgraphapi.PKIRegistryCertKeyPair) certgraphapi.PKIRegistryCertKeyPairInfo {
+	if curr.InClusterLocation != nil {
+		return curr.InClusterLocation.CertKeyInfo
+	}
+	return curr.OnDiskLocation.CertKeyInfo
+}
+
+// CABundleInfo is the metadata of the configmap or file.
+func CABundleInfo(curr certgraphapi.PKIRegistryCABundle) certgraphapi.PKIRegistryCertificateAuthorityInfo {
+	if curr.InClusterLocation != nil {
+		return curr.InClusterLocation.CABundleInfo
+	}
+	return curr.OnDiskLocation.CABundleInfo
+}
+
+// CertKeyPairListItem adds the location and description of the secret or file to an ordered list.
+func CertKeyPairListItem(md *Markdown, curr certgraphapi.PKIRegistryCertKeyPair) {
+	md.NewOrderedListItem()
+	if curr.InClusterLocation != nil {
+		md.Textf("ns/%v secret/%v\n", curr.InClusterLocation.SecretLocation.Namespace, curr.InClusterLocation.SecretLocation.Name)
+	} else {
+		md.Textf("file %v\n", curr.OnDiskLocation.OnDiskLocation.Path)
+	}
+	md.Textf("**Description:** %v", CertKeyPairInfo(curr).Description)
+	md.Text("\n")
+}
+
+// CABundleListItem adds the location and description of the configmap or file to an ordered list.
+func CABundleListItem(md *Markdown, curr certgraphapi.PKIRegistryCABundle) {
+	md.NewOrderedListItem()
+	if curr.InClusterLocation != nil {
+		md.Textf("ns/%v configmap/%v\n", curr.InClusterLocation.ConfigMapLocation.Namespace, curr.InClusterLocation.ConfigMapLocation.Name)
+	} else {
+		md.Textf("file %v\n", curr.OnDiskLocation.OnDiskLocation.Path)
+	}
+	md.Textf("**Description:** %v", CABundleInfo(curr).Description)
+	md.Text("\n")
+}
+
 func ProcessByLocation(rawData []*certgraphapi.PKIList) (*certs.PKIRegistryInfo, error) {
 	errs := []error{}
 	inClusterCertKeyPairs := certs.SecretInfoByNamespaceName{}
@@ -71,5 +111,33 @@ func ProcessByLocation(rawData []*certgraphapi.PKIList) (*certs.PKIRegistryInfo,
 		return nil, utilerrors.NewAggregate(errs)
 	}
 
-	return certs.CertsToRegistryInfo(inClusterCertKeyPairs, onDiskCertKeyPairs, inClusterCABundles, onDiskCABundles), nil
+	// files on disk have no annotations, so those that are not known above are audited without an owner.
+	allOnDiskCertKeyPairs := certs.CertKeyPairInfoByOnDiskLocation{}
+	for location, info := range onDiskCertKeyPairs {
+		allOnDiskCertKeyPairs[location] = info
+	}
+	allOnDiskCABundles := certs.CABundleInfoByOnDiskLocation{}
+	for location, info := range onDiskCABundles {
+		allOnDiskCABundles[location] = info
+	}
+	for _, currPKI := range rawData {
+		for _, currCert := range currPKI.CertKeyPairs.Items {
+			for _, location := range currCert.Spec.OnDiskLocations {
+				for _, file := range []certgraphapi.OnDiskLocation{location.Cert, location.Key} {
+					if _, ok := allOnDiskCertKeyPairs[file]; !ok && len(file.Path) > 0 {
+						allOnDiskCertKeyPairs[file] = certgraphapi.PKIRegistryCertKeyPairInfo{OwningJiraComponent: UnknownOwner}
+					}
+				}
+			}
+		}
+		for _, currCABundle := range currPKI.CertificateAuthorityBundles.Items {
+			for _, file := range currCABundle.Spec.OnDiskLocations {
+				if _, ok := allOnDiskCABundles[file]; !ok && len(file.Path) > 0 {
+					allOnDiskCABundles[file] = certgraphapi.PKIRegistryCertificateAuthorityInfo{OwningJiraComponent: UnknownOwner}
+				}
+			}
+		}
+	}
+
+	return certs.CertsToRegistryInfo(inClusterCertKeyPairs, allOnDiskCertKeyPairs, inClusterCABundles, allOnDiskCABundles), nil
 }
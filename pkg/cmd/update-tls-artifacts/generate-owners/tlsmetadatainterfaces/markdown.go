@@ -29,17 +29,17 @@ func (m *Markdown) Bytes() []byte {
 	ret := &bytes.Buffer{}
 	fmt.Fprintf(ret, "# %s\n\n", m.title)
 	fmt.Fprintf(ret, "## Table of Contents\n")
-	fmt.Fprintf(ret, m.tableOfContents.String())
+	fmt.Fprint(ret, m.tableOfContents.String())
 	fmt.Fprintln(ret, "")
 	fmt.Fprintln(ret, "")
-	fmt.Fprintf(ret, m.body.String())
+	fmt.Fprint(ret, m.body.String())
 	return ret.Bytes()
 }
 
 // ExactBytes returns markdown with table of contents or title.  Useful for embedding.
 func (m *Markdown) ExactBytes() []byte {
 	ret := &bytes.Buffer{}
-	fmt.Fprintf(ret, m.body.String())
+	fmt.Fprint(ret, m.body.String())
 	return ret.Bytes()
 }
 
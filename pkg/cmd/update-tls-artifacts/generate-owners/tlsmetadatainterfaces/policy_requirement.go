@@ -0,0 +1,237 @@
+package tlsmetadatainterfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/certs/cert-inspection/certgraphapi"
+	"github.com/openshift/origin/pkg/certs"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Policy checks the certificate details of the raw data, which the annotation and ownership requirements do not look at.
+type Policy interface {
+	// CertKeyPairViolation returns why the cert/key pair violates the policy, or an empty string if it complies.
+	CertKeyPairViolation(certKeyPair certgraphapi.CertKeyPair, info certgraphapi.PKIRegistryCertKeyPairInfo) string
+	// CABundleViolation returns why the CA bundle violates the policy, or an empty string if it complies.
+	CABundleViolation(caBundle certgraphapi.CertificateAuthorityBundle, info certgraphapi.PKIRegistryCertificateAuthorityInfo) string
+}
+
+type policyRequirement struct {
+	// requirementName is a unique name for metadata requirement
+	requirementName string
+	// title for the markdown
+	title string
+	// explanationMD is exactly the markdown to include that explains the purposes of the check
+	explanationMD string
+	// newPolicy builds the policy for a set of raw data, for policies that relate certificates to each other.
+	newPolicy func(rawData []*certgraphapi.PKIList) Policy
+}
+
+func NewPolicyRequirement(requirementName, title, explanationMD string, newPolicy func(rawData []*certgraphapi.PKIList) Policy) Requirement {
+	return policyRequirement{
+		requirementName: requirementName,
+		title:           title,
+		explanationMD:   explanationMD,
+		newPolicy:       newPolicy,
+	}
+}
+
+func (o policyRequirement) GetName() string {
+	return o.requirementName
+}
+
+func (o policyRequirement) InspectRequirement(rawData []*certgraphapi.PKIList) (RequirementResult, error) {
+	pkiInfo, err := ProcessByLocation(rawData)
+	if err != nil {
+		return nil, fmt.Errorf("transforming raw data %v: %w", o.GetName(), err)
+	}
+	certKeyPairViolations, caBundleViolations := inClusterViolations(rawData, o.newPolicy(rawData))
+
+	statusJSONBytes, err := json.MarshalIndent(pkiInfo, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failure marshalling %v.json: %w", o.GetName(), err)
+	}
+	markdown := o.generateInspectionMarkdown(pkiInfo, certKeyPairViolations, caBundleViolations)
+	violations := &certs.PKIRegistryInfo{}
+	for _, curr := range pkiInfo.CertKeyPairs {
+		if curr.InClusterLocation != nil && len(certKeyPairViolations[curr.InClusterLocation.SecretLocation]) > 0 {
+			violations.CertKeyPairs = append(violations.CertKeyPairs, curr)
+		}
+	}
+	for _, curr := range pkiInfo.CertificateAuthorityBundles {
+		if curr.InClusterLocation != nil && len(caBundleViolations[curr.InClusterLocation.ConfigMapLocation]) > 0 {
+			violations.CertificateAuthorityBundles = append(violations.CertificateAuthorityBundles, curr)
+		}
+	}
+	violationJSONBytes, err := json.MarshalIndent(violations, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failure marshalling %v-violations.json: %w", o.GetName(), err)
+	}
+
+	return NewRequirementResult(
+		o.GetName(),
+		statusJSONBytes,
+		markdown,
+		violationJSONBytes)
+}
+
+// inClusterViolations returns why the secrets and configmaps violate the policy.  The raw data of every platform is
+// checked, so a location can have several reasons.
+func inClusterViolations(rawData []*certgraphapi.PKIList, policy Policy) (map[certgraphapi.InClusterSecretLocation]sets.String, map[certgraphapi.InClusterConfigMapLocation]sets.String) {
+	certKeyPairViolations := map[certgraphapi.InClusterSecretLocation]sets.String{}
+	caBundleViolations := map[certgraphapi.InClusterConfigMapLocation]sets.String{}
+
+	for _, currPKI := range rawData {
+		certKeyInfo := map[certgraphapi.InClusterSecretLocation]certgraphapi.PKIRegistryCertKeyPairInfo{}
+		for _, curr := range currPKI.InClusterResourceData.CertKeyPairs {
+			certKeyInfo[curr.SecretLocation] = curr.CertKeyInfo
+		}
+		caBundleInfo := map[certgraphapi.InClusterConfigMapLocation]certgraphapi.PKIRegistryCertificateAuthorityInfo{}
+		for _, curr := range currPKI.InClusterResourceData.CertificateAuthorityBundles {
+			caBundleInfo[curr.ConfigMapLocation] = curr.CABundleInfo
+		}
+
+		for _, certKeyPair := range currPKI.CertKeyPairs.Items {
+			for _, location := range certKeyPair.Spec.SecretLocations {
+				violation := policy.CertKeyPairViolation(certKeyPair, certKeyInfo[location])
+				if len(violation) == 0 {
+					continue
+				}
+				if _, ok := certKeyPairViolations[location]; !ok {
+					certKeyPairViolations[location] = sets.NewString()
+				}
+				certKeyPairViolations[location].Insert(violation)
+			}
+		}
+		for _, caBundle := range currPKI.CertificateAuthorityBundles.Items {
+			for _, location := range caBundle.Spec.ConfigMapLocations {
+				violation := policy.CABundleViolation(caBundle, caBundleInfo[location])
+				if len(violation) == 0 {
+					continue
+				}
+				if _, ok := caBundleViolations[location]; !ok {
+					caBundleViolations[location] = sets.NewString()
+				}
+				caBundleViolations[location].Insert(violation)
+			}
+		}
+	}
+
+	return certKeyPairViolations, caBundleViolations
+}
+
+func (o policyRequirement) generateInspectionMarkdown(pkiInfo *certs.PKIRegistryInfo,
+	certKeyPairViolations map[certgraphapi.InClusterSecretLocation]sets.String,
+	caBundleViolations map[certgraphapi.InClusterConfigMapLocation]sets.String) []byte {
+
+	compliantCertsByOwner := map[string][]certgraphapi.PKIRegistryCertKeyPair{}
+	violatingCertsByOwner := map[string][]certgraphapi.PKIRegistryCertKeyPair{}
+	compliantCABundlesByOwner := map[string][]certgraphapi.PKIRegistryCABundle{}
+	violatingCABundlesByOwner := map[string][]certgraphapi.PKIRegistryCABundle{}
+	for _, curr := range pkiInfo.CertKeyPairs {
+		if curr.InClusterLocation == nil {
+			continue
+		}
+		owner := curr.InClusterLocation.CertKeyInfo.OwningJiraComponent
+		if len(certKeyPairViolations[curr.InClusterLocation.SecretLocation]) > 0 {
+			violatingCertsByOwner[owner] = append(violatingCertsByOwner[owner], curr)
+			continue
+		}
+		compliantCertsByOwner[owner] = append(compliantCertsByOwner[owner], curr)
+	}
+	for _, curr := range pkiInfo.CertificateAuthorityBundles {
+		if curr.InClusterLocation == nil {
+			continue
+		}
+		owner := curr.InClusterLocation.CABundleInfo.OwningJiraComponent
+		if len(caBundleViolations[curr.InClusterLocation.ConfigMapLocation]) > 0 {
+			violatingCABundlesByOwner[owner] = append(violatingCABundlesByOwner[owner], curr)
+			continue
+		}
+		compliantCABundlesByOwner[owner] = append(compliantCABundlesByOwner[owner], curr)
+	}
+
+	md := NewMarkdown(o.title)
+	md.Title(2, "How to meet the requirement")
+	md.ExactText(o.explanationMD)
+
+	if len(violatingCertsByOwner) > 0 || len(violatingCABundlesByOwner) > 0 {
+		numViolators := 0
+		for _, v := range violatingCertsByOwner {
+			numViolators += len(v)
+		}
+		for _, v := range violatingCABundlesByOwner {
+			numViolators += len(v)
+		}
+		md.Title(2, fmt.Sprintf("Items Do NOT Meet the Requirement (%d)", numViolators))
+		violatingOwners := sets.StringKeySet(violatingCertsByOwner)
+		violatingOwners.Insert(sets.StringKeySet(violatingCABundlesByOwner).UnsortedList()...)
+		for _, owner := range violatingOwners.List() {
+			md.Title(3, fmt.Sprintf("%s (%d)", owner, len(violatingCertsByOwner[owner])+len(violatingCABundlesByOwner[owner])))
+			certs := violatingCertsByOwner[owner]
+			if len(certs) > 0 {
+				md.Title(4, fmt.Sprintf("Certificates (%d)", len(certs)))
+				md.OrderedListStart()
+				for _, curr := range certs {
+					CertKeyPairListItem(md, curr)
+					md.Textf("**Violation:** %v", strings.Join(certKeyPairViolations[curr.InClusterLocation.SecretLocation].List(), "; "))
+					md.Text("\n")
+				}
+				md.OrderedListEnd()
+				md.Text("\n")
+			}
+
+			caBundles := violatingCABundlesByOwner[owner]
+			if len(caBundles) > 0 {
+				md.Title(4, fmt.Sprintf("Certificate Authority Bundles (%d)", len(caBundles)))
+				md.OrderedListStart()
+				for _, curr := range caBundles {
+					CABundleListItem(md, curr)
+					md.Textf("**Violation:** %v", strings.Join(caBundleViolations[curr.InClusterLocation.ConfigMapLocation].List(), "; "))
+					md.Text("\n")
+				}
+				md.OrderedListEnd()
+				md.Text("\n")
+			}
+		}
+	}
+
+	numCompliant := 0
+	for _, v := range compliantCertsByOwner {
+		numCompliant += len(v)
+	}
+	for _, v := range compliantCABundlesByOwner {
+		numCompliant += len(v)
+	}
+	md.Title(2, fmt.Sprintf("Items That DO Meet the Requirement (%d)", numCompliant))
+	compliantOwners := sets.StringKeySet(compliantCertsByOwner)
+	compliantOwners.Insert(sets.StringKeySet(compliantCABundlesByOwner).UnsortedList()...)
+	for _, owner := range compliantOwners.List() {
+		md.Title(3, fmt.Sprintf("%s (%d)", owner, len(compliantCertsByOwner[owner])+len(compliantCABundlesByOwner[owner])))
+		certs := compliantCertsByOwner[owner]
+		if len(certs) > 0 {
+			md.Title(4, fmt.Sprintf("Certificates (%d)", len(certs)))
+			md.OrderedListStart()
+			for _, curr := range certs {
+				CertKeyPairListItem(md, curr)
+			}
+			md.OrderedListEnd()
+			md.Text("\n")
+		}
+
+		caBundles := compliantCABundlesByOwner[owner]
+		if len(caBundles) > 0 {
+			md.Title(4, fmt.Sprintf("Certificate Authority Bundles (%d)", len(caBundles)))
+			md.OrderedListStart()
+			for _, curr := range caBundles {
+				CABundleListItem(md, curr)
+			}
+			md.OrderedListEnd()
+			md.Text("\n")
+		}
+	}
+
+	return md.Bytes()
+}
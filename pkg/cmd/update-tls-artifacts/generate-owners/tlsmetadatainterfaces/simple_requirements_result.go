@@ -119,7 +119,14 @@ func (s SimpleRequirementsResult) HaveViolationsRegressed(allViolationsFS embed.
 				)
 			}
 		}
-		// TODO[vrutkovs]: add currCertKeyPair.OnDiskLocation
+		if currCertKeyPair.OnDiskLocation != nil {
+			currLocation := currCertKeyPair.OnDiskLocation.OnDiskLocation
+			if _, err := certgraphutils.LocateCertKeyPairByOnDiskLocation(currLocation, existingViolations.CertKeyPairs); err != nil {
+				regressions = append(regressions,
+					fmt.Sprintf("requirment/%v: file %v regressed and does not have an owner", s.GetName(), currLocation.Path),
+				)
+			}
+		}
 	}
 
 	for _, currCABundle := range resultingViolations.CertificateAuthorityBundles {
@@ -133,7 +140,14 @@ func (s SimpleRequirementsResult) HaveViolationsRegressed(allViolationsFS embed.
 				)
 			}
 		}
-		// TODO[vrutkovs]: add currCABundle.OnDiskLocation
+		if currCABundle.OnDiskLocation != nil {
+			currLocation := currCABundle.OnDiskLocation.OnDiskLocation
+			if _, err := certgraphutils.LocateCABundleByOnDiskLocation(currLocation, existingViolations.CertificateAuthorityBundles); err != nil {
+				regressions = append(regressions,
+					fmt.Sprintf("requirment/%v: file %v regressed and does not have an owner", s.GetName(), currLocation.Path),
+				)
+			}
+		}
 	}
 
 	if len(regressions) > 0 {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortests/authentication/disruptionoauthlogin"
 	"github.com/openshift/origin/pkg/monitortests/authentication/legacyauthenticationmonitortests"
 	"github.com/openshift/origin/pkg/monitortests/authentication/requiredsccmonitortests"
 	azuremetrics "github.com/openshift/origin/pkg/monitortests/cloud/azure/metrics"
@@ -19,6 +20,8 @@ import (
 	"github.com/openshift/origin/pkg/monitortests/kubeapiserver/disruptionnewapiserver"
 	"github.com/openshift/origin/pkg/monitortests/kubeapiserver/legacykubeapiservermonitortests"
 	"github.com/openshift/origin/pkg/monitortests/monitoring/disruptionmetricsapi"
+	"github.com/openshift/origin/pkg/monitortests/monitoring/prometheusrulereferences"
+	"github.com/openshift/origin/pkg/monitortests/monitoring/prometheussnapshotcollector"
 	"github.com/openshift/origin/pkg/monitortests/monitoring/statefulsetsrecreation"
 	"github.com/openshift/origin/pkg/monitortests/network/disruptioningress"
 	"github.com/openshift/origin/pkg/monitortests/network/disruptionpodnetwork"
@@ -33,9 +36,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortests/testframework/additionaleventscollector"
 	"github.com/openshift/origin/pkg/monitortests/testframework/alertanalyzer"
 	"github.com/openshift/origin/pkg/monitortests/testframework/clusterinfoserializer"
-	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionexternalawscloudservicemonitoring"
-	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionexternalazurecloudservicemonitoring"
-	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionexternalgcpcloudservicemonitoring"
+	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionconsensus"
 	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionexternalservicemonitoring"
 	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionserializer"
 	"github.com/openshift/origin/pkg/monitortests/testframework/e2etestanalyzer"
@@ -69,6 +70,11 @@ func ListAllMonitorTests() []string {
 	return monitorNames
 }
 
+// ListOptInMonitorTests returns the names of the monitor tests that only run when they are enabled.
+func ListOptInMonitorTests() []string {
+	return newOptInMonitorTests(monitortestframework.MonitorTestInitializationInfo{}).ListMonitorTests().List()
+}
+
 func NewMonitorTestsFor(info monitortestframework.MonitorTestInitializationInfo) (monitortestframework.MonitorTestRegistry, error) {
 
 	// get tests and apply any filtering defined in info
@@ -76,13 +82,25 @@ func NewMonitorTestsFor(info monitortestframework.MonitorTestInitializationInfo)
 
 	switch info.ClusterStabilityDuringTest {
 	case monitortestframework.Stable:
-		startingRegistry = newDefaultMonitorTests(info)
+		var err error
+		startingRegistry, err = newDefaultMonitorTests(info)
+		if err != nil {
+			return nil, err
+		}
 	case monitortestframework.Disruptive:
 		startingRegistry = newDisruptiveMonitorTests(info)
 	default:
 		panic(fmt.Sprintf("unknown cluster stability level: %q", info.ClusterStabilityDuringTest))
 	}
 
+	if len(info.EnableMonitorTests) > 0 {
+		optInRegistry, err := newOptInMonitorTests(info).GetRegistryFor(info.EnableMonitorTests...)
+		if err != nil {
+			return nil, err
+		}
+		startingRegistry.AddRegistryOrDie(optInRegistry)
+	}
+
 	switch {
 	case len(info.ExactMonitorTests) > 0:
 		return startingRegistry.GetRegistryFor(info.ExactMonitorTests...)
@@ -96,7 +114,7 @@ func NewMonitorTestsFor(info monitortestframework.MonitorTestInitializationInfo)
 	return startingRegistry, nil
 }
 
-func newDefaultMonitorTests(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTestRegistry {
+func newDefaultMonitorTests(info monitortestframework.MonitorTestInitializationInfo) (monitortestframework.MonitorTestRegistry, error) {
 	monitorTestRegistry := monitortestframework.NewMonitorTestRegistry()
 
 	monitorTestRegistry.AddRegistryOrDie(newUniversalMonitorTests(info))
@@ -111,15 +129,31 @@ func newDefaultMonitorTests(info monitortestframework.MonitorTestInitializationI
 	monitorTestRegistry.AddMonitorTestOrDie("ingress-availability", "Networking / router", disruptioningress.NewAvailabilityInvariant())
 
 	monitorTestRegistry.AddMonitorTestOrDie("alert-summary-serializer", "Test Framework", alertanalyzer.NewAlertSummarySerializer())
-	monitorTestRegistry.AddMonitorTestOrDie("external-service-availability", "Test Framework", disruptionexternalservicemonitoring.NewAvailabilityInvariant())
-	monitorTestRegistry.AddMonitorTestOrDie("external-gcp-cloud-service-availability", "Test Framework", disruptionexternalgcpcloudservicemonitoring.NewCloudAvailabilityInvariant())
-	monitorTestRegistry.AddMonitorTestOrDie("external-aws-cloud-service-availability", "Test Framework", disruptionexternalawscloudservicemonitoring.NewCloudAvailabilityInvariant())
-	monitorTestRegistry.AddMonitorTestOrDie("external-azure-cloud-service-availability", "Test Framework", disruptionexternalazurecloudservicemonitoring.NewCloudAvailabilityInvariant())
+	externalServiceProviders, err := disruptionexternalservicemonitoring.LoadProviders()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the external service providers: %w", err)
+	}
+	for _, provider := range externalServiceProviders {
+		monitorTestRegistry.AddMonitorTestOrDie(provider.MonitorTestName, "Test Framework", disruptionexternalservicemonitoring.NewAvailabilityInvariant(provider))
+	}
 	monitorTestRegistry.AddMonitorTestOrDie("pathological-event-analyzer", "Test Framework", pathologicaleventanalyzer.NewAnalyzer())
 	monitorTestRegistry.AddMonitorTestOrDie("disruption-summary-serializer", "Test Framework", disruptionserializer.NewDisruptionSummarySerializer())
+	monitorTestRegistry.AddMonitorTestOrDie("disruption-consensus-analyzer", "Test Framework", disruptionconsensus.NewAnalyzer())
 
 	monitorTestRegistry.AddMonitorTestOrDie("monitoring-statefulsets-recreation", "Monitoring", statefulsetsrecreation.NewStatefulsetsChecker())
 	monitorTestRegistry.AddMonitorTestOrDie("metrics-api-availability", "Monitoring", disruptionmetricsapi.NewAvailabilityInvariant())
+	monitorTestRegistry.AddMonitorTestOrDie("alert-reference-validator", "Monitoring", prometheusrulereferences.NewPrometheusRuleReferences())
+
+	return monitorTestRegistry, nil
+}
+
+// newOptInMonitorTests returns the monitor tests that change the cluster to measure it, they are not part of any
+// default and only run when enabled.
+func newOptInMonitorTests(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTestRegistry {
+	monitorTestRegistry := monitortestframework.NewMonitorTestRegistry()
+
+	// adds an identity provider, which rolls out the oauth-server at the start and the end of the run.
+	monitorTestRegistry.AddMonitorTestOrDie("oauth-login-availability", "apiserver-auth", disruptionoauthlogin.NewAvailabilityInvariant())
 
 	return monitorTestRegistry
 }
@@ -134,7 +168,7 @@ func newDisruptiveMonitorTests(info monitortestframework.MonitorTestInitializati
 	// monitorTestRegistry.AddMonitorTestOrDie("apiserver-availability", "kube-apiserver", disruptionlegacyapiservers.NewRecordAvailabilityOnly())
 	// monitorTestRegistry.AddMonitorTestOrDie("service-type-load-balancer-availability", "Networking / router", disruptionserviceloadbalancer.NewRecordAvailabilityOnly())
 	// monitorTestRegistry.AddMonitorTestOrDie("ingress-availability", "Networking / router", disruptioningress.NewRecordAvailabilityOnly())
-	// monitorTestRegistry.AddMonitorTestOrDie("external-service-availability", "Test Framework", disruptionexternalservicemonitoring.NewRecordAvailabilityOnly())
+	// monitorTestRegistry.AddMonitorTestOrDie(provider.MonitorTestName, "Test Framework", disruptionexternalservicemonitoring.NewRecordAvailabilityOnly(provider))
 
 	return monitorTestRegistry
 }
@@ -180,5 +214,7 @@ func newUniversalMonitorTests(info monitortestframework.MonitorTestInitializatio
 	monitorTestRegistry.AddMonitorTestOrDie("azure-metrics-collector", "Test Framework", azuremetrics.NewAzureMetricsCollector())
 	monitorTestRegistry.AddMonitorTestOrDie("watch-request-counts-collector", "Test Framework", watchrequestcountscollector.NewWatchRequestCountSerializer())
 
+	monitorTestRegistry.AddMonitorTestOrDie("prometheus-snapshot-collector", "Monitoring", prometheussnapshotcollector.NewPrometheusSnapshotCollector())
+
 	return monitorTestRegistry
 }
@@ -0,0 +1,28 @@
+package defaultmonitortests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionexternalservicemonitoring"
+)
+
+func TestNewMonitorTestsForInvalidProviders(t *testing.T) {
+	providersFile := filepath.Join(t.TempDir(), "providers.yaml")
+	if err := os.WriteFile(providersFile, []byte("not: [valid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(disruptionexternalservicemonitoring.ProvidersFileEnvVar, providersFile)
+
+	if _, err := NewMonitorTestsFor(monitortestframework.MonitorTestInitializationInfo{
+		ClusterStabilityDuringTest: monitortestframework.Stable,
+	}); err == nil {
+		t.Errorf("expected an error for the invalid providers in %s", providersFile)
+	}
+	// flag binding lists the monitor tests, it must not crash the command.
+	if names := ListAllMonitorTests(); len(names) != 0 {
+		t.Errorf("expected no monitor tests, got %v", names)
+	}
+}
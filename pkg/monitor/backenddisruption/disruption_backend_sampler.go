@@ -65,6 +65,13 @@ type BackendSampler struct {
 	// userAgent used to sets the User-Agent HTTP Header for all requests that are sent by this sampler
 	userAgent string
 
+	// sampleObserver is optionally notified about every sample taken, used to export metrics from long-running pollers.
+	sampleObserver SampleObserver
+
+	// requestFunc optionally replaces the single GET of host+path for backends that need an exchange of several
+	// requests to prove they are available.
+	requestFunc RequestFunc
+
 	// initHTTPClient ensures we only create the http client once
 	initHTTPClient sync.Once
 	// httpClient is used to connect to the host+path
@@ -81,6 +88,11 @@ type BackendSampler struct {
 	consumptionFinished chan struct{}
 }
 
+// RequestFunc checks a backend using more than a single GET, for instance a login that must be followed by a request
+// using the credentials obtained.  The requests must carry auditID in their audit.HeaderAuditID header, so failed
+// samples can be found in the audit logs.
+type RequestFunc func(ctx context.Context, httpClient *http.Client, url, auditID string) error
+
 type routeCoordinates struct {
 	// namespace containing the route
 	namespace string
@@ -212,6 +224,20 @@ func (b *BackendSampler) WithUserAgent(userAgent string) *BackendSampler {
 	return b
 }
 
+// WithSampleObserver sets a SampleObserver that is notified about the result and latency of every sample.
+func (b *BackendSampler) WithSampleObserver(sampleObserver SampleObserver) *BackendSampler {
+	b.sampleObserver = sampleObserver
+	return b
+}
+
+// WithRequestFunc replaces the single GET of host+path with a custom exchange.  The requestFunc is handed the http
+// client for the connection type of this sampler and the URL of host+path, and must return an error if the backend
+// is not available.
+func (b *BackendSampler) WithRequestFunc(requestFunc RequestFunc) *BackendSampler {
+	b.requestFunc = requestFunc
+	return b
+}
+
 // WithExpectedBodyRegex allows a specification of specific body to be returned. This useful when passing through proxies and the
 // like since a connection may not be the one you expect.  If not specified, then the default behavior is that any 2xx
 // or 3xx response is acceptable.
@@ -361,12 +387,21 @@ func (b *BackendSampler) CheckConnection(ctx context.Context) (string, error) {
 	backstopContextTimeout := b.getTimeout() * 3 / 2 // (1.5)
 	requestContext, requestCancel := context.WithTimeout(ctx, backstopContextTimeout)
 	defer requestCancel()
+	uid := uuid.New().String()
+	if b.requestFunc != nil {
+		sampleErr := b.requestFunc(requestContext, httpClient, url, uid)
+		if requestContext.Err() == context.Canceled {
+			// this isn't an error, we were simply cancelled
+			return uid, nil
+		}
+		return uid, sampleErr
+	}
+
 	req, err := http.NewRequestWithContext(requestContext, http.MethodGet, url, nil)
 	if err != nil {
 		return "", err
 	}
 
-	uid := uuid.New().String()
 	req.Header.Set(audit.HeaderAuditID, uid)
 
 	resp, getErr := httpClient.Do(req)
@@ -440,6 +475,9 @@ func (b *BackendSampler) RunEndpointMonitoring(ctx context.Context, monitorRecor
 
 	<-samplerContext.Done()
 	<-b.consumptionFinished
+	if b.sampleObserver != nil {
+		b.sampleObserver.SamplerStopped(b.GetLocator(), b.GetConnectionType())
+	}
 
 	if disruptionSampler.numberOfSamples(ctx) > 0 {
 		return fmt.Errorf("not finished writing all samples (%d remaining), but we're told to close", disruptionSampler.numberOfSamples(ctx))
@@ -527,6 +565,9 @@ func (b *disruptionSampler) produceSamples(ctx context.Context, interval time.Du
 		currDisruptionSample := b.newSample(ctx)
 		go func() {
 			uid, sampleErr := b.backendSampler.CheckConnection(ctx)
+			if observer := b.backendSampler.sampleObserver; observer != nil && ctx.Err() == nil {
+				observer.ObserveSample(b.backendSampler.GetLocator(), b.backendSampler.GetConnectionType(), time.Since(currDisruptionSample.startTime), sampleErr)
+			}
 			currDisruptionSample.setSampleError(sampleErr)
 			currDisruptionSample.setRequestAuditID(uid)
 			if sampleErr != nil {
@@ -0,0 +1,90 @@
+package backenddisruption
+
+import (
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SampleObserver is notified about every sample taken by a BackendSampler.  ObserveSample is called from the sampling
+// goroutines, so implementations must be safe for concurrent use and must not block.
+type SampleObserver interface {
+	// ObserveSample is called once a sample has finished.  sampleErr is nil when the backend was available.
+	ObserveSample(locator monitorapi.Locator, connectionType monitorapi.BackendConnectionType, duration time.Duration, sampleErr error)
+	// SamplerStopped is called once the sampler for the locator has stopped and will produce no more samples.
+	SamplerStopped(locator monitorapi.Locator, connectionType monitorapi.BackendConnectionType)
+}
+
+// SamplerMetrics is a SampleObserver that exports the results of the samples as prometheus metrics so that long-running
+// pollers can be observed by a cluster monitoring stack outside of a test run.
+type SamplerMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	outage   *prometheus.GaugeVec
+}
+
+var samplerMetricLabels = []string{"backend", "target", "connection_type"}
+
+// NewSamplerMetrics creates the sampler metrics and registers them with the registerer.
+func NewSamplerMetrics(registerer prometheus.Registerer) (*SamplerMetrics, error) {
+	ret := &SamplerMetrics{
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "disruption_sampler_requests_total",
+				Help: "Number of samples taken against a disruption backend, partitioned by result (success or failure).",
+			},
+			append(samplerMetricLabels, "result"),
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "disruption_sampler_request_duration_seconds",
+				Help:    "Latency of the samples taken against a disruption backend, partitioned by result (success or failure).",
+				Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15},
+			},
+			append(samplerMetricLabels, "result"),
+		),
+		outage: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "disruption_sampler_outage",
+				Help: "1 if the most recent sample against a disruption backend failed, 0 otherwise.",
+			},
+			samplerMetricLabels,
+		),
+	}
+
+	for _, collector := range []prometheus.Collector{ret.requests, ret.duration, ret.outage} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func samplerMetricLabelValues(locator monitorapi.Locator, connectionType monitorapi.BackendConnectionType) []string {
+	return []string{
+		locator.Keys[monitorapi.LocatorBackendDisruptionNameKey],
+		locator.Keys[monitorapi.LocatorDisruptionKey],
+		string(connectionType),
+	}
+}
+
+func (m *SamplerMetrics) ObserveSample(locator monitorapi.Locator, connectionType monitorapi.BackendConnectionType, duration time.Duration, sampleErr error) {
+	labelValues := samplerMetricLabelValues(locator, connectionType)
+	result := "success"
+	outage := 0.0
+	if sampleErr != nil {
+		result = "failure"
+		outage = 1
+	}
+
+	m.requests.WithLabelValues(append(labelValues, result)...).Inc()
+	m.duration.WithLabelValues(append(labelValues, result)...).Observe(duration.Seconds())
+	m.outage.WithLabelValues(labelValues...).Set(outage)
+}
+
+// SamplerStopped removes the outage gauge for the stopped sampler so that targets which are gone, like removed
+// endpoints, do not report a stale outage forever.  The counters and histograms are kept because they are cumulative.
+func (m *SamplerMetrics) SamplerStopped(locator monitorapi.Locator, connectionType monitorapi.BackendConnectionType) {
+	m.outage.DeleteLabelValues(samplerMetricLabelValues(locator, connectionType)...)
+}
@@ -0,0 +1,49 @@
+package backenddisruption
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSamplerMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	samplerMetrics, err := NewSamplerMetrics(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locator := monitorapi.NewLocator().LocateDisruptionCheck("pod-to-service-new-connections", "pod-to-service-from-node-worker-a-to-clusterIP-10.0.0.1", monitorapi.NewConnectionType)
+	samplerMetrics.ObserveSample(locator, monitorapi.NewConnectionType, 10*time.Millisecond, nil)
+	samplerMetrics.ObserveSample(locator, monitorapi.NewConnectionType, 20*time.Millisecond, nil)
+	samplerMetrics.ObserveSample(locator, monitorapi.NewConnectionType, 5*time.Second, fmt.Errorf("connection refused"))
+
+	expectedRequests := `
+# HELP disruption_sampler_requests_total Number of samples taken against a disruption backend, partitioned by result (success or failure).
+# TYPE disruption_sampler_requests_total counter
+disruption_sampler_requests_total{backend="pod-to-service-new-connections",connection_type="new",result="failure",target="pod-to-service-from-node-worker-a-to-clusterIP-10.0.0.1"} 1
+disruption_sampler_requests_total{backend="pod-to-service-new-connections",connection_type="new",result="success",target="pod-to-service-from-node-worker-a-to-clusterIP-10.0.0.1"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expectedRequests), "disruption_sampler_requests_total"); err != nil {
+		t.Error(err)
+	}
+
+	expectedOutage := `
+# HELP disruption_sampler_outage 1 if the most recent sample against a disruption backend failed, 0 otherwise.
+# TYPE disruption_sampler_outage gauge
+disruption_sampler_outage{backend="pod-to-service-new-connections",connection_type="new",target="pod-to-service-from-node-worker-a-to-clusterIP-10.0.0.1"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expectedOutage), "disruption_sampler_outage"); err != nil {
+		t.Error(err)
+	}
+
+	samplerMetrics.SamplerStopped(locator, monitorapi.NewConnectionType)
+	if count := testutil.CollectAndCount(samplerMetrics.outage); count != 0 {
+		t.Errorf("expected outage gauge to be removed after the sampler stopped, found %d series", count)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
 )
 
@@ -114,3 +116,23 @@ func TestMonitor_Events(t *testing.T) {
 		})
 	}
 }
+
+func TestRecorder_RecordResource(t *testing.T) {
+	recorder := NewRecorder()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-etcd", Name: "etcd", UID: "uid"}}
+	for i := 0; i < 3; i++ {
+		recorder.RecordResource("pods", pod)
+	}
+	if len(pod.Annotations) > 0 {
+		t.Errorf("the recorded object must not be modified, got %v", pod.Annotations)
+	}
+
+	recorded := recorder.CurrentResourceState()["pods"][monitorapi.InstanceKey{Namespace: "openshift-etcd", Name: "etcd", UID: "uid"}].(*corev1.Pod)
+	expected := map[string]string{
+		monitorapi.ObservedUpdateCountAnnotation:     "3",
+		monitorapi.ObservedRecreationCountAnnotation: "0",
+	}
+	if !reflect.DeepEqual(expected, recorded.Annotations) {
+		t.Errorf("expected annotations %v, got %v", expected, recorded.Annotations)
+	}
+}
@@ -182,6 +182,15 @@ func (b *LocatorBuilder) Disruption(backendDisruptionName, thisInstanceName, loa
 	return b.Build()
 }
 
+// DisruptionConsensus locates the consensus computed across every sampler of the same logical backend.  It deliberately
+// does not set the backend-disruption-name so that consensus intervals are never counted as disruption themselves.
+func (b *LocatorBuilder) DisruptionConsensus(logicalBackend string) Locator {
+	b.targetType = LocatorTypeDisruption
+	b.annotations[LocatorDisruptionKey] = "consensus"
+	b.annotations[LocatorTargetKey] = logicalBackend
+	return b.Build()
+}
+
 // DisruptionRequiredOnly takes only the logically required data for backend-disruption.json and codifies it.
 // backendDisruptionName is the value used to store and locate historical data related to the amount of disruption.
 // thisInstanceName is used to show on a timeline which connection failed.
@@ -390,6 +399,16 @@ func (b *LocatorBuilder) ClusterOperator(name string) Locator {
 	return b.Build()
 }
 
+// ObjectFromNames locates any resource by its kind, like the generic locators of kube events.
+func (b *LocatorBuilder) ObjectFromNames(kind, namespace, name string) Locator {
+	b.targetType = LocatorTypeKind
+	b.annotations[LocatorKey(strings.ToLower(kind))] = name
+	if len(namespace) > 0 {
+		b.annotations[LocatorNamespaceKey] = namespace
+	}
+	return b.Build()
+}
+
 func (b *LocatorBuilder) Build() Locator {
 	ret := Locator{
 		Type: b.targetType,
@@ -154,11 +154,17 @@ const (
 	DisruptionBeganEventReason              IntervalReason = "DisruptionBegan"
 	DisruptionEndedEventReason              IntervalReason = "DisruptionEnded"
 	DisruptionSamplerOutageBeganEventReason IntervalReason = "DisruptionSamplerOutageBegan"
+	DisruptionBudgetThresholdExceededReason IntervalReason = "DisruptionBudgetThresholdExceeded"
 	GracefulAPIServerShutdown               IntervalReason = "GracefulAPIServerShutdown"
 	IncompleteAPIServerShutdown             IntervalReason = "IncompleteAPIServerShutdown"
 
 	HttpClientConnectionLost IntervalReason = "HttpClientConnectionLost"
 
+	DisruptionConsensusEverywhereReason         IntervalReason = "DisruptionConsensusEverywhere"
+	DisruptionConsensusOpenShiftTestsOnlyReason IntervalReason = "DisruptionConsensusOpenShiftTestsOnly"
+	DisruptionConsensusSingleNodeReason         IntervalReason = "DisruptionConsensusSingleNode"
+	DisruptionConsensusPartialReason            IntervalReason = "DisruptionConsensusPartial"
+
 	PodPendingReason               IntervalReason = "PodIsPending"
 	PodNotPendingReason            IntervalReason = "PodIsNotPending"
 	PodReasonCreated               IntervalReason = "Created"
@@ -200,6 +206,16 @@ const (
 	FailedToDeleteCGroupsPath             IntervalReason = "FailedToDeleteCGroupsPath"
 	FailedToAuthenticateWithOpenShiftUser IntervalReason = "FailedToAuthenticateWithOpenShiftUser"
 	FailedContactingAPIReason             IntervalReason = "FailedContactingAPI"
+
+	EventStormReason IntervalReason = "EventStorm"
+
+	FieldChangedReason  IntervalReason = "FieldChanged"
+	FieldFlappingReason IntervalReason = "FieldFlapping"
+
+	ResourceCreatedReason   IntervalReason = "ResourceCreated"
+	ResourceUpdatedReason   IntervalReason = "ResourceUpdated"
+	ResourceDeletedReason   IntervalReason = "ResourceDeleted"
+	GenerationChangedReason IntervalReason = "GenerationChanged"
 )
 
 type AnnotationKey string
@@ -231,6 +247,22 @@ const (
 	AnnotationRoles          AnnotationKey = "roles"
 	AnnotationStatus         AnnotationKey = "status"
 	AnnotationCondition      AnnotationKey = "condition"
+	// AnnotationAlertExpression is the expression of the rule that fired the alert.
+	AnnotationAlertExpression AnnotationKey = "expr"
+	// AnnotationAlertExpressionValue summarizes the value of the expression while the alert was firing.
+	AnnotationAlertExpressionValue AnnotationKey = "expr-value"
+	AnnotationRunbookURL           AnnotationKey = "runbook_url"
+	// AnnotationEventReason is the reason of the kube event an EventStorm interval was computed from.
+	AnnotationEventReason AnnotationKey = "event-reason"
+	// AnnotationEventsPerMinute is the peak rate of the events in an EventStorm interval.
+	AnnotationEventsPerMinute AnnotationKey = "events-per-minute"
+	// AnnotationStormEvents is the number of events in an EventStorm interval.  It is not AnnotationCount, so the
+	// storm is not counted again as a repeating event.
+	AnnotationStormEvents AnnotationKey = "storm-events"
+	// AnnotationField is the path of a field in a resource, like .spec.replicas.
+	AnnotationField AnnotationKey = "field"
+	// AnnotationManagers are the field managers that changed a field, separated by commas.
+	AnnotationManagers AnnotationKey = "managers"
 )
 
 // ConstructionOwner was originally meant to signify that an interval was derived from other intervals.
@@ -246,6 +278,7 @@ const (
 	ConstructionOwnerNodeLifecycle = "node-lifecycle-constructor"
 	ConstructionOwnerPodLifecycle  = "pod-lifecycle-constructor"
 	ConstructionOwnerEtcdLifecycle = "etcd-lifecycle-constructor"
+	ConstructionOwnerEventStorm    = "event-storm-constructor"
 )
 
 type Message struct {
@@ -267,6 +300,8 @@ const (
 	SourceAlert                     IntervalSource = "Alert"
 	SourceAPIServerShutdown         IntervalSource = "APIServerShutdown"
 	SourceDisruption                IntervalSource = "Disruption"
+	SourceDisruptionBudget          IntervalSource = "DisruptionBudget"
+	SourceDisruptionConsensus       IntervalSource = "DisruptionConsensus"
 	SourceE2ETest                   IntervalSource = "E2ETest"
 	SourceKubeEvent                 IntervalSource = "KubeEvent"
 	SourceNetworkManagerLog         IntervalSource = "NetworkMangerLog"
@@ -284,6 +319,8 @@ const (
 	SourcePathologicalEventMarker IntervalSource = "PathologicalEventMarker" // not sure if this is really helpful since the events all have a different origin
 	SourceClusterOperatorMonitor  IntervalSource = "ClusterOperatorMonitor"
 	SourceOperatorState           IntervalSource = "OperatorState"
+	SourceEventStorm              IntervalSource = "EventStorm"
+	SourceResourceWatch           IntervalSource = "ResourceWatch"
 	SourceNodeState                              = "NodeState"
 	SourcePodState                               = "PodState"
 	SourceCloudMetrics                           = "CloudMetrics"
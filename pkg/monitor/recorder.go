@@ -55,7 +55,9 @@ func (m *recorder) RecordResource(resourceType string, obj runtime.Object) {
 		m.recordedResources[resourceType] = recordedResource
 	}
 
-	newMetadata, err := meta.Accessor(obj)
+	// the annotations are added to the stored copy, the caller's object may be shared with an informer cache.
+	toStore := obj.DeepCopyObject()
+	newMetadata, err := meta.Accessor(toStore)
 	if err != nil {
 		// coding error
 		panic(err)
@@ -66,7 +68,6 @@ func (m *recorder) RecordResource(resourceType string, obj runtime.Object) {
 		UID:       fmt.Sprintf("%v", newMetadata.GetUID()),
 	}
 
-	toStore := obj.DeepCopyObject()
 	// without metadata, just stomp in the new value, we can't add annotations
 	if newMetadata == nil {
 		recordedResource[key] = toStore
@@ -107,7 +108,7 @@ func (m *recorder) RecordResource(resourceType string, obj runtime.Object) {
 	}
 
 	// set the recreate count. increment if the UIDs don't match
-	existingRecreateCountStr := existingAnnotations[monitorapi.ObservedUpdateCountAnnotation]
+	existingRecreateCountStr := existingAnnotations[monitorapi.ObservedRecreationCountAnnotation]
 	if existingMetadata.GetUID() != newMetadata.GetUID() {
 		if existingRecreateCount, err := strconv.ParseInt(existingRecreateCountStr, 10, 32); err != nil {
 			newAnnotations[monitorapi.ObservedRecreationCountAnnotation] = existingRecreateCountStr
@@ -34,6 +34,9 @@ type MonitorTestInitializationInfo struct {
 
 	// DisableMonitorTests will remove any monitor tests contained in the provided list
 	DisableMonitorTests []string
+
+	// EnableMonitorTests will add the opt-in monitor tests contained in the provided list, they do not run otherwise.
+	EnableMonitorTests []string
 }
 
 type MonitorTest interface {
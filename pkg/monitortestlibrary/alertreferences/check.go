@@ -0,0 +1,116 @@
+package alertreferences
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// StaleReference is a reference to an alert that no alerting rule can produce.
+type StaleReference struct {
+	Reference `json:",inline"`
+	Reason    string `json:"reason"`
+}
+
+// Report is the result of cross-checking origin's references with the alerting rules.
+type Report struct {
+	// StaleReferences refer to alerts that were renamed or removed, or that only exist on other platforms.
+	StaleReferences []StaleReference `json:"staleReferences,omitempty"`
+	// UncoveredCriticalAlerts are critical alerting rules that no alert test checks.
+	UncoveredCriticalAlerts []AlertRule `json:"uncoveredCriticalAlerts,omitempty"`
+}
+
+func (r Report) StaleReferenceStrings() []string {
+	ret := []string{}
+	for _, stale := range r.StaleReferences {
+		ret = append(ret, fmt.Sprintf("%v: %s", stale.Reference, stale.Reason))
+	}
+	return ret
+}
+
+func (r Report) UncoveredCriticalAlertStrings() []string {
+	ret := []string{}
+	for _, rule := range r.UncoveredCriticalAlerts {
+		ret = append(ret, fmt.Sprintf("alert/%s defined in prometheusrule/%s", rule.AlertName, rule.PrometheusRule))
+	}
+	return ret
+}
+
+func (r Report) String() string {
+	sections := []string{}
+	if len(r.StaleReferences) > 0 {
+		sections = append(sections, fmt.Sprintf("%d references to alerts without an alerting rule:\n%s",
+			len(r.StaleReferences), strings.Join(r.StaleReferenceStrings(), "\n")))
+	}
+	if len(r.UncoveredCriticalAlerts) > 0 {
+		sections = append(sections, fmt.Sprintf("%d critical alerts without an alert test:\n%s",
+			len(r.UncoveredCriticalAlerts), strings.Join(r.UncoveredCriticalAlertStrings(), "\n")))
+	}
+	if len(sections) == 0 {
+		return "every reference matches an alerting rule and every critical alert has a test"
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// Check cross-checks the references against the alerting rules.  A namespaced reference is only stale if every rule
+// for the alert pins a different namespace label, since the namespace of most alerts comes from the data.
+func Check(rules []AlertRule, references []Reference) Report {
+	rulesByAlert := map[string][]AlertRule{}
+	for _, rule := range rules {
+		rulesByAlert[rule.AlertName] = append(rulesByAlert[rule.AlertName], rule)
+	}
+
+	report := Report{}
+	tested := sets.New[string]()
+	for _, reference := range references {
+		if reference.Test {
+			tested.Insert(reference.AlertName)
+		}
+
+		alertRules, ok := rulesByAlert[reference.AlertName]
+		if !ok {
+			report.StaleReferences = append(report.StaleReferences, StaleReference{
+				Reference: reference,
+				Reason:    "no alerting rule with this name",
+			})
+			continue
+		}
+		if len(reference.AlertNamespace) == 0 {
+			continue
+		}
+		pinnedNamespaces := sets.New[string]()
+		for _, rule := range alertRules {
+			if len(rule.Namespace) == 0 || rule.Namespace == reference.AlertNamespace {
+				pinnedNamespaces = nil
+				break
+			}
+			pinnedNamespaces.Insert(rule.Namespace)
+		}
+		if pinnedNamespaces != nil {
+			report.StaleReferences = append(report.StaleReferences, StaleReference{
+				Reference: reference,
+				Reason:    fmt.Sprintf("alerting rules only fire in %v", sets.List(pinnedNamespaces)),
+			})
+		}
+	}
+
+	uncovered := map[string]AlertRule{}
+	for _, rule := range rules {
+		if rule.Severity != "critical" || tested.Has(rule.AlertName) {
+			continue
+		}
+		if _, ok := uncovered[rule.AlertName]; !ok {
+			uncovered[rule.AlertName] = rule
+		}
+	}
+	for _, rule := range uncovered {
+		report.UncoveredCriticalAlerts = append(report.UncoveredCriticalAlerts, rule)
+	}
+	sort.Slice(report.UncoveredCriticalAlerts, func(i, j int) bool {
+		return report.UncoveredCriticalAlerts[i].AlertName < report.UncoveredCriticalAlerts[j].AlertName
+	})
+
+	return report
+}
@@ -0,0 +1,80 @@
+package alertreferences
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	rules, err := ParseAlertRules([]byte(`
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  name: cluster-monitoring
+  namespace: openshift-monitoring
+spec:
+  groups:
+  - name: general
+    rules:
+    - record: cluster:up:sum
+      expr: sum(up)
+    - alert: TargetDown
+      expr: up == 0
+      labels:
+        severity: warning
+    - alert: KubeletDown
+      expr: absent(up{job="kubelet"})
+      labels:
+        severity: critical
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: monitoring.coreos.com/v1
+  kind: PrometheusRule
+  metadata:
+    name: etcd
+    namespace: openshift-etcd-operator
+  spec:
+    groups:
+    - name: etcd
+      rules:
+      - alert: etcdNoLeader
+        expr: etcd_server_has_leader == 0
+        labels:
+          severity: critical
+          namespace: openshift-etcd
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: ignored
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("expected 3 alerting rules, got %v", rules)
+	}
+
+	references := []Reference{
+		{AlertName: "TargetDown", AlertNamespace: "kube-system", Source: "alert tests", Test: true},
+		{AlertName: "etcdNoLeader", Source: "alert tests", Test: true},
+		{AlertName: "etcdNoLeader", AlertNamespace: "openshift-etcd", Source: "allowance"},
+		{AlertName: "etcdNoLeader", AlertNamespace: "openshift-e2e-loki", Source: "allowance"},
+		{AlertName: "RenamedAlert", Source: "allowance"},
+	}
+	report := Check(rules, references)
+
+	expectedStale := []string{
+		"alert/etcdNoLeader in ns/openshift-e2e-loki from allowance: alerting rules only fire in [openshift-etcd]",
+		"alert/RenamedAlert from allowance: no alerting rule with this name",
+	}
+	if actual := report.StaleReferenceStrings(); !reflect.DeepEqual(actual, expectedStale) {
+		t.Errorf("expected stale references %v, got %v", expectedStale, actual)
+	}
+
+	expectedUncovered := []string{"alert/KubeletDown defined in prometheusrule/openshift-monitoring/cluster-monitoring"}
+	if actual := report.UncoveredCriticalAlertStrings(); !reflect.DeepEqual(actual, expectedUncovered) {
+		t.Errorf("expected uncovered alerts %v, got %v", expectedUncovered, actual)
+	}
+}
@@ -0,0 +1,90 @@
+package alertreferences
+
+import (
+	"fmt"
+	"sort"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/origin/pkg/alerts"
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedalerts"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+// Reference is a place in origin that refers to an alert by name, and optionally namespace.
+type Reference struct {
+	AlertName      string `json:"alertName"`
+	AlertNamespace string `json:"alertNamespace,omitempty"`
+	// Source describes where the reference comes from, for instance the allowance list.
+	Source string `json:"source"`
+	// Test is true for references from alert tests, which are what gives an alert test coverage.
+	Test bool `json:"test,omitempty"`
+}
+
+func (r Reference) String() string {
+	if len(r.AlertNamespace) == 0 {
+		return fmt.Sprintf("alert/%s from %s", r.AlertName, r.Source)
+	}
+	return fmt.Sprintf("alert/%s in ns/%s from %s", r.AlertName, r.AlertNamespace, r.Source)
+}
+
+type allowedAlertsFunc func(featureSet configv1.FeatureSet) (allowedFiringWithBugs, allowedFiring, allowedPendingWithBugs, allowedPending alerts.MetricConditions)
+
+// OriginReferences returns every reference to an alert from the allowances in pkg/alerts, the alerts we never test
+// and the alert tests.
+func OriginReferences() []Reference {
+	ret := []Reference{}
+	seen := map[Reference]bool{}
+	add := func(reference Reference) {
+		if seen[reference] {
+			return
+		}
+		seen[reference] = true
+		ret = append(ret, reference)
+	}
+
+	allowanceLists := []struct {
+		name          string
+		allowedAlerts allowedAlertsFunc
+	}{
+		{name: "AllowedAlertsDuringConformance", allowedAlerts: alerts.AllowedAlertsDuringConformance},
+		{name: "AllowedAlertsDuringUpgrade", allowedAlerts: alerts.AllowedAlertsDuringUpgrade},
+	}
+	for _, allowanceList := range allowanceLists {
+		for _, featureSet := range []configv1.FeatureSet{configv1.Default, configv1.TechPreviewNoUpgrade} {
+			firingWithBugs, firing, pendingWithBugs, pending := allowanceList.allowedAlerts(featureSet)
+			for kind, conditions := range map[string]alerts.MetricConditions{
+				"firing with bugs":  firingWithBugs,
+				"firing":            firing,
+				"pending with bugs": pendingWithBugs,
+				"pending":           pending,
+			} {
+				for _, condition := range conditions {
+					add(Reference{
+						AlertName:      condition.AlertName,
+						AlertNamespace: condition.AlertNamespace,
+						Source:         fmt.Sprintf("%s %s", allowanceList.name, kind),
+					})
+				}
+			}
+		}
+	}
+
+	for _, alertName := range allowedalerts.AllowedAlertNames {
+		add(Reference{AlertName: alertName, Source: "AllowedAlertNames"})
+	}
+
+	for _, alertTest := range allowedalerts.AllAlertTests(&platformidentification.JobType{}, nil, allowedalerts.DefaultAllowances) {
+		// the namespaces of the alert tests come from the definitions, tests per namespace are for every namespace.
+		add(Reference{AlertName: alertTest.AlertName(), Source: "alert tests", Test: true})
+	}
+	for _, definition := range allowedalerts.GetAlertTestDefinitions().AlertTests {
+		if len(definition.Namespace) > 0 {
+			add(Reference{AlertName: definition.AlertName, AlertNamespace: definition.Namespace, Source: "alert tests", Test: true})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
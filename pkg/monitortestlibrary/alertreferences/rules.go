@@ -0,0 +1,169 @@
+package alertreferences
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+var prometheusRuleResource = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "prometheusrules"}
+
+// AlertRule is one alerting rule of a PrometheusRule.
+type AlertRule struct {
+	AlertName string `json:"alertName"`
+	Severity  string `json:"severity,omitempty"`
+	// Namespace is the namespace label the rule sets on its alerts, it is usually empty because the namespace comes
+	// from the series the expression selects.
+	Namespace string `json:"namespace,omitempty"`
+	// PrometheusRule is the namespace/name of the PrometheusRule the rule is defined in.
+	PrometheusRule string `json:"prometheusRule"`
+}
+
+// prometheusRule is the subset of monitoring.coreos.com/v1 PrometheusRule we need.
+type prometheusRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              struct {
+		Groups []struct {
+			Name  string `json:"name"`
+			Rules []struct {
+				Alert  string            `json:"alert,omitempty"`
+				Labels map[string]string `json:"labels,omitempty"`
+			} `json:"rules"`
+		} `json:"groups"`
+	} `json:"spec"`
+}
+
+func alertRulesFrom(obj *unstructured.Unstructured) ([]AlertRule, error) {
+	rule := &prometheusRule{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, rule); err != nil {
+		return nil, fmt.Errorf("unable to decode PrometheusRule %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	ret := []AlertRule{}
+	for _, group := range rule.Spec.Groups {
+		for _, curr := range group.Rules {
+			// recording rules have no alert name
+			if len(curr.Alert) == 0 {
+				continue
+			}
+			ret = append(ret, AlertRule{
+				AlertName:      curr.Alert,
+				Severity:       curr.Labels["severity"],
+				Namespace:      curr.Labels["namespace"],
+				PrometheusRule: fmt.Sprintf("%s/%s", rule.Namespace, rule.Name),
+			})
+		}
+	}
+	return ret, nil
+}
+
+// ListAlertRules returns the alerting rules of every PrometheusRule on the cluster.
+func ListAlertRules(ctx context.Context, dynamicClient dynamic.Interface) ([]AlertRule, error) {
+	prometheusRules, err := dynamicClient.Resource(prometheusRuleResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ret := []AlertRule{}
+	for i := range prometheusRules.Items {
+		rules, err := alertRulesFrom(&prometheusRules.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, rules...)
+	}
+	return ret, nil
+}
+
+// ParseAlertRules reads PrometheusRules, and lists of them, from YAML or JSON documents.  Other kinds are ignored so
+// that whole directories of manifests or must-gather output can be read.
+func ParseAlertRules(data []byte) ([]AlertRule, error) {
+	ret := []AlertRule{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		err := decoder.Decode(&obj.Object)
+		if errors.Is(err, io.EOF) {
+			return ret, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if obj.Object == nil {
+			continue
+		}
+
+		items := []unstructured.Unstructured{*obj}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, err
+			}
+			items = list.Items
+		}
+		for i := range items {
+			if items[i].GetKind() != "PrometheusRule" {
+				continue
+			}
+			rules, err := alertRulesFrom(&items[i])
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, rules...)
+		}
+	}
+}
+
+// ReadAlertRules reads the alerting rules from files and, recursively, the .yaml, .yml and .json files in directories.
+func ReadAlertRules(paths []string) ([]AlertRule, error) {
+	ret := []AlertRule{}
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			if path != root {
+				switch strings.ToLower(filepath.Ext(path)) {
+				case ".yaml", ".yml", ".json":
+				default:
+					return nil
+				}
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rules, err := ParseAlertRules(data)
+			if err != nil && path != root {
+				// directories of manifests often contain files that are not kube objects.
+				logrus.WithError(err).Warnf("skipping %s", path)
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("unable to read %s: %w", path, err)
+			}
+			ret = append(ret, rules...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
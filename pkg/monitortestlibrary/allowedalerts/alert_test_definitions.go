@@ -0,0 +1,328 @@
+package allowedalerts
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// AlertTestDefinitionsFileEnvVar replaces the alert test definitions compiled into openshift-tests with the ones in
+// the named file, so that a component team can try out new thresholds without rebuilding.
+const AlertTestDefinitionsFileEnvVar = "ALERT_TEST_DEFINITIONS_FILE"
+
+// alertTestDefinitionsVersion is the only version of the format we understand.
+const alertTestDefinitionsVersion = "v1"
+
+//go:embed alert_tests.yaml
+var alertTestDefinitionsYAML []byte
+
+// AlertTestDefinitions is the versioned file format for alert tests.  It may be YAML or JSON.
+type AlertTestDefinitions struct {
+	Version    string                `json:"version"`
+	AlertTests []AlertTestDefinition `json:"alertTests"`
+}
+
+type AlertTestPolicy string
+
+const (
+	// AlertTestPolicyDefault flakes and fails according to the allowance.
+	AlertTestPolicyDefault AlertTestPolicy = "Default"
+	// AlertTestPolicyNeverFail flakes according to the allowance, but never fails.
+	AlertTestPolicyNeverFail AlertTestPolicy = "NeverFail"
+	// AlertTestPolicyAlwaysFlake flakes if the alert reaches the state for any amount of time.
+	AlertTestPolicyAlwaysFlake AlertTestPolicy = "AlwaysFlake"
+	// AlertTestPolicyAlwaysFail fails if the alert reaches the state for any amount of time.
+	AlertTestPolicyAlwaysFail AlertTestPolicy = "AlwaysFail"
+)
+
+type AlertTestAllowanceMode string
+
+const (
+	// AlertTestAllowanceHistorical flakes above the historical P95 and fails above the historical P99.
+	AlertTestAllowanceHistorical AlertTestAllowanceMode = "Historical"
+	// AlertTestAllowanceFixed flakes and fails after the durations in the definition.
+	AlertTestAllowanceFixed AlertTestAllowanceMode = "Fixed"
+	// AlertTestAllowanceEtcdRevisionChange uses the allowance for etcd revision changes AllAlertTests is called with.
+	AlertTestAllowanceEtcdRevisionChange AlertTestAllowanceMode = "EtcdRevisionChange"
+)
+
+// AlertTestDefinition describes the AlertTests for one alert in one state.
+type AlertTestDefinition struct {
+	// AlertName is the name of the alert.
+	AlertName string `json:"alertName"`
+	// Component owns the alert and is used as the prefix of the test name.  It is required unless PerNamespace is set,
+	// in which case the owner of each namespace is used.
+	Component string `json:"component,omitempty"`
+	// Namespace limits the test to the alert firing in one namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// PerNamespace creates a test per namespace we know the owner of, plus one for all other namespaces.
+	PerNamespace bool `json:"perNamespace,omitempty"`
+	// State is pending, firing, warning or critical.
+	State string `json:"state"`
+	// Policy defaults to Default.
+	Policy AlertTestPolicy `json:"policy,omitempty"`
+	// Allowance defaults to Historical.
+	Allowance AlertTestAllowanceMode `json:"allowance,omitempty"`
+	// FlakeAfter is required for the Fixed allowance.
+	FlakeAfter *metav1.Duration `json:"flakeAfter,omitempty"`
+	// FailAfter is required for the Fixed allowance.
+	FailAfter *metav1.Duration `json:"failAfter,omitempty"`
+}
+
+var alertStatesByName = map[string]AlertState{
+	"pending":  AlertPending,
+	"firing":   AlertInfo,
+	"warning":  AlertWarning,
+	"critical": AlertCritical,
+}
+
+// key identifies the tests a definition compiles into, two definitions with the same key produce the same test names.
+func (d AlertTestDefinition) key() string {
+	namespace := d.Namespace
+	if d.PerNamespace {
+		namespace = "<per-namespace>"
+	}
+	return fmt.Sprintf("alert/%s ns/%s state/%s", d.AlertName, namespace, d.State)
+}
+
+// alertStateKey identifies the intervals a definition tests.  A perNamespace definition tests the intervals of every
+// namespace, so it overlaps any other definition with the same alertStateKey.
+func (d AlertTestDefinition) alertStateKey() string {
+	return fmt.Sprintf("alert/%s state/%s", d.AlertName, d.State)
+}
+
+func (d AlertTestDefinition) validate() []error {
+	errs := []error{}
+	if len(d.AlertName) == 0 {
+		errs = append(errs, fmt.Errorf("alertName is required"))
+	}
+	if _, ok := alertStatesByName[d.State]; !ok {
+		errs = append(errs, fmt.Errorf("state must be one of %v, not %q", sets.List(sets.KeySet(alertStatesByName)), d.State))
+	}
+	switch {
+	case d.PerNamespace && len(d.Namespace) > 0:
+		errs = append(errs, fmt.Errorf("namespace and perNamespace are mutually exclusive"))
+	case d.PerNamespace && len(d.Component) > 0:
+		errs = append(errs, fmt.Errorf("component is taken from the namespace when perNamespace is set"))
+	case !d.PerNamespace && len(d.Component) == 0:
+		errs = append(errs, fmt.Errorf("component is required"))
+	}
+
+	policy := d.Policy
+	if len(policy) == 0 {
+		policy = AlertTestPolicyDefault
+	}
+	allowance := d.Allowance
+	if len(allowance) == 0 {
+		allowance = AlertTestAllowanceHistorical
+	}
+	switch policy {
+	case AlertTestPolicyDefault, AlertTestPolicyNeverFail:
+	case AlertTestPolicyAlwaysFlake, AlertTestPolicyAlwaysFail:
+		if allowance != AlertTestAllowanceHistorical {
+			errs = append(errs, fmt.Errorf("policy %v ignores the allowance, remove allowance %v", policy, allowance))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown policy %q", policy))
+	}
+	switch allowance {
+	case AlertTestAllowanceHistorical, AlertTestAllowanceEtcdRevisionChange:
+		if d.FlakeAfter != nil || d.FailAfter != nil {
+			errs = append(errs, fmt.Errorf("flakeAfter and failAfter require allowance %v", AlertTestAllowanceFixed))
+		}
+	case AlertTestAllowanceFixed:
+		switch {
+		case d.FlakeAfter == nil || d.FailAfter == nil:
+			errs = append(errs, fmt.Errorf("allowance %v requires flakeAfter and failAfter", AlertTestAllowanceFixed))
+		case d.FlakeAfter.Duration > d.FailAfter.Duration:
+			errs = append(errs, fmt.Errorf("flakeAfter %v must not be longer than failAfter %v", d.FlakeAfter.Duration, d.FailAfter.Duration))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown allowance %q", allowance))
+	}
+
+	return errs
+}
+
+// ParseAlertTestDefinitions reads and validates alert test definitions in YAML or JSON.
+func ParseAlertTestDefinitions(data []byte) (*AlertTestDefinitions, error) {
+	definitions := &AlertTestDefinitions{}
+	if err := yaml.UnmarshalStrict(data, definitions); err != nil {
+		return nil, err
+	}
+	if definitions.Version != alertTestDefinitionsVersion {
+		return nil, fmt.Errorf("unsupported version %q, expected %q", definitions.Version, alertTestDefinitionsVersion)
+	}
+
+	errs := []string{}
+	seen := map[string]int{}
+	// perNamespaceSeen and namespacedSeen are the first perNamespace and other definitions of every alertStateKey.
+	perNamespaceSeen := map[string]int{}
+	namespacedSeen := map[string]int{}
+	for i, definition := range definitions.AlertTests {
+		for _, err := range definition.validate() {
+			errs = append(errs, fmt.Sprintf("alertTests[%d] %s: %v", i, definition.AlertName, err))
+		}
+		if previous, ok := seen[definition.key()]; ok {
+			errs = append(errs, fmt.Sprintf("alertTests[%d] %s: duplicates alertTests[%d]", i, definition.key(), previous))
+			continue
+		}
+		seen[definition.key()] = i
+
+		overlapping, overlaps := perNamespaceSeen, namespacedSeen
+		if definition.PerNamespace {
+			overlapping, overlaps = namespacedSeen, perNamespaceSeen
+		}
+		if previous, ok := overlapping[definition.alertStateKey()]; ok {
+			errs = append(errs, fmt.Sprintf("alertTests[%d] %s: overlaps alertTests[%d], perNamespace already tests every namespace", i, definition.key(), previous))
+			continue
+		}
+		if _, ok := overlaps[definition.alertStateKey()]; !ok {
+			overlaps[definition.alertStateKey()] = i
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid alert test definitions:\n%s", strings.Join(errs, "\n"))
+	}
+	return definitions, nil
+}
+
+var (
+	readAlertTestDefinitions sync.Once
+	alertTestDefinitions     *AlertTestDefinitions
+	alertTestDefinitionsErr  error
+)
+
+// GetAlertTestDefinitions returns the definitions from AlertTestDefinitionsFileEnvVar or the ones compiled in.  A file
+// that cannot be used is logged and the definitions compiled in are returned, AlertTestDefinitionsFileTestCases fails.
+func GetAlertTestDefinitions() *AlertTestDefinitions {
+	definitions, _ := getAlertTestDefinitions()
+	return definitions
+}
+
+func getAlertTestDefinitions() (*AlertTestDefinitions, error) {
+	readAlertTestDefinitions.Do(
+		func() {
+			alertTestDefinitions, alertTestDefinitionsErr = readAlertTestDefinitionsFile(os.Getenv(AlertTestDefinitionsFileEnvVar))
+			if alertTestDefinitionsErr != nil {
+				logrus.WithError(alertTestDefinitionsErr).Error("ignoring alert test definitions")
+			}
+			if alertTestDefinitions != nil {
+				return
+			}
+
+			var err error
+			alertTestDefinitions, err = ParseAlertTestDefinitions(alertTestDefinitionsYAML)
+			if err != nil {
+				panic(err)
+			}
+		})
+
+	return alertTestDefinitions, alertTestDefinitionsErr
+}
+
+// readAlertTestDefinitionsFile reads the definitions in filename, if any.
+func readAlertTestDefinitionsFile(filename string) (*AlertTestDefinitions, error) {
+	if len(filename) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s=%s: %w", AlertTestDefinitionsFileEnvVar, filename, err)
+	}
+	definitions, err := ParseAlertTestDefinitions(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s=%s: %w", AlertTestDefinitionsFileEnvVar, filename, err)
+	}
+	return definitions, nil
+}
+
+// AlertTestDefinitionsFileTestCases fails when the definitions from AlertTestDefinitionsFileEnvVar could not be used,
+// so that a broken file is noticed instead of silently running with the definitions compiled in.
+func AlertTestDefinitionsFileTestCases() []*junitapi.JUnitTestCase {
+	_, err := getAlertTestDefinitions()
+	return alertTestDefinitionsFileTestCases(err)
+}
+
+func alertTestDefinitionsFileTestCases(definitionsErr error) []*junitapi.JUnitTestCase {
+	const testName = "[sig-arch] alert test definitions from " + AlertTestDefinitionsFileEnvVar + " should be valid"
+
+	if definitionsErr == nil {
+		return []*junitapi.JUnitTestCase{{Name: testName}}
+	}
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: definitionsErr.Error(),
+			},
+		},
+	}
+}
+
+// fixedAllowance flakes and fails after durations that do not depend on the job.
+type fixedAllowance struct {
+	flakeAfter time.Duration
+	failAfter  time.Duration
+}
+
+func (d *fixedAllowance) FailAfter(key historicaldata.AlertDataKey) (time.Duration, error) {
+	return d.failAfter, nil
+}
+
+func (d *fixedAllowance) FlakeAfter(key historicaldata.AlertDataKey) time.Duration {
+	return d.flakeAfter
+}
+
+// toTests compiles the definition with the same builder the tests were written with by hand.
+func (d AlertTestDefinition) toTests(jobType *platformidentification.JobType, etcdAllowance AlertTestAllowanceCalculator) []AlertTest {
+	var builder *alertBuilder
+	if d.PerNamespace {
+		builder = newAlertTestPerNamespace(d.AlertName, jobType)
+	} else {
+		builder = newAlertTest(d.Component, d.AlertName, jobType)
+	}
+	if len(d.Namespace) > 0 {
+		builder = builder.inNamespace(d.Namespace)
+	}
+
+	switch alertStatesByName[d.State] {
+	case AlertPending:
+		builder = builder.pending()
+	case AlertInfo:
+		builder = builder.firing()
+	case AlertWarning:
+		builder = builder.warning()
+	case AlertCritical:
+		builder = builder.critical()
+	}
+
+	switch d.Allowance {
+	case AlertTestAllowanceFixed:
+		builder = builder.withAllowance(&fixedAllowance{flakeAfter: d.FlakeAfter.Duration, failAfter: d.FailAfter.Duration})
+	case AlertTestAllowanceEtcdRevisionChange:
+		builder = builder.withAllowance(etcdAllowance)
+	}
+
+	switch d.Policy {
+	case AlertTestPolicyNeverFail:
+		builder = builder.neverFail()
+	case AlertTestPolicyAlwaysFlake:
+		builder = builder.alwaysFlake()
+	case AlertTestPolicyAlwaysFail:
+		builder = builder.alwaysFail()
+	}
+
+	return builder.toTests()
+}
@@ -0,0 +1,272 @@
+package allowedalerts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+func TestEmbeddedAlertTestDefinitions(t *testing.T) {
+	definitions, err := ParseAlertTestDefinitions(alertTestDefinitionsYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	etcdAllowance := &etcdRevisionChangeAllowance{}
+	testsByName := map[string]*basicAlertTest{}
+	for _, definition := range definitions.AlertTests {
+		for _, alertTest := range definition.toTests(&platformidentification.JobType{}, etcdAllowance) {
+			if _, ok := testsByName[alertTest.InvariantTestName()]; ok {
+				t.Errorf("duplicate test name %v", alertTest.InvariantTestName())
+			}
+			testsByName[alertTest.InvariantTestName()] = alertTest.(*basicAlertTest)
+		}
+	}
+
+	targetDown, ok := testsByName["[sig-node][invariant] alert/TargetDown should not be at or above info in ns/kube-system"]
+	if !ok {
+		t.Fatalf("missing TargetDown test")
+	}
+	if _, ok := targetDown.allowanceCalculator.(*alwaysFailAllowance); !ok {
+		t.Errorf("expected TargetDown to always fail, got %T", targetDown.allowanceCalculator)
+	}
+
+	leaderChanges, ok := testsByName["[bz-etcd][invariant] alert/etcdHighNumberOfLeaderChanges should not be at or above info"]
+	if !ok {
+		t.Fatalf("missing etcdHighNumberOfLeaderChanges test")
+	}
+	if leaderChanges.allowanceCalculator != etcdAllowance {
+		t.Errorf("expected etcdHighNumberOfLeaderChanges to use the etcd allowance, got %T", leaderChanges.allowanceCalculator)
+	}
+
+	overcommit, ok := testsByName["[bz-single-node][invariant] alert/KubeMemoryOvercommit should not be at or above info"]
+	if !ok {
+		t.Fatalf("missing KubeMemoryOvercommit test")
+	}
+	if _, ok := overcommit.allowanceCalculator.(*neverFailAllowance); !ok {
+		t.Errorf("expected KubeMemoryOvercommit to never fail, got %T", overcommit.allowanceCalculator)
+	}
+
+	if _, ok := testsByName["[Unknown][invariant] alert/KubePodNotReady should not be at or above pending in all the other namespaces"]; !ok {
+		t.Errorf("expected per namespace tests for KubePodNotReady")
+	}
+}
+
+func TestParseAlertTestDefinitions(t *testing.T) {
+	tests := []struct {
+		name          string
+		yaml          string
+		expectedError string
+	}{
+		{
+			name: "fixed allowance",
+			yaml: `
+version: v1
+alertTests:
+- alertName: Foo
+  component: bz-foo
+  state: firing
+  allowance: Fixed
+  flakeAfter: 30s
+  failAfter: 5m
+`,
+		},
+		{
+			name:          "unsupported version",
+			yaml:          `version: v2`,
+			expectedError: `unsupported version "v2"`,
+		},
+		{
+			name: "duplicate",
+			yaml: `
+version: v1
+alertTests:
+- alertName: Foo
+  component: bz-foo
+  state: firing
+- alertName: Foo
+  component: bz-bar
+  state: firing
+  policy: NeverFail
+`,
+			expectedError: "alertTests[1] alert/Foo ns/ state/firing: duplicates alertTests[0]",
+		},
+		{
+			name: "perNamespace after a namespace",
+			yaml: `
+version: v1
+alertTests:
+- alertName: Foo
+  component: bz-foo
+  namespace: openshift-etcd
+  state: firing
+- alertName: Foo
+  perNamespace: true
+  state: firing
+`,
+			expectedError: "alertTests[1] alert/Foo ns/<per-namespace> state/firing: overlaps alertTests[0]",
+		},
+		{
+			name: "namespace after perNamespace",
+			yaml: `
+version: v1
+alertTests:
+- alertName: Foo
+  perNamespace: true
+  state: firing
+- alertName: Foo
+  component: bz-foo
+  namespace: openshift-etcd
+  state: firing
+`,
+			expectedError: "alertTests[1] alert/Foo ns/openshift-etcd state/firing: overlaps alertTests[0]",
+		},
+		{
+			name: "conflicting policy and allowance",
+			yaml: `
+version: v1
+alertTests:
+- alertName: Foo
+  component: bz-foo
+  state: firing
+  policy: AlwaysFail
+  allowance: EtcdRevisionChange
+`,
+			expectedError: "policy AlwaysFail ignores the allowance",
+		},
+		{
+			name: "fixed allowance without durations",
+			yaml: `
+version: v1
+alertTests:
+- alertName: Foo
+  component: bz-foo
+  state: firing
+  allowance: Fixed
+`,
+			expectedError: "allowance Fixed requires flakeAfter and failAfter",
+		},
+		{
+			name: "namespace and perNamespace",
+			yaml: `
+version: v1
+alertTests:
+- alertName: Foo
+  namespace: openshift-etcd
+  perNamespace: true
+  state: firing
+`,
+			expectedError: "namespace and perNamespace are mutually exclusive",
+		},
+		{
+			name: "unknown state",
+			yaml: `
+version: v1
+alertTests:
+- alertName: Foo
+  component: bz-foo
+  state: burning
+`,
+			expectedError: `state must be one of [critical firing pending warning], not "burning"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := ParseAlertTestDefinitions([]byte(tt.yaml))
+			switch {
+			case len(tt.expectedError) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(tt.expectedError) > 0 && (err == nil || !strings.Contains(err.Error(), tt.expectedError)):
+				t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+			case err != nil:
+				return
+			}
+
+			alertTests := definitions.AlertTests[0].toTests(&platformidentification.JobType{}, DefaultAllowances)
+			allowance := alertTests[0].(*basicAlertTest).allowanceCalculator
+			failAfter, _ := allowance.FailAfter(historicaldata.AlertDataKey{})
+			if failAfter != 5*time.Minute || allowance.FlakeAfter(historicaldata.AlertDataKey{}) != 30*time.Second {
+				t.Errorf("expected the fixed allowance, got %#v", allowance)
+			}
+		})
+	}
+}
+
+func TestReadAlertTestDefinitionsFile(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	if err := os.WriteFile(valid, []byte("version: v1\nalertTests:\n- alertName: Foo\n  component: bz-foo\n  state: firing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("version: v1\nalertTests:\n- alertName: Foo\n  state: firing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		filename      string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name:     "valid",
+			filename: valid,
+			expected: []string{"Foo"},
+		},
+		{
+			name:          "unreadable",
+			filename:      filepath.Join(dir, "missing.yaml"),
+			expectedError: "unable to read " + AlertTestDefinitionsFileEnvVar,
+		},
+		{
+			name:          "invalid",
+			filename:      invalid,
+			expectedError: "invalid " + AlertTestDefinitionsFileEnvVar,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definitions, err := readAlertTestDefinitionsFile(tt.filename)
+			junits := alertTestDefinitionsFileTestCases(err)
+			if len(junits) != 1 {
+				t.Fatalf("expected one junit, got %d", len(junits))
+			}
+			if len(tt.expectedError) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				if definitions != nil {
+					t.Errorf("expected no definitions, got %v", definitions)
+				}
+				if junits[0].FailureOutput == nil || !strings.Contains(junits[0].FailureOutput.Output, tt.expectedError) {
+					t.Errorf("expected a failing junit containing %q, got %#v", tt.expectedError, junits[0].FailureOutput)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if junits[0].FailureOutput != nil {
+				t.Errorf("expected a passing junit, got %v", junits[0].FailureOutput.Output)
+			}
+			names := []string{}
+			if definitions != nil {
+				for _, definition := range definitions.AlertTests {
+					names = append(names, definition.AlertName)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
@@ -41,7 +41,7 @@ func (d *etcdRevisionChangeAllowance) FailAfter(key historicaldata.AlertDataKey)
 
 	}
 	allowed, _, _ := getClosestPercentilesValues(key)
-	return allowed.P99, nil
+	return failAfterPercentile(allowed), nil
 }
 
 func (d *etcdRevisionChangeAllowance) FlakeAfter(key historicaldata.AlertDataKey) time.Duration {
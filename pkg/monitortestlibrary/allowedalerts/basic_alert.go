@@ -199,6 +199,15 @@ const (
 	fail
 )
 
+func (a *basicAlertTest) dataKey() historicaldata.AlertDataKey {
+	return historicaldata.AlertDataKey{
+		AlertName:      a.alertName,
+		AlertLevel:     string(a.alertState),
+		AlertNamespace: a.namespace,
+		JobType:        *a.jobType,
+	}
+}
+
 func (a *basicAlertTest) failOrFlake(firingIntervals, pendingIntervals monitorapi.Intervals) (testState, string) {
 	var alertIntervals monitorapi.Intervals
 
@@ -227,12 +236,7 @@ func (a *basicAlertTest) failOrFlake(firingIntervals, pendingIntervals monitorap
 	firingDuration := firingIntervals.Duration(1 * time.Second)
 	pendingDuration := pendingIntervals.Duration(1 * time.Second)
 
-	dataKey := historicaldata.AlertDataKey{
-		AlertName:      a.alertName,
-		AlertLevel:     string(a.alertState),
-		AlertNamespace: a.namespace,
-		JobType:        *a.jobType,
-	}
+	dataKey := a.dataKey()
 
 	failAfter, err := a.allowanceCalculator.FailAfter(dataKey)
 	if err != nil {
@@ -385,11 +389,14 @@ func (a *basicAlertTest) InvariantCheck(allEventIntervals monitorapi.Intervals,
 		}
 	}
 
+	// record which historical data the allowances came from.
+	historical := describeHistoricalData(a.dataKey())
 	switch state {
 	case pass:
 		return []*junitapi.JUnitTestCase{
 			{
-				Name: a.InvariantTestName(),
+				Name:      a.InvariantTestName(),
+				SystemOut: historical,
 			},
 		}, nil
 
@@ -403,7 +410,7 @@ func (a *basicAlertTest) InvariantCheck(allEventIntervals monitorapi.Intervals,
 				FailureOutput: &junitapi.FailureOutput{
 					Output: message,
 				},
-				SystemOut: message,
+				SystemOut: message + "\n\n" + historical,
 			},
 		}, nil
 
@@ -414,7 +421,7 @@ func (a *basicAlertTest) InvariantCheck(allEventIntervals monitorapi.Intervals,
 				FailureOutput: &junitapi.FailureOutput{
 					Output: message,
 				},
-				SystemOut: message,
+				SystemOut: message + "\n\n" + historical,
 			},
 		}, nil
 
@@ -1,6 +1,7 @@
 package allowedalerts
 
 import (
+	"fmt"
 	"time"
 
 	historicaldata2 "github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
@@ -43,7 +44,7 @@ var DefaultAllowances = &percentileAllowances{}
 
 func (d *percentileAllowances) FailAfter(key historicaldata2.AlertDataKey) (time.Duration, error) {
 	allowed, _, _ := getClosestPercentilesValues(key)
-	return allowed.P99, nil
+	return failAfterPercentile(allowed), nil
 }
 
 func (d *percentileAllowances) FlakeAfter(key historicaldata2.AlertDataKey) time.Duration {
@@ -57,6 +58,25 @@ func getClosestPercentilesValues(key historicaldata2.AlertDataKey) (historicalda
 	return GetHistoricalData().BestMatchDuration(key)
 }
 
+// failAfterPercentile returns the P99 of the historical data, unless the data came from a fallback we do not trust
+// enough to fail a test, in which case the test can only flake.
+func failAfterPercentile(allowed historicaldata2.StatisticalDuration) time.Duration {
+	if allowed != (historicaldata2.StatisticalDuration{}) && allowed.LowConfidence() {
+		return 24 * time.Hour
+	}
+	return allowed.P99
+}
+
+// describeHistoricalData describes the historical data the allowances for the key come from, for the output of tests.
+func describeHistoricalData(key historicaldata2.AlertDataKey) string {
+	allowed, details, _ := getClosestPercentilesValues(key)
+	dataset := GetHistoricalData().Dataset
+	if allowed == (historicaldata2.StatisticalDuration{}) {
+		return fmt.Sprintf("%s\nusing %v", details, dataset)
+	}
+	return fmt.Sprintf("%s %s\nusing %v", allowed.DescribeMatch(), details, dataset)
+}
+
 func alwaysFlake() AlertTestAllowanceCalculator {
 	return &alwaysFlakeAllowance{}
 }
@@ -17,6 +17,11 @@ import (
 //go:embed query_results.json
 var queryResults []byte
 
+// HistoricalDataEnvVar is a file path or URL with newer data in the format of query_results.json, used instead of the
+// embedded data when it can be verified against the sha256 in HistoricalDataEnvVar_SHA256, or <location>.sha256 when
+// that is unset.
+const HistoricalDataEnvVar = "ALERT_HISTORICAL_DATA"
+
 var (
 	readResults    sync.Once
 	historicalData *historicaldata.AlertBestMatcher
@@ -26,7 +31,7 @@ func GetHistoricalData() *historicaldata.AlertBestMatcher {
 	readResults.Do(
 		func() {
 			var err error
-			historicalData, err = historicaldata.NewAlertMatcher(queryResults)
+			historicalData, err = historicaldata.LoadAlertMatcher(HistoricalDataEnvVar, queryResults)
 			if err != nil {
 				panic(err)
 			}
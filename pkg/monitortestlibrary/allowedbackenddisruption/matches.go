@@ -3,6 +3,7 @@ package allowedbackenddisruption
 import (
 	"time"
 
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 )
 
@@ -22,3 +23,8 @@ const (
 func GetAllowedDisruption(backendName string, jobType platformidentification.JobType) (*time.Duration, string, error) {
 	return GetCurrentResults().BestMatchP99(backendName, jobType)
 }
+
+// GetAllowedDisruptionPercentiles is GetAllowedDisruption with every percentile and the confidence in the match.
+func GetAllowedDisruptionPercentiles(backendName string, jobType platformidentification.JobType) (historicaldata.StatisticalDuration, string, error) {
+	return GetCurrentResults().BestMatch(backendName, jobType)
+}
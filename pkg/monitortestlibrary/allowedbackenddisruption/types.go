@@ -49,6 +49,11 @@ order by
 `
 )
 
+// HistoricalDataEnvVar is a file path or URL with newer data in the format of query_results.json, used instead of the
+// embedded data when it can be verified against the sha256 in HistoricalDataEnvVar_SHA256, or <location>.sha256 when
+// that is unset.
+const HistoricalDataEnvVar = "DISRUPTION_HISTORICAL_DATA"
+
 //go:embed query_results.json
 var queryResults []byte
 
@@ -61,7 +66,7 @@ func GetCurrentResults() *historicaldata.DisruptionBestMatcher {
 	readResults.Do(
 		func() {
 			var err error
-			historicalData, err = historicaldata.NewDisruptionMatcher(queryResults)
+			historicalData, err = historicaldata.LoadDisruptionMatcher(HistoricalDataEnvVar, queryResults)
 			if err != nil {
 				panic(err)
 			}
@@ -0,0 +1,82 @@
+package disruptionlibrary
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func Test_CalculateAllowedDisruptionWithGrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "sub-second rounds up and adds five seconds",
+			allowed:  200 * time.Millisecond,
+			expected: 6 * time.Second,
+		},
+		{
+			name:     "small allowance adds five seconds",
+			allowed:  10 * time.Second,
+			expected: 15 * time.Second,
+		},
+		{
+			name:     "large allowance adds twenty percent",
+			allowed:  100 * time.Second,
+			expected: 120 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, _ := CalculateAllowedDisruptionWithGrace(tt.allowed)
+			if actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_disruptionBudget_ObserveSample(t *testing.T) {
+	recorder := monitor.NewRecorder()
+	budget := newDisruptionBudget(recorder, 4*time.Second)
+	locator := monitorapi.NewLocator().LocateDisruptionCheck("ingress-to-console-new-connections", "openshift-tests", monitorapi.NewConnectionType)
+
+	thresholdsCrossed := func() []string {
+		ret := []string{}
+		for _, interval := range recorder.Intervals(time.Time{}, time.Time{}) {
+			if interval.Source != monitorapi.SourceDisruptionBudget {
+				continue
+			}
+			ret = append(ret, interval.Message.Annotations[DisruptionBudgetThresholdAnnotation])
+		}
+		return ret
+	}
+
+	budget.ObserveSample(locator, monitorapi.NewConnectionType, time.Second, nil)
+	budget.ObserveSample(locator, monitorapi.NewConnectionType, time.Second, fmt.Errorf("timeout"))
+	if actual := thresholdsCrossed(); len(actual) != 0 {
+		t.Fatalf("expected no warnings after 1s of 4s, got %v", actual)
+	}
+
+	budget.ObserveSample(locator, monitorapi.NewConnectionType, time.Second, fmt.Errorf("timeout"))
+	if actual := thresholdsCrossed(); len(actual) != 1 || actual[0] != "50" {
+		t.Fatalf("expected the 50%% warning after 2s of 4s, got %v", actual)
+	}
+
+	for i := 0; i < 4; i++ {
+		budget.ObserveSample(locator, monitorapi.NewConnectionType, time.Second, fmt.Errorf("timeout"))
+	}
+	if actual := thresholdsCrossed(); len(actual) != 2 || actual[1] != "100" {
+		t.Fatalf("expected the 50%% and 100%% warnings exactly once, got %v", actual)
+	}
+	for _, interval := range recorder.Intervals(time.Time{}, time.Time{}) {
+		if interval.Level != monitorapi.Warning {
+			t.Errorf("expected budget intervals to be warnings so they are not counted as disruption: %v", interval)
+		}
+	}
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
-	"math"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/openshift/origin/pkg/monitortestlibrary/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/sirupsen/logrus"
 
 	"github.com/openshift/origin/pkg/monitor/backenddisruption"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
@@ -50,6 +51,15 @@ func (w *Availability) StartCollection(ctx context.Context, adminRESTConfig *res
 
 	w.adminRESTConfig = adminRESTConfig
 
+	// evaluate the disruption budget while the run is in progress so that long jobs show when they blew through it.
+	// The job type may change by the end of an upgrade, so the final junit still recomputes the allowance.
+	if jobType, err := platformidentification.GetJobType(ctx, adminRESTConfig); err != nil {
+		logrus.WithError(err).Warn("unable to determine job type, disruption budget will only be evaluated at the end")
+	} else {
+		w.watchDisruptionBudget(ctx, recorder, w.newConnectionDisruptionSampler, jobType)
+		w.watchDisruptionBudget(ctx, recorder, w.reusedConnectionDisruptionSampler, jobType)
+	}
+
 	if err := w.newConnectionDisruptionSampler.StartEndpointMonitoring(ctx, recorder, nil); err != nil {
 		return err
 	}
@@ -60,6 +70,18 @@ func (w *Availability) StartCollection(ctx context.Context, adminRESTConfig *res
 	return nil
 }
 
+func (w *Availability) watchDisruptionBudget(ctx context.Context, recorder monitorapi.RecorderWriter, backend *backenddisruption.BackendSampler, jobType *platformidentification.JobType) {
+	if jobType.Platform == "" {
+		return
+	}
+	allowedDisruption, _, err := historicalAllowedDisruption(ctx, backend, jobType)
+	if err != nil || allowedDisruption == (historicaldata.StatisticalDuration{}) {
+		return
+	}
+	finalAllowedDisruption, _ := CalculateAllowedDisruptionWithGrace(allowedDisruption.P99)
+	backend.WithSampleObserver(newDisruptionBudget(recorder, finalAllowedDisruption))
+}
+
 func (w *Availability) CollectData(ctx context.Context) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
 	if w == nil {
 		return nil, nil, fmt.Errorf("unable to collected data because instance is nil")
@@ -101,18 +123,21 @@ func (w *Availability) CollectData(ctx context.Context) (monitorapi.Intervals, [
 
 func createDisruptionJunit(
 	testName string,
-	allowedDisruption *time.Duration,
+	allowedDisruption historicaldata.StatisticalDuration,
 	disruptionDetails string,
 	locator monitorapi.Locator,
 	disruptedIntervals monitorapi.Intervals,
-	jobType *platformidentification.JobType) *junitapi.JUnitTestCase {
+	jobType *platformidentification.JobType,
+	dataset historicaldata.Dataset) []*junitapi.JUnitTestCase {
 
 	// Not sure what these are, but this will help find them, and we don't get any value from testing these:
 	if jobType.Platform == "" {
-		return &junitapi.JUnitTestCase{
-			Name: testName,
-			SkipMessage: &junitapi.SkipMessage{
-				Message: "Unknown platform, skipping disruption testing",
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: testName,
+				SkipMessage: &junitapi.SkipMessage{
+					Message: "Unknown platform, skipping disruption testing",
+				},
 			},
 		}
 	}
@@ -120,11 +145,14 @@ func createDisruptionJunit(
 	// Indicates there is no entry in the query_results.json data file, nor a valid fallback,
 	// we do not wish to run the test. (this likely implies we do not have the required number of
 	// runs in 3 weeks to do a reliable P99)
-	if allowedDisruption == nil {
-		return &junitapi.JUnitTestCase{
-			Name: testName,
-			SkipMessage: &junitapi.SkipMessage{
-				Message: "No historical data to calculate allowedDisruption",
+	if allowedDisruption == (historicaldata.StatisticalDuration{}) {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: testName,
+				SkipMessage: &junitapi.SkipMessage{
+					Message: "No historical data to calculate allowedDisruption",
+				},
+				SystemOut: fmt.Sprintf("%s\nusing %v", disruptionDetails, dataset),
 			},
 		}
 	}
@@ -132,56 +160,44 @@ func createDisruptionJunit(
 	disruptionDuration := disruptedIntervals.Duration(1 * time.Second)
 	roundedDisruptionDuration := disruptionDuration.Round(time.Second)
 
-	// Determine what amount of disruption we're willing to tolerate before we fail the test. We previously just
-	// enforced being over a P99 over the past 3 weeks, however the P99 fluctuates wildly even under these
-	// conditions, and the tests fail excessively on very low numbers. Thus we now also allow a grace amount to try to
-	// establish this as a first line of defence to detect egregious regressions before they merge.
-	//roundedAllowedDisruption, additionalDetails := calculateAllowedDisruptionWithGrace(*allowedDisruption)
-	allowedDetails := []string{}
-	allowedDetails = append(allowedDetails, fmt.Sprintf("P99 from historical data for similar jobs over past 3 weeks: %s",
-		*allowedDisruption))
-	if *allowedDisruption < 1*time.Second {
-		t := 1 * time.Second
-		allowedDisruption = &t
-		allowedDetails = append(allowedDetails, "rounded P99 up to always allow one second")
-	}
-
-	// Allow grace of 5s or 20%, at this layer, with one sample, we're only hoping to find really severe disruption:
-	allowedSecs := allowedDisruption.Seconds()
-	allowedSecsWithGrace := allowedSecs + 5.0
-	allowedSecsPlus20Percent := allowedSecs * 1.2
-	if allowedSecsPlus20Percent > allowedSecsWithGrace {
-		allowedSecsWithGrace = allowedSecsPlus20Percent
-		allowedDetails = append(allowedDetails, "added an additional 20% of grace")
-	} else {
-		allowedDetails = append(allowedDetails, "added an additional 5s of grace")
-	}
-	roundedFinal := int64(math.Round(allowedSecsWithGrace))
-	finalAllowedDisruption := time.Duration(roundedFinal) * time.Second
+	finalAllowedDisruption, allowedDetails := CalculateAllowedDisruptionWithGrace(allowedDisruption.P99)
+	allowedDetails = append(allowedDetails, allowedDisruption.DescribeMatch())
 
 	if roundedDisruptionDuration <= finalAllowedDisruption {
-		return &junitapi.JUnitTestCase{
-			Name: testName,
+		return []*junitapi.JUnitTestCase{
+			{
+				Name: testName,
+				SystemOut: fmt.Sprintf("%v was unreachable for %s (maxAllowed=%s) %s\n%s\nusing %v",
+					locator.OldLocator(), roundedDisruptionDuration, finalAllowedDisruption, disruptionDetails,
+					allowedDisruption.DescribeMatch(), dataset),
+			},
 		}
 	}
 
 	reason := fmt.Sprintf("%v was unreachable during disruption: %v", locator.OldLocator(), disruptionDetails)
 	describe := disruptedIntervals.Strings()
-	failureMessage := fmt.Sprintf("%s for at least %s (maxAllowed=%s):\n%s\n\n%s", reason,
+	failureMessage := fmt.Sprintf("%s for at least %s (maxAllowed=%s):\n%s\nusing %v\n\n%s", reason,
 		roundedDisruptionDuration, finalAllowedDisruption,
 		strings.Join(allowedDetails, "\n"),
+		dataset,
 		strings.Join(describe, "\n"))
 
-	return &junitapi.JUnitTestCase{
+	failure := &junitapi.JUnitTestCase{
 		Name: testName,
 		FailureOutput: &junitapi.FailureOutput{
 			Output: failureMessage,
 		},
 		SystemOut: failureMessage,
 	}
+
+	// Data from a low confidence fallback is not trusted to fail the test, only to flake it.
+	if allowedDisruption.LowConfidence() {
+		return []*junitapi.JUnitTestCase{failure, {Name: testName}}
+	}
+	return []*junitapi.JUnitTestCase{failure}
 }
 
-func (w *Availability) junitForNewConnections(ctx context.Context, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) (*junitapi.JUnitTestCase, error) {
+func (w *Availability) junitForNewConnections(ctx context.Context, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) ([]*junitapi.JUnitTestCase, error) {
 	newConnectionAllowed, newConnectionDisruptionDetails, err := historicalAllowedDisruption(ctx, w.newConnectionDisruptionSampler, jobType)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get new allowed disruption: %w", err)
@@ -195,11 +211,12 @@ func (w *Availability) junitForNewConnections(ctx context.Context, finalInterval
 				),
 			),
 			jobType,
+			allowedbackenddisruption.GetCurrentResults().Dataset,
 		),
 		nil
 }
 
-func (w *Availability) junitForReusedConnections(ctx context.Context, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) (*junitapi.JUnitTestCase, error) {
+func (w *Availability) junitForReusedConnections(ctx context.Context, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) ([]*junitapi.JUnitTestCase, error) {
 	reusedConnectionAllowed, reusedConnectionDisruptionDetails, err := historicalAllowedDisruption(ctx, w.reusedConnectionDisruptionSampler, jobType)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get reused allowed disruption: %w", err)
@@ -213,12 +230,13 @@ func (w *Availability) junitForReusedConnections(ctx context.Context, finalInter
 				),
 			),
 			jobType,
+			allowedbackenddisruption.GetCurrentResults().Dataset,
 		),
 		nil
 }
 
-func historicalAllowedDisruption(ctx context.Context, backend *backenddisruption.BackendSampler, jobType *platformidentification.JobType) (*time.Duration, string, error) {
-	return allowedbackenddisruption.GetAllowedDisruption(backend.GetDisruptionBackendName(), *jobType)
+func historicalAllowedDisruption(ctx context.Context, backend *backenddisruption.BackendSampler, jobType *platformidentification.JobType) (historicaldata.StatisticalDuration, string, error) {
+	return allowedbackenddisruption.GetAllowedDisruptionPercentiles(backend.GetDisruptionBackendName(), *jobType)
 }
 
 func (w *Availability) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
@@ -232,15 +250,15 @@ func (w *Availability) EvaluateTestsFromConstructedIntervals(ctx context.Context
 		return nil, err
 	}
 
-	newConnectionJunit, err := w.junitForNewConnections(ctx, finalIntervals, jobType)
+	newConnectionJunits, err := w.junitForNewConnections(ctx, finalIntervals, jobType)
 	if err != nil {
 		return nil, err
 	}
 
-	reusedConnectionJunit, err := w.junitForReusedConnections(ctx, finalIntervals, jobType)
+	reusedConnectionJunits, err := w.junitForReusedConnections(ctx, finalIntervals, jobType)
 	if err != nil {
 		return nil, err
 	}
 
-	return []*junitapi.JUnitTestCase{newConnectionJunit, reusedConnectionJunit}, nil
+	return append(newConnectionJunits, reusedConnectionJunits...), nil
 }
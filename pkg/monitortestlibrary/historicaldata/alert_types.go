@@ -33,6 +33,8 @@ type AlertDataKey struct {
 
 type AlertBestMatcher struct {
 	HistoricalData map[AlertDataKey]AlertStatisticalData
+	// Dataset is the data HistoricalData was loaded from.
+	Dataset Dataset
 }
 
 func NewAlertMatcher(historicalJSON []byte) (*AlertBestMatcher, error) {
@@ -73,6 +75,7 @@ func NewAlertMatcher(historicalJSON []byte) (*AlertBestMatcher, error) {
 
 	return &AlertBestMatcher{
 		HistoricalData: historicalData,
+		Dataset:        newDataset(embeddedSource, historicalJSON),
 	}, nil
 }
 
@@ -82,34 +85,21 @@ func NewAlertMatcherWithHistoricalData(data map[AlertDataKey]AlertStatisticalDat
 	}
 }
 
-func (b *AlertBestMatcher) bestMatch(key AlertDataKey) (AlertStatisticalData, string, error) {
+func (b *AlertBestMatcher) bestMatch(key AlertDataKey) (AlertStatisticalData, Match, string, error) {
 	exactMatchKey := key
 	logrus.WithField("alertName", key.AlertName).WithField("entries", len(b.HistoricalData)).
 		Debugf("searching for best match for %+v", key.JobType)
 
-	if percentiles, ok := b.HistoricalData[exactMatchKey]; ok {
-		if percentiles.JobRuns >= defaultMinJobRuns {
+	// tested in TestGetClosestP99Value in allowedalerts.
+	match, ok := findMatch(key.JobType, defaultMinJobRuns, func(candidate platformidentification.JobType) int64 {
+		return b.HistoricalData[alertDataKeyForJobType(exactMatchKey, candidate)].JobRuns
+	})
+	if ok {
+		percentiles := b.blend(exactMatchKey, match)
+		if len(match.Fallback) == 0 {
 			logrus.Infof("found exact match: %+v", percentiles)
-			return percentiles, "", nil
-		}
-		percentiles, matchReason, err := b.evaluateBestGuesser(PreviousReleaseUpgrade, exactMatchKey)
-		if err != nil {
-			return AlertStatisticalData{}, "", err
-		}
-		if percentiles != (AlertStatisticalData{}) {
-			return percentiles, matchReason, nil
-		}
-	}
-
-	// tested in TestGetClosestP95Value in allowedbackendisruption.  Should get a local test at some point.
-	for _, nextBestGuesser := range nextBestGuessers {
-		percentiles, matchReason, err := b.evaluateBestGuesser(nextBestGuesser, exactMatchKey)
-		if err != nil {
-			return AlertStatisticalData{}, "", err
-		}
-		if percentiles != (AlertStatisticalData{}) {
-			return percentiles, matchReason, nil
 		}
+		return percentiles, match, describeMatch(exactMatchKey, match), nil
 	}
 
 	// TODO: ensure our core platforms are here, error if not. We need to be sure our aggregated jobs are running this
@@ -119,38 +109,62 @@ func (b *AlertBestMatcher) bestMatch(key AlertDataKey) (AlertStatisticalData, st
 	// determination. If we did not record historical data for this NURP combination, we do not wish to enforce
 	// disruption testing on a per job basis. Return an empty data result to signal we have no data, and skip the test.
 	return AlertStatisticalData{},
+		Match{},
 		fmt.Sprintf("(no exact or fuzzy match for jobType=%#v)", key.JobType),
 		nil
 }
 
-func (b *AlertBestMatcher) evaluateBestGuesser(nextBestGuesser NextBestKey, exactMatchKey AlertDataKey) (AlertStatisticalData, string, error) {
-	nextBestJobType, ok := nextBestGuesser(exactMatchKey.JobType)
-	if !ok {
-		return AlertStatisticalData{}, "", nil
+func alertDataKeyForJobType(key AlertDataKey, jobType platformidentification.JobType) AlertDataKey {
+	return AlertDataKey{
+		AlertName:      key.AlertName,
+		AlertNamespace: key.AlertNamespace,
+		AlertLevel:     key.AlertLevel,
+
+		JobType: jobType,
 	}
-	nextBestMatchKey := AlertDataKey{
-		AlertName:      exactMatchKey.AlertName,
-		AlertNamespace: exactMatchKey.AlertNamespace,
-		AlertLevel:     exactMatchKey.AlertLevel,
+}
 
-		JobType: nextBestJobType,
+// blend returns the data of the match, averaged by job runs if it has more than one job type.
+func (b *AlertBestMatcher) blend(exactMatchKey AlertDataKey, match Match) AlertStatisticalData {
+	if len(match.JobTypes) == 1 {
+		return b.HistoricalData[alertDataKeyForJobType(exactMatchKey, match.JobTypes[0])]
 	}
-	if percentiles, ok := b.HistoricalData[nextBestMatchKey]; ok && percentiles.JobRuns >= defaultMinJobRuns {
-		return percentiles, fmt.Sprintf("(no exact match for %#v, fell back to %#v)", exactMatchKey, nextBestMatchKey), nil
+
+	ret := AlertStatisticalData{AlertDataKey: exactMatchKey}
+	for _, jobType := range match.JobTypes {
+		ret.JobRuns += b.HistoricalData[alertDataKeyForJobType(exactMatchKey, jobType)].JobRuns
+	}
+	for _, jobType := range match.JobTypes {
+		curr := b.HistoricalData[alertDataKeyForJobType(exactMatchKey, jobType)]
+		weight := blendWeight(curr.JobRuns, ret.JobRuns)
+		ret.Name = curr.Name
+		ret.P50 += weight * curr.P50
+		ret.P75 += weight * curr.P75
+		ret.P95 += weight * curr.P95
+		ret.P99 += weight * curr.P99
+		if ret.FirstObserved.IsZero() || curr.FirstObserved.Before(ret.FirstObserved) {
+			ret.FirstObserved = curr.FirstObserved
+		}
+		if curr.LastObserved.After(ret.LastObserved) {
+			ret.LastObserved = curr.LastObserved
+		}
 	}
-	return AlertStatisticalData{}, "", nil
+	return ret
 }
 
 // BestMatchDuration returns the best possible match for this historical data.  It attempts an exact match first, then
 // it attempts to match on the most important keys in order, before giving up and returning an empty default,
 // which means to skip testing against this data.
 func (b *AlertBestMatcher) BestMatchDuration(key AlertDataKey) (StatisticalDuration, string, error) {
-	rawData, details, err := b.bestMatch(key)
+	rawData, match, details, err := b.bestMatch(key)
 	// Empty data implies we have none, and thus do not want to run the test.
 	if rawData == (AlertStatisticalData{}) {
 		return StatisticalDuration{}, details, err
 	}
-	return toAlertStatisticalDuration(rawData), details, err
+	ret := toAlertStatisticalDuration(rawData)
+	ret.Fallback = match.Fallback
+	ret.Confidence = match.Confidence
+	return ret, details, err
 }
 
 func (b *AlertBestMatcher) BestMatchP99(key AlertDataKey) (*time.Duration, string, error) {
@@ -0,0 +1,316 @@
+package historicaldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The artifacts of a job run a Baseline is built from, as written by the clusterinfoserializer, disruptionserializer
+// and alertanalyzer monitor tests.
+const (
+	clusterDataFilePrefix       = "cluster-data"
+	backendDisruptionFilePrefix = "backend-disruption"
+	alertSummaryFilePrefix      = "alerts"
+)
+
+// Baseline is historical data computed from the artifacts of our own job runs, for job types the upstream data does
+// not cover.
+type Baseline struct {
+	Disruptions []DisruptionStatisticalData
+	Alerts      []AlertStatisticalData
+}
+
+// jobRunArtifacts are the summaries of a single job run.  A run can write several of each, one per invocation of
+// openshift-tests, which are added together as we do when we upload them.
+type jobRunArtifacts struct {
+	dir               string
+	clusterDataFiles  []string
+	disruptionFiles   []string
+	alertSummaryFiles []string
+}
+
+// the parts of the artifacts we read.  These mirror disruptionserializer.BackendDisruptionList and
+// alertanalyzer.AlertList, which cannot be imported from here.
+type backendDisruptionList struct {
+	BackendDisruptions map[string]*struct {
+		Name              string
+		BackendName       string
+		DisruptedDuration metav1.Duration
+	}
+}
+
+type alertList struct {
+	Alerts []struct {
+		Name      string
+		Namespace string
+		Level     string
+		Duration  metav1.Duration
+	}
+}
+
+// BuildBaseline computes the P50, P75, P95 and P99 of the disruption and alert durations of every job type found
+// under dir.  Every directory with a cluster-data*.json is a job run; its backend-disruption*.json and alerts*.json
+// are read from the same directory.
+func BuildBaseline(dir string) (*Baseline, error) {
+	runs, err := findJobRuns(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no job runs with %s*.json found under %s", clusterDataFilePrefix, dir)
+	}
+
+	disruptionSamples := map[DataKey][]float64{}
+	alertSamples := map[AlertDataKey][]float64{}
+	for _, run := range runs {
+		jobType, err := readJobType(run.clusterDataFiles)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", run.dir, err)
+		}
+
+		disruptions, err := readDisruptions(run.disruptionFiles)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", run.dir, err)
+		}
+		for backendName, seconds := range disruptions {
+			key := DataKey{BackendName: backendName, JobType: jobType}
+			disruptionSamples[key] = append(disruptionSamples[key], seconds)
+		}
+
+		alerts, err := readAlerts(run.alertSummaryFiles)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", run.dir, err)
+		}
+		for alertKey, seconds := range alerts {
+			key := alertKey
+			key.JobType = jobType
+			alertSamples[key] = append(alertSamples[key], seconds)
+		}
+	}
+	logrus.Infof("read %d job runs, %d disruption and %d alert data keys", len(runs), len(disruptionSamples), len(alertSamples))
+
+	baseline := &Baseline{}
+	for key, samples := range disruptionSamples {
+		baseline.Disruptions = append(baseline.Disruptions, DisruptionStatisticalData{
+			DataKey: key,
+			P50:     percentile(samples, 0.50),
+			P75:     percentile(samples, 0.75),
+			P95:     percentile(samples, 0.95),
+			P99:     percentile(samples, 0.99),
+			JobRuns: int64(len(samples)),
+		})
+	}
+	sort.Slice(baseline.Disruptions, func(i, j int) bool {
+		return fmt.Sprintf("%+v", baseline.Disruptions[i].DataKey) < fmt.Sprintf("%+v", baseline.Disruptions[j].DataKey)
+	})
+	for key, samples := range alertSamples {
+		baseline.Alerts = append(baseline.Alerts, AlertStatisticalData{
+			AlertDataKey: key,
+			P50:          percentile(samples, 0.50),
+			P75:          percentile(samples, 0.75),
+			P95:          percentile(samples, 0.95),
+			P99:          percentile(samples, 0.99),
+			JobRuns:      int64(len(samples)),
+		})
+	}
+	sort.Slice(baseline.Alerts, func(i, j int) bool {
+		return fmt.Sprintf("%+v", baseline.Alerts[i].AlertDataKey) < fmt.Sprintf("%+v", baseline.Alerts[j].AlertDataKey)
+	})
+	return baseline, nil
+}
+
+// DisruptionJSON returns the disruption data in the format of the upstream query_results.json, which
+// NewDisruptionMatcher reads.
+func (b *Baseline) DisruptionJSON() ([]byte, error) {
+	type encodingPercentile struct {
+		DataKey `json:",inline"`
+		P50     string
+		P75     string
+		P95     string
+		P99     string
+		JobRuns int64
+	}
+	encoded := []encodingPercentile{}
+	for _, curr := range b.Disruptions {
+		encoded = append(encoded, encodingPercentile{
+			DataKey: curr.DataKey,
+			P50:     formatSeconds(curr.P50),
+			P75:     formatSeconds(curr.P75),
+			P95:     formatSeconds(curr.P95),
+			P99:     formatSeconds(curr.P99),
+			JobRuns: curr.JobRuns,
+		})
+	}
+	return json.MarshalIndent(encoded, "", "    ")
+}
+
+// AlertJSON returns the alert data in the format of the upstream query_results.json, which NewAlertMatcher reads.
+func (b *Baseline) AlertJSON() ([]byte, error) {
+	type encodingPercentile struct {
+		AlertDataKey `json:",inline"`
+		P50          string
+		P75          string
+		P95          string
+		P99          string
+		JobRuns      int64
+	}
+	encoded := []encodingPercentile{}
+	for _, curr := range b.Alerts {
+		encoded = append(encoded, encodingPercentile{
+			AlertDataKey: curr.AlertDataKey,
+			P50:          formatSeconds(curr.P50),
+			P75:          formatSeconds(curr.P75),
+			P95:          formatSeconds(curr.P95),
+			P99:          formatSeconds(curr.P99),
+			JobRuns:      curr.JobRuns,
+		})
+	}
+	return json.MarshalIndent(encoded, "", "    ")
+}
+
+// formatSeconds rounds to milliseconds, interpolating between samples has no more precision than that.
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
+
+// percentile interpolates between the closest samples, like PERCENTILE_CONT in the BigQuery that computes the
+// upstream data.
+func percentile(samples []float64, p float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, samples...)
+	sort.Float64s(sorted)
+
+	rank := p * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+func findJobRuns(dir string) ([]*jobRunArtifacts, error) {
+	runsByDir := map[string]*jobRunArtifacts{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			return nil
+		}
+		runDir := filepath.Dir(path)
+		run, ok := runsByDir[runDir]
+		if !ok {
+			run = &jobRunArtifacts{dir: runDir}
+			runsByDir[runDir] = run
+		}
+		switch {
+		case strings.HasPrefix(name, clusterDataFilePrefix):
+			run.clusterDataFiles = append(run.clusterDataFiles, path)
+		case strings.HasPrefix(name, backendDisruptionFilePrefix):
+			run.disruptionFiles = append(run.disruptionFiles, path)
+		case strings.HasPrefix(name, alertSummaryFilePrefix):
+			run.alertSummaryFiles = append(run.alertSummaryFiles, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []*jobRunArtifacts{}
+	for _, run := range runsByDir {
+		if len(run.clusterDataFiles) == 0 {
+			if len(run.disruptionFiles) > 0 || len(run.alertSummaryFiles) > 0 {
+				logrus.Warnf("skipping %s, it has no %s*.json to identify the job type", run.dir, clusterDataFilePrefix)
+			}
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].dir < runs[j].dir
+	})
+	return runs, nil
+}
+
+func readJSON(filename string, into interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, into); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", filename, err)
+	}
+	return nil
+}
+
+func readJobType(filenames []string) (platformidentification.JobType, error) {
+	var jobType platformidentification.JobType
+	for i, filename := range filenames {
+		clusterData := platformidentification.ClusterData{}
+		if err := readJSON(filename, &clusterData); err != nil {
+			return platformidentification.JobType{}, err
+		}
+		if i == 0 {
+			jobType = clusterData.JobType
+			continue
+		}
+		if clusterData.JobType != jobType {
+			return platformidentification.JobType{}, fmt.Errorf("%s has job type %+v, expected %+v", filename, clusterData.JobType, jobType)
+		}
+	}
+	return jobType, nil
+}
+
+// readDisruptions returns the seconds of disruption of every backend, by the name the disruption tests look it up by,
+// which includes the connection type.
+func readDisruptions(filenames []string) (map[string]float64, error) {
+	disruptions := map[string]float64{}
+	for _, filename := range filenames {
+		list := backendDisruptionList{}
+		if err := readJSON(filename, &list); err != nil {
+			return nil, err
+		}
+		for name, disruption := range list.BackendDisruptions {
+			if len(disruption.Name) > 0 {
+				name = disruption.Name
+			}
+			disruptions[name] += disruption.DisruptedDuration.Seconds()
+		}
+	}
+	return disruptions, nil
+}
+
+// readAlerts returns the seconds every alert was at or above its level.  The alert summaries list the alerts we test
+// with a zero duration when they did not fire, so runs without an alert still count towards its data.
+func readAlerts(filenames []string) (map[AlertDataKey]float64, error) {
+	alerts := map[AlertDataKey]float64{}
+	for _, filename := range filenames {
+		list := alertList{}
+		if err := readJSON(filename, &list); err != nil {
+			return nil, err
+		}
+		for _, alert := range list.Alerts {
+			key := AlertDataKey{
+				AlertName:      alert.Name,
+				AlertNamespace: alert.Namespace,
+				AlertLevel:     alert.Level,
+			}
+			alerts[key] += alert.Duration.Seconds()
+		}
+	}
+	return alerts, nil
+}
@@ -0,0 +1,94 @@
+package historicaldata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+func TestPercentile(t *testing.T) {
+	samples := []float64{4, 1, 3, 2, 5}
+	for p, expected := range map[float64]float64{0: 1, 0.5: 3, 0.75: 4, 0.95: 4.8, 1: 5} {
+		if actual := percentile(samples, p); actual != expected {
+			t.Errorf("expected P%v of %v to be %v, got %v", p*100, samples, expected, actual)
+		}
+	}
+}
+
+func TestBuildBaseline(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const clusterData = `{"Release":"4.15","FromRelease":"","Platform":"openstack","Architecture":"amd64","Network":"ovn","Topology":"ha"}`
+	for i := 1; i <= 4; i++ {
+		run := fmt.Sprintf("run-%d/artifacts/junit", i)
+		writeFile(run+"/cluster-data_20240101-100000.json", clusterData)
+		writeFile(run+"/backend-disruption_20240101-100000.json", fmt.Sprintf(
+			`{"BackendDisruptions":{"kube-api-new-connections":{"Name":"kube-api-new-connections","BackendName":"kube-api","DisruptedDuration":"%ds"}}}`, i))
+		// the second invocation of openshift-tests in the run adds to the first.
+		writeFile(run+"/backend-disruption_20240101-110000.json",
+			`{"BackendDisruptions":{"kube-api-new-connections":{"Name":"kube-api-new-connections","BackendName":"kube-api","DisruptedDuration":"1s"}}}`)
+		writeFile(run+"/alerts_20240101-100000.json", fmt.Sprintf(
+			`{"Alerts":[{"Name":"KubeAPIErrorBudgetBurn","Namespace":"openshift-kube-apiserver","Level":"Critical","Duration":"%ds"}]}`, 10*i))
+	}
+	// runs without cluster data cannot be attributed to a job type.
+	writeFile("unknown/backend-disruption.json", `{"BackendDisruptions":{}}`)
+
+	baseline, err := BuildBaseline(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := baseline.DisruptionJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	disruptionMatcher, err := NewDisruptionMatcher(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobType := platformidentification.JobType{Release: "4.15", Platform: "openstack", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	disruption, ok := disruptionMatcher.HistoricalData[DataKey{BackendName: "kube-api-new-connections", JobType: jobType}]
+	if !ok {
+		t.Fatalf("expected kube-api-new-connections for %+v, got %+v", jobType, disruptionMatcher.HistoricalData)
+	}
+	if disruption.JobRuns != 4 || disruption.P95 != 4.85 || disruption.P99 != 4.97 {
+		t.Errorf("unexpected disruption data %+v", disruption)
+	}
+
+	data, err = baseline.AlertJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alertMatcher, err := NewAlertMatcher(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alert, ok := alertMatcher.HistoricalData[AlertDataKey{
+		AlertName:      "KubeAPIErrorBudgetBurn",
+		AlertNamespace: "openshift-kube-apiserver",
+		AlertLevel:     "Critical",
+		JobType:        jobType,
+	}]
+	if !ok {
+		t.Fatalf("expected KubeAPIErrorBudgetBurn for %+v, got %+v", jobType, alertMatcher.HistoricalData)
+	}
+	if alert.JobRuns != 4 || alert.P95 != 38.5 || alert.P99 != 39.7 {
+		t.Errorf("unexpected alert data %+v", alert)
+	}
+}
+
+func TestBuildBaselineWithoutJobRuns(t *testing.T) {
+	if _, err := BuildBaseline(t.TempDir()); err == nil {
+		t.Errorf("expected a directory without job runs to be an error")
+	}
+}
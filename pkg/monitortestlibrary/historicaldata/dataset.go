@@ -0,0 +1,153 @@
+package historicaldata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// embeddedSource is the Source of the data compiled into openshift-tests.
+const embeddedSource = "embedded"
+
+// httpTimeout bounds how long a mirror can delay the start of a run before we fall back to the embedded data.
+const httpTimeout = 30 * time.Second
+
+// Dataset identifies the historical data a matcher was built from, so that results can be traced back to it.
+type Dataset struct {
+	// Source is "embedded", or the file path or URL the data was loaded from.
+	Source string
+	// SHA256 is the hex encoded sha256 of the data.
+	SHA256 string
+}
+
+func (d Dataset) String() string {
+	return fmt.Sprintf("historical data from %s (sha256:%s)", d.Source, d.SHA256)
+}
+
+func newDataset(source string, data []byte) Dataset {
+	sum := sha256.Sum256(data)
+	return Dataset{
+		Source: source,
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+}
+
+// LoadDisruptionMatcher builds a DisruptionBestMatcher from the location in envVar, falling back to the embedded data.
+// See loadDataset.
+func LoadDisruptionMatcher(envVar string, embedded []byte) (*DisruptionBestMatcher, error) {
+	var matcher *DisruptionBestMatcher
+	dataset, err := loadDataset(envVar, embedded, func(data []byte) (err error) {
+		matcher, err = NewDisruptionMatcher(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	matcher.Dataset = dataset
+	return matcher, nil
+}
+
+// LoadAlertMatcher builds an AlertBestMatcher from the location in envVar, falling back to the embedded data.
+// See loadDataset.
+func LoadAlertMatcher(envVar string, embedded []byte) (*AlertBestMatcher, error) {
+	var matcher *AlertBestMatcher
+	dataset, err := loadDataset(envVar, embedded, func(data []byte) (err error) {
+		matcher, err = NewAlertMatcher(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	matcher.Dataset = dataset
+	return matcher, nil
+}
+
+// expectedSHA256EnvVarSuffix names the variable with the expected sha256 of the data in an envVar, like
+// DISRUPTION_HISTORICAL_DATA_SHA256.
+const expectedSHA256EnvVarSuffix = "_SHA256"
+
+// loadDataset parses the data at the location in envVar, a file path or an http(s) URL, so that newer data can be used
+// without rebuilding openshift-tests.  The data is verified against the hex encoded sha256 in envVar_SHA256, which
+// comes from whoever points the run at the data rather than from the mirror serving it.  When that is unset, the
+// location must have a <location>.sha256 next to it, as written by sha256sum, which only protects against truncated
+// downloads and half-synced mirrors.  If envVar is unset, or the data cannot be read, verified or parsed, the embedded
+// data is parsed instead.
+func loadDataset(envVar string, embedded []byte, parse func([]byte) error) (Dataset, error) {
+	location := os.Getenv(envVar)
+	if len(location) > 0 {
+		data, err := readVerified(location, os.Getenv(envVar+expectedSHA256EnvVarSuffix))
+		if err == nil {
+			err = parse(data)
+		}
+		if err == nil {
+			dataset := newDataset(location, data)
+			logrus.Infof("using %v", dataset)
+			return dataset, nil
+		}
+		logrus.WithError(err).Warnf("unable to use %s=%s, falling back to the embedded historical data", envVar, location)
+	}
+
+	if err := parse(embedded); err != nil {
+		return Dataset{}, err
+	}
+	return newDataset(embeddedSource, embedded), nil
+}
+
+// readVerified reads the location and checks it against expectedSHA256, or the sha256 in <location>.sha256 when it is
+// empty.
+func readVerified(location, expectedSHA256 string) ([]byte, error) {
+	data, err := read(location)
+	if err != nil {
+		return nil, err
+	}
+	expected := strings.ToLower(strings.TrimSpace(expectedSHA256))
+	if len(expected) == 0 {
+		checksum, err := read(location + ".sha256")
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the checksum: %w", err)
+		}
+		fields := strings.Fields(string(checksum))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty checksum in %s.sha256", location)
+		}
+		expected = strings.ToLower(fields[0])
+	}
+	if actual := newDataset(location, data).SHA256; actual != expected {
+		return nil, fmt.Errorf("sha256 of %s is %s, expected %s", location, actual, expected)
+	}
+	return data, nil
+}
+
+// WriteDataset writes data to filename along with the filename.sha256 that loadDataset requires.
+func WriteDataset(filename string, data []byte) error {
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return err
+	}
+	checksum := fmt.Sprintf("%s  %s\n", newDataset(filename, data).SHA256, filepath.Base(filename))
+	return os.WriteFile(filename+".sha256", []byte(checksum), 0644)
+}
+
+func read(location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+
+	client := &http.Client{Timeout: httpTimeout}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, location)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,134 @@
+package historicaldata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	embeddedData = `[{"BackendName":"kube-api-new-connections","Release":"4.14","Platform":"aws","P95":"1.0","P99":"2.0","JobRuns":200}]`
+	newerData    = `[{"BackendName":"kube-api-new-connections","Release":"4.15","Platform":"aws","P95":"3.0","P99":"4.0","JobRuns":200}]`
+)
+
+func checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:]) + "  query_results.json\n"
+}
+
+func TestLoadDisruptionMatcher(t *testing.T) {
+	const envVar = "TEST_DISRUPTION_HISTORICAL_DATA"
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	verified := writeFile("verified.json", newerData)
+	writeFile("verified.json.sha256", checksum(newerData))
+	unverified := writeFile("unverified.json", newerData)
+	tampered := writeFile("tampered.json", newerData)
+	writeFile("tampered.json.sha256", checksum(embeddedData))
+	invalid := writeFile("invalid.json", "{")
+	writeFile("invalid.json.sha256", checksum("{"))
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		location       string
+		expectedSHA256 string
+		expectedSource string
+	}{
+		{
+			name:           "unset",
+			expectedSource: "embedded",
+		},
+		{
+			name:           "verified file",
+			location:       verified,
+			expectedSource: verified,
+		},
+		{
+			name:           "verified URL",
+			location:       server.URL + "/verified.json",
+			expectedSource: server.URL + "/verified.json",
+		},
+		{
+			name:           "missing checksum",
+			location:       unverified,
+			expectedSource: "embedded",
+		},
+		{
+			name:           "checksum mismatch",
+			location:       tampered,
+			expectedSource: "embedded",
+		},
+		{
+			name:           "invalid data",
+			location:       invalid,
+			expectedSource: "embedded",
+		},
+		{
+			name:           "expected sha256",
+			location:       unverified,
+			expectedSHA256: checksum(newerData)[:64],
+			expectedSource: unverified,
+		},
+		{
+			name:           "expected sha256 overrides the mirror checksum",
+			location:       server.URL + "/tampered.json",
+			expectedSHA256: checksum(newerData)[:64],
+			expectedSource: server.URL + "/tampered.json",
+		},
+		{
+			name:           "expected sha256 mismatch",
+			location:       server.URL + "/verified.json",
+			expectedSHA256: checksum(embeddedData)[:64],
+			expectedSource: "embedded",
+		},
+		{
+			name:           "missing URL",
+			location:       server.URL + "/missing.json",
+			expectedSource: "embedded",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(envVar, test.location)
+			t.Setenv(envVar+"_SHA256", test.expectedSHA256)
+			matcher, err := LoadDisruptionMatcher(envVar, []byte(embeddedData))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matcher.Dataset.Source != test.expectedSource {
+				t.Errorf("expected data from %s, got %v", test.expectedSource, matcher.Dataset)
+			}
+
+			expectedData := embeddedData
+			if test.expectedSource != "embedded" {
+				expectedData = newerData
+			}
+			if expected := checksum(expectedData)[:64]; matcher.Dataset.SHA256 != expected {
+				t.Errorf("expected sha256 %s, got %s", expected, matcher.Dataset.SHA256)
+			}
+			if len(matcher.HistoricalData) != 1 {
+				t.Errorf("expected one entry, got %v", matcher.HistoricalData)
+			}
+		})
+	}
+}
+
+func TestLoadDisruptionMatcherInvalidEmbeddedData(t *testing.T) {
+	if _, err := LoadDisruptionMatcher("TEST_DISRUPTION_HISTORICAL_DATA", []byte("{")); err == nil {
+		t.Errorf("expected invalid embedded data to be an error")
+	}
+}
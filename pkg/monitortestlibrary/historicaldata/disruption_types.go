@@ -27,6 +27,23 @@ type StatisticalDuration struct {
 	FirstObserved                  time.Time
 	LastObserved                   time.Time
 	JobRuns                        int64
+	// Fallback is the fallback the data came from, empty for an exact match.
+	Fallback string
+	// Confidence is how well the data predicts the job type, see LowConfidenceThreshold.
+	Confidence float64
+}
+
+// LowConfidence returns true if the data is not trusted to fail a test.
+func (d StatisticalDuration) LowConfidence() bool {
+	return d.Confidence < LowConfidenceThreshold
+}
+
+// DescribeMatch describes where the data came from, for the output of tests.
+func (d StatisticalDuration) DescribeMatch() string {
+	if len(d.Fallback) == 0 {
+		return fmt.Sprintf("exact match with confidence %.2f", d.Confidence)
+	}
+	return fmt.Sprintf("fell back to %s with confidence %.2f", d.Fallback, d.Confidence)
 }
 
 type DisruptionStatisticalData struct {
@@ -48,6 +65,8 @@ type DataKey struct {
 
 type DisruptionBestMatcher struct {
 	HistoricalData map[DataKey]DisruptionStatisticalData
+	// Dataset is the data HistoricalData was loaded from.
+	Dataset Dataset
 }
 
 func NewDisruptionMatcher(historicalJSON []byte) (*DisruptionBestMatcher, error) {
@@ -88,6 +107,7 @@ func NewDisruptionMatcher(historicalJSON []byte) (*DisruptionBestMatcher, error)
 
 	return &DisruptionBestMatcher{
 		HistoricalData: historicalData,
+		Dataset:        newDataset(embeddedSource, historicalJSON),
 	}, nil
 }
 
@@ -97,36 +117,27 @@ func NewDisruptionMatcherWithHistoricalData(data map[DataKey]DisruptionStatistic
 	}
 }
 
-func (b *DisruptionBestMatcher) bestMatch(name string, jobType platformidentification.JobType, minJobRuns int) (DisruptionStatisticalData, string, error) {
+func (b *DisruptionBestMatcher) bestMatch(name string, jobType platformidentification.JobType, minJobRuns int) (DisruptionStatisticalData, Match, string, error) {
 	exactMatchKey := DataKey{
 		BackendName: name,
 		JobType:     jobType,
 	}
 	logrus.WithField("backend", name).Infof("searching for bestMatch for %+v", jobType)
 	logrus.Infof("historicalData has %d entries", len(b.HistoricalData))
-	if percentiles, ok := b.HistoricalData[exactMatchKey]; ok {
-		if percentiles.JobRuns >= int64(minJobRuns) {
-			logrus.Infof("found exact match: %+v", percentiles)
-			return percentiles, "", nil
-		}
 
-		percentiles, matchReason, err := b.evaluateBestGuesser(name, PreviousReleaseUpgrade, exactMatchKey, jobType)
-		if err != nil {
-			return DisruptionStatisticalData{}, "", err
-		}
-		if percentiles != (DisruptionStatisticalData{}) {
-			return percentiles, matchReason, nil
-		}
-	}
-	// tested in TestGetClosestP99Value in allowedbackendisruption.  Should get a local test at some point.
-	for _, nextBestGuesser := range nextBestGuessers {
-		percentiles, matchReason, err := b.evaluateBestGuesser(name, nextBestGuesser, exactMatchKey, jobType)
-		if err != nil {
-			return DisruptionStatisticalData{}, "", err
-		}
-		if percentiles != (DisruptionStatisticalData{}) {
-			return percentiles, matchReason, nil
+	// tested in TestGetClosestP99Value in allowedbackendisruption.
+	match, ok := findMatch(jobType, minJobRuns, func(candidate platformidentification.JobType) int64 {
+		return b.HistoricalData[DataKey{BackendName: name, JobType: candidate}].JobRuns
+	})
+	if ok {
+		percentiles := b.blend(exactMatchKey, match)
+		if len(match.Fallback) == 0 {
+			logrus.Infof("found exact match: %+v", percentiles)
+		} else {
+			logrus.Infof("no exact match fell back to %s %#v", match.Fallback, match.JobTypes)
+			logrus.Infof("found inexact match: %+v", percentiles)
 		}
+		return percentiles, match, describeMatch(exactMatchKey, match), nil
 	}
 
 	logrus.Warn("no exact or fuzzy match, no results will be returned, test will be skipped")
@@ -138,41 +149,60 @@ func (b *DisruptionBestMatcher) bestMatch(name string, jobType platformidentific
 	// determination. If we did not record historical data for this NURP combination, we do not wish to enforce
 	// disruption testing on a per job basis. Return an empty data result to signal we have no data, and skip the test.
 	return DisruptionStatisticalData{},
+		Match{},
 		fmt.Sprintf("(no exact or fuzzy match for jobType=%#v)", jobType),
 		nil
 }
 
-func (b *DisruptionBestMatcher) evaluateBestGuesser(name string, nextBestGuesser NextBestKey, exactMatchKey DataKey, jobType platformidentification.JobType) (DisruptionStatisticalData, string, error) {
-	nextBestJobType, ok := nextBestGuesser(jobType)
-	if !ok {
-		return DisruptionStatisticalData{}, "", nil
+// blend returns the data of the match, averaged by job runs if it has more than one job type.
+func (b *DisruptionBestMatcher) blend(exactMatchKey DataKey, match Match) DisruptionStatisticalData {
+	if len(match.JobTypes) == 1 {
+		return b.HistoricalData[DataKey{BackendName: exactMatchKey.BackendName, JobType: match.JobTypes[0]}]
 	}
-	nextBestMatchKey := DataKey{
-		BackendName: name,
-		JobType:     nextBestJobType,
+
+	ret := DisruptionStatisticalData{DataKey: exactMatchKey}
+	for _, jobType := range match.JobTypes {
+		ret.JobRuns += b.HistoricalData[DataKey{BackendName: exactMatchKey.BackendName, JobType: jobType}].JobRuns
 	}
-	if percentiles, ok := b.HistoricalData[nextBestMatchKey]; ok && percentiles.JobRuns > defaultMinJobRuns {
-		logrus.Infof("no exact match fell back to %#v", nextBestMatchKey)
-		logrus.Infof("found inexact match: %+v", percentiles)
-		return percentiles, fmt.Sprintf("(no exact match for %#v, fell back to %#v)", exactMatchKey, nextBestMatchKey), nil
+	for _, jobType := range match.JobTypes {
+		curr := b.HistoricalData[DataKey{BackendName: exactMatchKey.BackendName, JobType: jobType}]
+		weight := blendWeight(curr.JobRuns, ret.JobRuns)
+		ret.P50 += weight * curr.P50
+		ret.P75 += weight * curr.P75
+		ret.P95 += weight * curr.P95
+		ret.P99 += weight * curr.P99
+		if ret.FirstObserved.IsZero() || curr.FirstObserved.Before(ret.FirstObserved) {
+			ret.FirstObserved = curr.FirstObserved
+		}
+		if curr.LastObserved.After(ret.LastObserved) {
+			ret.LastObserved = curr.LastObserved
+		}
 	}
-	return DisruptionStatisticalData{}, "", nil
+	return ret
 }
 
 // BestMatchDuration returns the best possible match for this historical data.  It attempts an exact match first, then
 // it attempts to match on the most important keys in order, before giving up and returning an empty default,
 // which means to skip testing against this data.
 func (b *DisruptionBestMatcher) BestMatchDuration(name string, jobType platformidentification.JobType, minJobRuns int) (StatisticalDuration, string, error) {
-	rawData, details, err := b.bestMatch(name, jobType, minJobRuns)
+	rawData, match, details, err := b.bestMatch(name, jobType, minJobRuns)
 	// Empty data implies we have none, and thus do not want to run the test.
 	if rawData == (DisruptionStatisticalData{}) {
 		return StatisticalDuration{}, details, err
 	}
-	return toStatisticalDuration(rawData), details, err
+	ret := toStatisticalDuration(rawData)
+	ret.Fallback = match.Fallback
+	ret.Confidence = match.Confidence
+	return ret, details, err
+}
+
+// BestMatch returns the best possible match with the number of job runs required to trust a P99.
+func (b *DisruptionBestMatcher) BestMatch(name string, jobType platformidentification.JobType) (StatisticalDuration, string, error) {
+	return b.BestMatchDuration(name, jobType, defaultMinJobRuns)
 }
 
 func (b *DisruptionBestMatcher) BestMatchP99(name string, jobType platformidentification.JobType) (*time.Duration, string, error) {
-	rawData, details, err := b.BestMatchDuration(name, jobType, defaultMinJobRuns)
+	rawData, details, err := b.BestMatch(name, jobType)
 	if rawData == (StatisticalDuration{}) {
 		return nil, details, err
 	}
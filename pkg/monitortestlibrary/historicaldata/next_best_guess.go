@@ -9,12 +9,53 @@ import (
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 )
 
-// nextBestGuessers is the order in which to attempt to lookup other alternative matches that are close to this job type.
-var nextBestGuessers = []NextBestKey{
-	// The only guesser we try not is falling back to previous release. Otherwise if we don't have enough data, we don't
-	// run the test. This was implemented after finding that we fail every attempt at a fallback.
-	// Continuing with previous release helps us in the transition between major releases, so we kept this fallback.
-	PreviousReleaseUpgrade,
+const (
+	// ExactMatchConfidence is the confidence of data for the job type itself.
+	ExactMatchConfidence = 1.0
+
+	// LowConfidenceThreshold is the confidence below which data is not trusted to fail a test.  Tests that are
+	// over the allowance of low confidence data flake instead.
+	LowConfidenceThreshold = 0.75
+)
+
+// Fallback finds job types whose data can stand in for a job type that does not have enough runs of its own.
+type Fallback struct {
+	Name string
+	// Confidence is how well the data of the candidates predicts the job type, from 0 to 1.
+	Confidence float64
+	// Candidates returns the job types to try, in order.
+	Candidates func(in platformidentification.JobType) []platformidentification.JobType
+	// Blend combines the data of every candidate with runs, weighted by their runs, instead of using the first
+	// candidate with enough runs on its own.
+	Blend bool
+}
+
+// fallbacks is the order in which to attempt to lookup other alternative matches that are close to this job type.
+// Falling back to previous release helps us in the transition between major releases and is trusted as much as an
+// exact match.  We used to skip the test rather than try anything else, after finding that we fail every attempt at
+// a fallback, so the other fallbacks are low confidence and can only flake.
+var fallbacks = []Fallback{
+	{
+		Name:       "previous release",
+		Confidence: 0.9,
+		Candidates: single(PreviousReleaseUpgrade),
+	},
+	{
+		Name:       "same platform other network",
+		Confidence: 0.6,
+		Candidates: OtherNetworks,
+	},
+	{
+		Name:       "same platform and topology other architecture",
+		Confidence: 0.5,
+		Candidates: OtherArchitectures,
+	},
+	{
+		Name:       "blend of neighbours",
+		Confidence: 0.4,
+		Candidates: Neighbours,
+		Blend:      true,
+	},
 }
 
 // NextBestKey returns the next best key in the query_results.json generated from BigQuery and a bool indicating whether this guesser has an opinion.
@@ -37,6 +78,125 @@ func PreviousReleaseUpgrade(in platformidentification.JobType) (platformidentifi
 	return ret, true
 }
 
+var (
+	knownNetworks      = []string{"ovn", "sdn"}
+	knownArchitectures = []string{
+		platformidentification.ArchitectureAMD64,
+		platformidentification.ArchitectureARM64,
+		platformidentification.ArchitecturePPC64le,
+		platformidentification.ArchitectureS390,
+	}
+)
+
+// OtherNetworks returns the job type on the other networks of the same platform.
+func OtherNetworks(in platformidentification.JobType) []platformidentification.JobType {
+	ret := []platformidentification.JobType{}
+	for _, network := range knownNetworks {
+		if network == in.Network {
+			continue
+		}
+		candidate := platformidentification.CloneJobType(in)
+		candidate.Network = network
+		ret = append(ret, candidate)
+	}
+	return ret
+}
+
+// OtherArchitectures returns the job type on the other architectures of the same platform and topology.
+func OtherArchitectures(in platformidentification.JobType) []platformidentification.JobType {
+	ret := []platformidentification.JobType{}
+	for _, architecture := range knownArchitectures {
+		if architecture == in.Architecture {
+			continue
+		}
+		candidate := platformidentification.CloneJobType(in)
+		candidate.Architecture = architecture
+		ret = append(ret, candidate)
+	}
+	return ret
+}
+
+// Neighbours returns the job type itself and every job type the other fallbacks try, so that job types that do not
+// have enough runs on their own can be combined.
+func Neighbours(in platformidentification.JobType) []platformidentification.JobType {
+	ret := []platformidentification.JobType{in}
+	if previous, ok := PreviousReleaseUpgrade(in); ok {
+		ret = append(ret, previous)
+	}
+	ret = append(ret, OtherNetworks(in)...)
+	ret = append(ret, OtherArchitectures(in)...)
+	return ret
+}
+
+func single(nextBestGuesser NextBestKey) func(in platformidentification.JobType) []platformidentification.JobType {
+	return func(in platformidentification.JobType) []platformidentification.JobType {
+		if candidate, ok := nextBestGuesser(in); ok {
+			return []platformidentification.JobType{candidate}
+		}
+		return nil
+	}
+}
+
+// Match describes where the data for a job type came from.
+type Match struct {
+	// Fallback is the name of the fallback that was used, empty for an exact match.
+	Fallback   string
+	Confidence float64
+	// JobTypes are the job types whose data was used.
+	JobTypes []platformidentification.JobType
+}
+
+// findMatch returns the job types to use the data of for a job type.  jobRuns returns the number of runs a job type
+// has data for, zero if it has none.
+func findMatch(jobType platformidentification.JobType, minJobRuns int, jobRuns func(platformidentification.JobType) int64) (Match, bool) {
+	if jobRuns(jobType) >= int64(minJobRuns) {
+		return Match{
+			Confidence: ExactMatchConfidence,
+			JobTypes:   []platformidentification.JobType{jobType},
+		}, true
+	}
+
+	for _, fallback := range fallbacks {
+		match := Match{
+			Fallback:   fallback.Name,
+			Confidence: fallback.Confidence,
+		}
+		var totalRuns int64
+		for _, candidate := range fallback.Candidates(jobType) {
+			runs := jobRuns(candidate)
+			if !fallback.Blend {
+				if runs >= int64(minJobRuns) {
+					match.JobTypes = []platformidentification.JobType{candidate}
+					return match, true
+				}
+				continue
+			}
+			if runs > 0 {
+				totalRuns += runs
+				match.JobTypes = append(match.JobTypes, candidate)
+			}
+		}
+		if fallback.Blend && len(match.JobTypes) > 1 && totalRuns >= int64(minJobRuns) {
+			return match, true
+		}
+	}
+	return Match{}, false
+}
+
+// describeMatch is the detail reported for data that was not an exact match.
+func describeMatch(exactMatchKey interface{}, match Match) string {
+	if len(match.Fallback) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(no exact match for %#v, fell back to %s with confidence %.2f: %#v)",
+		exactMatchKey, match.Fallback, match.Confidence, match.JobTypes)
+}
+
+// blendWeight is the share of the runs of a blend that one job type has.
+func blendWeight(jobRuns, totalRuns int64) float64 {
+	return float64(jobRuns) / float64(totalRuns)
+}
+
 func getMajor(in string) int {
 	major, err := strconv.ParseInt(strings.Split(in, ".")[0], 10, 32)
 	if err != nil {
@@ -1,6 +1,10 @@
 package historicaldata
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
 
 func TestCurrentReleaseFromMap(t *testing.T) {
 	// Test case: Empty input map
@@ -42,3 +46,107 @@ func TestCompareReleaseString(t *testing.T) {
 		t.Errorf("Expected true, but got false")
 	}
 }
+
+func TestFallbacks(t *testing.T) {
+	jobType := platformidentification.JobType{
+		Release:      "4.15",
+		FromRelease:  "4.15",
+		Platform:     "aws",
+		Architecture: "amd64",
+		Network:      "ovn",
+		Topology:     "ha",
+	}
+	withChanges := func(change func(*platformidentification.JobType)) platformidentification.JobType {
+		ret := platformidentification.CloneJobType(jobType)
+		change(&ret)
+		return ret
+	}
+	previousRelease := withChanges(func(j *platformidentification.JobType) { j.Release, j.FromRelease = "4.14", "4.14" })
+	sdn := withChanges(func(j *platformidentification.JobType) { j.Network = "sdn" })
+	arm64 := withChanges(func(j *platformidentification.JobType) { j.Architecture = "arm64" })
+
+	data := func(jobType platformidentification.JobType, p99 float64, jobRuns int64) DisruptionStatisticalData {
+		return DisruptionStatisticalData{
+			DataKey: DataKey{BackendName: "kube-api-new-connections", JobType: jobType},
+			P95:     p99 / 2,
+			P99:     p99,
+			JobRuns: jobRuns,
+		}
+	}
+
+	tests := []struct {
+		name               string
+		data               []DisruptionStatisticalData
+		expectedP99        float64
+		expectedFallback   string
+		expectedConfidence float64
+	}{
+		{
+			name:               "exact",
+			data:               []DisruptionStatisticalData{data(jobType, 1, 100), data(previousRelease, 2, 100)},
+			expectedP99:        1,
+			expectedConfidence: ExactMatchConfidence,
+		},
+		{
+			name:               "previous release",
+			data:               []DisruptionStatisticalData{data(jobType, 1, 10), data(previousRelease, 2, 100), data(sdn, 3, 100)},
+			expectedP99:        2,
+			expectedFallback:   "previous release",
+			expectedConfidence: 0.9,
+		},
+		{
+			name:               "other network",
+			data:               []DisruptionStatisticalData{data(sdn, 3, 100), data(arm64, 4, 100)},
+			expectedP99:        3,
+			expectedFallback:   "same platform other network",
+			expectedConfidence: 0.6,
+		},
+		{
+			name:               "other architecture",
+			data:               []DisruptionStatisticalData{data(arm64, 4, 100)},
+			expectedP99:        4,
+			expectedFallback:   "same platform and topology other architecture",
+			expectedConfidence: 0.5,
+		},
+		{
+			name:               "blend",
+			data:               []DisruptionStatisticalData{data(jobType, 1, 60), data(sdn, 6, 40)},
+			expectedP99:        3,
+			expectedFallback:   "blend of neighbours",
+			expectedConfidence: 0.4,
+		},
+		{
+			name: "not enough runs to blend",
+			data: []DisruptionStatisticalData{data(jobType, 1, 30), data(sdn, 6, 40)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			historicalData := map[DataKey]DisruptionStatisticalData{}
+			for _, d := range test.data {
+				historicalData[d.DataKey] = d
+			}
+			matcher := NewDisruptionMatcherWithHistoricalData(historicalData)
+
+			actual, details, err := matcher.BestMatch("kube-api-new-connections", jobType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.expectedP99 == 0 {
+				if actual != (StatisticalDuration{}) {
+					t.Errorf("expected no match, got %+v", actual)
+				}
+				return
+			}
+			if actual.P99 != DurationOrDie(test.expectedP99) {
+				t.Errorf("expected P99 %v, got %v %s", test.expectedP99, actual.P99, details)
+			}
+			if actual.Fallback != test.expectedFallback || actual.Confidence != test.expectedConfidence {
+				t.Errorf("expected %q with confidence %v, got %s", test.expectedFallback, test.expectedConfidence, actual.DescribeMatch())
+			}
+			if actual.LowConfidence() != (test.expectedConfidence < LowConfidenceThreshold) {
+				t.Errorf("unexpected LowConfidence for %s", actual.DescribeMatch())
+			}
+		})
+	}
+}
@@ -65,6 +65,11 @@ type SimplePathologicalEventMatcher struct {
 	// This is only considered in the context of Allows, not Matches.
 	repeatThresholdOverride int
 
+	// allowedEventsPerMinute limits the rate of the event storms this matcher allows. Zero allows the storms of any
+	// event the matcher allows.
+	// This is only considered in the context of AllowsRate.
+	allowedEventsPerMinute float64
+
 	// neverAllow is for matchers we may use to get things flagged as "interesting" and thus charted, but
 	// we don't want to allow to repeat pathologically.
 	neverAllow bool
@@ -72,6 +77,13 @@ type SimplePathologicalEventMatcher struct {
 	// topology limits the exception to a specific topology. (e.g. single replica)
 	// This is only considered in the context of Allows, not Matches.
 	topology *v1.TopologyMode
+
+	// owner is the jira component that owns the exception.
+	owner string
+
+	// expires is when the exception should have been removed. Expired exceptions keep allowing events, but fail
+	// a dedicated test so the owner notices.
+	expires *time.Time
 }
 
 func (ade *SimplePathologicalEventMatcher) Name() string {
@@ -128,6 +140,26 @@ func (ade *SimplePathologicalEventMatcher) Allows(i monitorapi.Interval, topolog
 	return true
 }
 
+// AllowsRate checks if the event storm interval is allowed, and if its events may repeat at the given rate.
+func (ade *SimplePathologicalEventMatcher) AllowsRate(i monitorapi.Interval, eventsPerMinute float64, topology v1.TopologyMode) bool {
+	if !ade.Allows(i, topology) {
+		return false
+	}
+	if ade.allowedEventsPerMinute != 0 && eventsPerMinute > ade.allowedEventsPerMinute {
+		logrus.WithField("allower", ade.Name()).Debugf("event rate %.1f over allowed rate: %.1f", eventsPerMinute, ade.allowedEventsPerMinute)
+		return false
+	}
+	return true
+}
+
+// RateEventMatcher is an EventMatcher that can limit how fast the events it allows may repeat.
+type RateEventMatcher interface {
+	EventMatcher
+
+	// AllowsRate returns true if the given event storm interval should be allowed to repeat at eventsPerMinute.
+	AllowsRate(i monitorapi.Interval, eventsPerMinute float64, topology v1.TopologyMode) bool
+}
+
 type AllowedPathologicalEventRegistry struct {
 	matchers map[string]EventMatcher
 }
@@ -184,6 +216,45 @@ func (r *AllowedPathologicalEventRegistry) AllowedByAny(
 	return false, nil
 }
 
+// ExpiredMatchers returns the matchers that expired before now, sorted by name.
+func (r *AllowedPathologicalEventRegistry) ExpiredMatchers(now time.Time) []*SimplePathologicalEventMatcher {
+	ret := []*SimplePathologicalEventMatcher{}
+	for _, m := range r.matchers {
+		simpleMatcher, ok := m.(*SimplePathologicalEventMatcher)
+		if !ok || simpleMatcher.expires == nil {
+			continue
+		}
+		if simpleMatcher.expires.Before(now) {
+			ret = append(ret, simpleMatcher)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name() < ret[j].Name()
+	})
+	return ret
+}
+
+// AllowedStormByAny checks if any matcher allows the event storm. Matchers that cannot limit a rate allow the storms of
+// the events they allow.
+func (r *AllowedPathologicalEventRegistry) AllowedStormByAny(
+	i monitorapi.Interval,
+	eventsPerMinute float64,
+	topology v1.TopologyMode) (bool, EventMatcher) {
+	for k, m := range r.matchers {
+		var allowed bool
+		if rateMatcher, ok := m.(RateEventMatcher); ok {
+			allowed = rateMatcher.AllowsRate(i, eventsPerMinute, topology)
+		} else {
+			allowed = m.Allows(i, topology)
+		}
+		if allowed {
+			logrus.WithField("message", i.Message).WithField("locator", i.Locator).Infof("event storm allowed by %s", k)
+			return allowed, m
+		}
+	}
+	return false, nil
+}
+
 func (r *AllowedPathologicalEventRegistry) GetMatcherByName(name string) (EventMatcher, error) {
 
 	matcher, ok := r.matchers[name]
@@ -198,252 +269,8 @@ func (r *AllowedPathologicalEventRegistry) GetMatcherByName(name string) (EventM
 func NewUniversalPathologicalEventMatchers(kubeConfig *rest.Config, finalIntervals monitorapi.Intervals) *AllowedPathologicalEventRegistry {
 	registry := &AllowedPathologicalEventRegistry{matchers: map[string]EventMatcher{}}
 
-	// [sig-apps] StatefulSet Basic StatefulSet functionality [StatefulSetBasic] should not deadlock when a pod's predecessor fails [Suite:openshift/conformance/parallel] [Suite:k8s]
-	// PauseNewPods intentionally causes readiness probe to fail.
-	// [sig-apps] StatefulSet Basic StatefulSet functionality [StatefulSetBasic] should perform rolling updates and roll backs of template modifications [Conformance] [Suite:openshift/conformance/parallel/minimal] [Suite:k8s]
-	// breakPodHTTPProbe intentionally causes readiness probe to fail.
-	/*
-
-					This is duplicated with KubeletUnhealthyReadinessProbeFailed, I am keeping
-					commented out as a historical artifact in case the blanked Unhealthy readiness probe
-					matcher is removed some day and this specific case starts firing again.
-
-		registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-			name: "E2EStatefulSetReadinessProbeFailed",
-			locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-				monitorapi.LocatorNamespaceKey: regexp.MustCompile(`e2e-statefulset-[0-9]+`),
-				monitorapi.LocatorPodKey:       regexp.MustCompile(`ss2-[0-9]`),
-				monitorapi.LocatorNodeKey:      regexp.MustCompile(`[a-z0-9.-]+`),
-			},
-			messageReasonRegex: regexp.MustCompile(`^Unhealthy$`),
-			messageHumanRegex:  regexp.MustCompile(`Readiness probe failed: `),
-		})
-	*/
-
-	// Kubectl Port forwarding ***
-	// The same pod name is used many times for all these tests with a tight readiness check to make the tests fast.
-	// This results in hundreds of events while the pod isn't ready.
-	/*
-
-		This is duplicated with KubeletUnhealthyReadinessProbeFailed, I am keeping
-		commented out as a historical artifact in case the blanked Unhealthy readiness probe
-		matcher is removed some day and this specific case starts firing again.
-
-		registry.AddPathologicalEventMatcherOrDie("UnhealthyE2EPortForwarding", &SimplePathologicalEventMatcher{
-			locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-				monitorapi.LocatorNamespaceKey: regexp.MustCompile(`e2e-port-forwarding-[0-9]+`),
-				monitorapi.LocatorPodKey:       regexp.MustCompile(`^pfpod$`),
-			},
-			messageReasonRegex: regexp.MustCompile(`^Unhealthy$`),
-			messageHumanRegex:  regexp.MustCompile(`Readiness probe failed: `),
-		})
-
-	*/
-
-	/*
-
-		Historical artifact, covered by KubeletUnhealthyReadinessProbeFailed
-
-		// [sig-node] Probing container ***
-		// these tests intentionally cause repeated probe failures to ensure good handling
-		registry.AddPathologicalEventMatcherOrDie("E2EContainerProbeFailedOrWarning", &SimplePathologicalEventMatcher{
-			locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-				monitorapi.LocatorNamespaceKey: regexp.MustCompile(`e2e-container-probe-[0-9]+`),
-			},
-			messageHumanRegex: regexp.MustCompile(`probe (failed|warning):`),
-		})
-	*/
-
-	/*
-
-		Historical artifact, covered by FailedScheduling
-
-		// TestAllowedSCCViaRBAC and TestPodUpdateSCCEnforcement
-		// The pod is shaped to intentionally not be scheduled.  Looks like an artifact of the old integration testing.
-		registry.AddPathologicalEventMatcherOrDie("E2ESCCFailedScheduling", &SimplePathologicalEventMatcher{
-			locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-				monitorapi.LocatorNamespaceKey: regexp.MustCompile(`e2e-test-scc-[a-z0-9]+`),
-			},
-			messageReasonRegex: regexp.MustCompile(`FailedScheduling`),
-		})
-	*/
-
-	// Security Context ** should not run with an explicit root user ID
-	// Security Context ** should not run without a specified user ID
-	// This container should never run
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "E2ESecurityContextBreaksNonRootPolicy",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`e2e-security-context-test-[0-9]+`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`.*-root-uid`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^Failed$`),
-		messageHumanRegex:  regexp.MustCompile(`Error: container's runAsUser breaks non-root policy.*`),
-	})
-
-	// PersistentVolumes-local tests should not run the pod when there is a volume node
-	// affinity and node selector conflicts.
-	/*
-
-		Blanked allowed later by FailedScheduling matcher. Keeping for historical artifact.
-
-		registry.AddPathologicalEventMatcherOrDie("E2EPersistentVolumesFailedScheduling", &SimplePathologicalEventMatcher{
-			locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-				monitorapi.LocatorNamespaceKey: regexp.MustCompile(`e2e-persistent-local-volumes-test-[0-9]+`),
-				monitorapi.LocatorPodKey:       regexp.MustCompile(`pod-[a-z0-9.-]+`),
-			},
-			messageReasonRegex: regexp.MustCompile(`^FailedScheduling$`),
-		})
-	*/
-
-	// various DeploymentConfig tests trigger this by cancelling multiple rollouts
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:               "DeploymentAwaitingCancellation",
-		messageReasonRegex: regexp.MustCompile(`^DeploymentAwaitingCancellation$`),
-		messageHumanRegex:  regexp.MustCompile(`Deployment of version [0-9]+ awaiting cancellation of older running deployments`),
-	})
-
-	// If image pulls in e2e namespaces fail catastrophically we'd expect them to lead to test failures
-	// We are deliberately not ignoring image pull failures for core component namespaces
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "E2EImagePullBackOff",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`^e2e-.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^BackOff$`),
-		messageHumanRegex:  regexp.MustCompile(`Back-off pulling image`),
-	})
-
-	// Several allowances were related to Loki, I think we can generally ignore any repeating event
-	// from the Loki NS, this should not fail tests.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "E2ELoki",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`^openshift-e2e-loki$`),
-		},
-	})
-
-	// kube apiserver, controller-manager and scheduler guard pod probes can fail due to operands getting rolled out
-	// multiple times during the bootstrapping phase of a cluster installation
-
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "KubeAPIReadinessProbeError",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`openshift-kube-*`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`kube.*guard.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^ProbeError$`),
-		messageHumanRegex:  regexp.MustCompile(`Readiness probe error`),
-	})
-
-	// this is the less specific even sent by the kubelet when a probe was executed successfully but returned false
-	// we ignore this event because openshift has a patch in patch_prober that sends a more specific event about
-	// readiness failures in openshift-* namespaces.  We will catch the more specific ProbeError events.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:               "KubeletUnhealthyReadinessProbeFailed",
-		messageReasonRegex: regexp.MustCompile(`^Unhealthy$`),
-		messageHumanRegex:  regexp.MustCompile(`Readiness probe failed`),
-	})
-
-	/*
-
-			This looks duplicated with AllowBackOffRestartingFailedContainer
-		Kept for historical purposes
-
-		// should not start app containers if init containers fail on a RestartAlways pod
-		// the init container intentionally fails to start
-		registry.AddPathologicalEventMatcherOrDie("E2EInitContainerRestartBackoff", &SimplePathologicalEventMatcher{
-			locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-				monitorapi.LocatorNamespaceKey: regexp.MustCompile(`e2e-init-container-[0-9]+`),
-				monitorapi.LocatorPodKey:       regexp.MustCompile(`pod-init-[a-z0-9.-]+`),
-			},
-			messageReasonRegex: regexp.MustCompile(`^BackOff$`),
-			messageHumanRegex:  regexp.MustCompile(`Back-off restarting failed container`),
-		})
-	*/
-
-	// If you see this error, it means enough was working to get this event which implies enough retries happened to allow initial openshift
-	// installation to succeed. Hence, we can ignore it.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:               "AWSFailedCreateInsufficientInstanceCapacity",
-		messageReasonRegex: regexp.MustCompile(`^FailedCreate$`),
-		messageHumanRegex:  regexp.MustCompile(`error creating EC2 instance: InsufficientInstanceCapacity: We currently do not have sufficient .* capacity in the Availability Zone you requested`),
-	})
-
-	// This was originally filed as a bug in 2021, closed as fixed, but the events continue repeating in 2023.
-	// They only occur in the namespace for a specific horizontal pod autoscaling test. Ignoring permanently,
-	// as they have been for the past two years.
-	// https://bugzilla.redhat.com/show_bug.cgi?id=1993985
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "PodAutoscalerFailedToGetCPUUtilization",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`horizontalpodautoscaler`),
-		},
-		messageHumanRegex: regexp.MustCompile(`failed to get cpu utilization: unable to get metrics for resource cpu: no metrics returned from resource metrics API`),
-	})
-
-	// Formerly bug: https://bugzilla.redhat.com/show_bug.cgi?id=2075204
-	// Left stale and closed automatically. Assuming we can live with it now.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "EtcdReadinessProbeError",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`openshift-etcd`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`etcd-guard.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^ProbeError$`),
-		messageHumanRegex:  regexp.MustCompile(`Readiness probe error: .* connect: connection refused`),
-	})
-
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "OpenShiftAPICheckFailed",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(``),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(``),
-		},
-		messageReasonRegex: regexp.MustCompile(`^OpenShiftAPICheckFailed$`),
-		messageHumanRegex:  regexp.MustCompile(`user.openshift.io.v1.*503`),
-		// TODO: Jira long closed as stale, and this problem occurs well outside single node now.
-		// A new bug should probably be filed.
-		jira: "https://bugzilla.redhat.com/show_bug.cgi?id=2017435",
-	})
-
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:              "MessageChangedFromFEFF",
-		messageHumanRegex: regexp.MustCompile(`message changed from "\\ufeff`),
-	})
-
-	// This was originally intended to be limited to only during the openshift/build test suite, however it was
-	// never hooked up and was just ignored everywhere. We do not have the capability to detect if
-	// events were within specific test suites yet. Leaving them as an always allow for now.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "ScalingReplicaSet",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey:  regexp.MustCompile(`(openshift-controller-manager|openshift-route-controller-manager)`),
-			monitorapi.LocatorDeploymentKey: regexp.MustCompile(`(controller-manager|route-controller-manager)`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^ScalingReplicaSet$`),
-		messageHumanRegex:  regexp.MustCompile(`\(combined from similar events\): Scaled (down|up) replica set.*controller-manager-[a-z0-9-]+ to [0-9]+`),
-	})
-
-	// Match pod sandbox errors as "interesting" so they get charted, but we do not ever allow them to repeat
-	// pathologically.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:              "PodSandbox",
-		messageHumanRegex: regexp.MustCompile(`pod sandbox`),
-		neverAllow:        true,
-	})
-
-	// There is an "event leak" for RecreatingTerminatedPod/RecreatingFailedPod/SuccessfulDelete
-	// events on Statefulsets. Two of those started to be heavily emitted in Kube v1.29
-	// Ignore them until https://issues.redhat.com/browse/OCPBUGS-27262 is fixed
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "LeakyStatefulsetEvents",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`^openshift-(monitoring|user-workload-monitoring)$`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^RecreatingTerminatedPod|RecreatingFailedPod|SuccessfulDelete$`),
-		messageHumanRegex:  regexp.MustCompile(`.*StatefulSet.*`),
-	})
+	// Matchers that only need regexes are defined in pathological_event_matchers.yaml.
+	registry.addDefinedPathologicalEventMatchers(false)
 
 	registry.AddPathologicalEventMatcherOrDie(AllowBackOffRestartingFailedContainer)
 
@@ -480,6 +307,8 @@ func NewUniversalPathologicalEventMatchers(kubeConfig *rest.Config, finalInterva
 	singleNodeConnectionRefusedMatcher := newSingleNodeConnectionRefusedEventMatcher(finalIntervals)
 	registry.AddPathologicalEventMatcherOrDie(singleNodeConnectionRefusedMatcher)
 
+	registry.applyPathologicalEventMatcherOverrides(false)
+
 	return registry
 }
 
@@ -492,56 +321,14 @@ func NewUpgradePathologicalEventMatchers(kubeConfig *rest.Config, finalIntervals
 
 	// Now add in the matchers we only want to apply during upgrade:
 
-	// Operators that use library-go can report about multiple versions during upgrades.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "OperatorMultipleVersions",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey:  regexp.MustCompile(`(openshift-etcd-operator|openshift-kube-apiserver-operator|openshift-kube-controller-manager-operator|openshift-kube-scheduler-operator)`),
-			monitorapi.LocatorDeploymentKey: regexp.MustCompile(`(etcd-operator|kube-apiserver-operator|kube-controller-manager-operator|openshift-kube-scheduler-operator)`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^MultipleVersions$`),
-		messageHumanRegex:  regexp.MustCompile(`multiple versions found, probably in transition`),
-	})
-
-	// etcd-quorum-guard can fail during upgrades.
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "EtcdQuorumGuardReadinessProbe",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey: regexp.MustCompile(`openshift-etcd`),
-			monitorapi.LocatorPodKey:       regexp.MustCompile(`^etcd-quorum-guard.*`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^Unhealthy$`),
-		messageHumanRegex:  regexp.MustCompile(`Readiness probe failed:`),
-	})
-
-	// etcd can have unhealthy members during an upgrade
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name: "EtcdUnhealthyMembers",
-		locatorKeyRegexes: map[monitorapi.LocatorKey]*regexp.Regexp{
-			monitorapi.LocatorNamespaceKey:  regexp.MustCompile(`openshift-etcd-operator`),
-			monitorapi.LocatorDeploymentKey: regexp.MustCompile(`etcd-operator`),
-		},
-		messageReasonRegex: regexp.MustCompile(`^UnhealthyEtcdMember$`),
-		messageHumanRegex:  regexp.MustCompile(`unhealthy members`),
-	})
-
-	// Ignore NetworkNotReady repeat events.
-	// This was originally linked to bugzilla: https://bugzilla.redhat.com/show_bug.cgi?id=1986370
-	// The bug has been closed as NOTABUG.
-	// We used to allow this for three namespaces (openshift-multus, openshift-e2e-loki, and openshift-network-diagnostics),
-	// however a quick search of the intervals in bigquery shows this happening a ton in lots of namespaces,
-	// and killing jobs when it does. Given the bug status, I am ignoring these events, whenever they occur, in
-	// all upgrade jobs for now. - dgoodwin
-	registry.AddPathologicalEventMatcherOrDie(&SimplePathologicalEventMatcher{
-		name:               "NetworkNotReady",
-		messageReasonRegex: regexp.MustCompile(`^NetworkNotReady$`),
-		messageHumanRegex:  regexp.MustCompile(`network is not ready: container runtime network not ready: NetworkReady=false reason:NetworkPluginNotReady message:Network plugin returns error: No CNI configuration file.*Has your network provider started\?`),
-	})
+	registry.addDefinedPathologicalEventMatchers(true)
 
 	// Allow FailedScheduling repeat events during node upgrades:
 	m := newFailedSchedulingDuringNodeUpdatePathologicalEventMatcher(finalIntervals)
 	registry.AddPathologicalEventMatcherOrDie(m)
 
+	registry.applyPathologicalEventMatcherOverrides(true)
+
 	return registry
 }
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 	"github.com/sirupsen/logrus"
@@ -40,6 +41,9 @@ func TestDuplicatedEventForUpgrade(events monitorapi.Intervals, kubeClientConfig
 	tests := []*junitapi.JUnitTestCase{}
 	tests = append(tests, evaluator.testDuplicatedCoreNamespaceEvents(events, kubeClientConfig)...)
 	tests = append(tests, evaluator.testDuplicatedE2ENamespaceEvents(events, kubeClientConfig)...)
+	tests = append(tests, evaluator.testEventStorms(events)...)
+	tests = append(tests, testExpiredPathologicalEventMatchers(registry, time.Now())...)
+	tests = append(tests, testPathologicalEventMatcherOverrides()...)
 	return tests
 }
 
@@ -62,9 +66,59 @@ func TestDuplicatedEventForStableSystem(events monitorapi.Intervals, clientConfi
 	tests := []*junitapi.JUnitTestCase{}
 	tests = append(tests, evaluator.testDuplicatedCoreNamespaceEvents(events, clientConfig)...)
 	tests = append(tests, evaluator.testDuplicatedE2ENamespaceEvents(events, clientConfig)...)
+	tests = append(tests, evaluator.testEventStorms(events)...)
+	tests = append(tests, testExpiredPathologicalEventMatchers(registry, time.Now())...)
+	tests = append(tests, testPathologicalEventMatcherOverrides()...)
 	return tests
 }
 
+// testExpiredPathologicalEventMatchers fails when an allowance outlived its expiry, so that it is either fixed or
+// deliberately extended by its owner.
+func testExpiredPathologicalEventMatchers(registry *AllowedPathologicalEventRegistry, now time.Time) []*junitapi.JUnitTestCase {
+	const testName = "[sig-arch] pathological event allowances should not be expired"
+
+	expired := registry.ExpiredMatchers(now)
+	if len(expired) == 0 {
+		return []*junitapi.JUnitTestCase{{Name: testName}}
+	}
+
+	lines := []string{}
+	for _, matcher := range expired {
+		lines = append(lines, fmt.Sprintf("%s expired on %s, owner=%q jira=%q", matcher.Name(), matcher.expires.Format(expiresLayout), matcher.owner, matcher.jira))
+	}
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%d pathological event allowances have expired:\n%s", len(expired), strings.Join(lines, "\n")),
+			},
+		},
+	}
+}
+
+// testPathologicalEventMatcherOverrides fails when the matchers from PathologicalEventMatchersFileEnvVar could not be
+// used, so that a broken file is noticed instead of silently running with the matchers compiled in.
+func testPathologicalEventMatcherOverrides() []*junitapi.JUnitTestCase {
+	_, _, err := getPathologicalEventMatcherDefinitions()
+	return pathologicalEventMatcherOverridesTestCases(err)
+}
+
+func pathologicalEventMatcherOverridesTestCases(overridesErr error) []*junitapi.JUnitTestCase {
+	const testName = "[sig-arch] pathological event matchers from " + PathologicalEventMatchersFileEnvVar + " should be valid"
+
+	if overridesErr == nil {
+		return []*junitapi.JUnitTestCase{{Name: testName}}
+	}
+	return []*junitapi.JUnitTestCase{
+		{
+			Name: testName,
+			FailureOutput: &junitapi.FailureOutput{
+				Output: overridesErr.Error(),
+			},
+		},
+	}
+}
+
 type duplicateEventsEvaluator struct {
 	registry *AllowedPathologicalEventRegistry
 
@@ -0,0 +1,240 @@
+package pathologicaleventlibrary
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EventStormWindow is the sliding window event rates are computed over.
+	EventStormWindow = 5 * time.Minute
+
+	// EventStormEventsPerMinute is the rate over EventStormWindow above which repeating events are a storm. It allows
+	// DuplicateEventThreshold events in a window, where the total count allows them over the whole run.
+	EventStormEventsPerMinute = 4.0
+
+	// EventStormFailureEventsPerMinute is the rate above which a storm without a matcher fails, slower storms flake.
+	EventStormFailureEventsPerMinute = 30.0
+)
+
+// EventStorm is a period during which the same event repeated faster than the allowed rate.
+type EventStorm struct {
+	Locator monitorapi.Locator
+	// Message is the message of the last event of the storm.
+	Message monitorapi.Message
+	From    time.Time
+	To      time.Time
+	// Events is the number of times the event happened during the storm.
+	Events int
+	// PeakEventsPerMinute is the highest rate over a window of the storm.
+	PeakEventsPerMinute float64
+}
+
+// eventOccurrence is a number of events that were observed at the same time.
+type eventOccurrence struct {
+	at    time.Time
+	count int
+}
+
+// FindEventStorms computes the rate of every kube event, identified by its locator, reason and message, over sliding
+// windows and returns the periods where it was above eventsPerMinute.
+func FindEventStorms(intervals monitorapi.Intervals, window time.Duration, eventsPerMinute float64) []EventStorm {
+	eventsByKey := map[string]monitorapi.Intervals{}
+	for _, interval := range intervals {
+		if interval.Source != monitorapi.SourceKubeEvent {
+			continue
+		}
+		key := fmt.Sprintf("%s - reason/%s %s", interval.Locator.OldLocator(),
+			interval.Message.Reason, interval.Message.HumanMessage)
+		eventsByKey[key] = append(eventsByKey[key], interval)
+	}
+	keys := []string{}
+	for key := range eventsByKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	storms := []EventStorm{}
+	for _, key := range keys {
+		storms = append(storms, findEventStorms(eventsByKey[key], window, eventsPerMinute)...)
+	}
+	return storms
+}
+
+// eventOccurrences turns the intervals recorded for each observation of a kube event into the events that happened
+// between observations, using the count of the event.
+func eventOccurrences(events monitorapi.Intervals) []eventOccurrence {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].From.Before(events[j].From)
+	})
+
+	occurrences := []eventOccurrence{}
+	previous := 0
+	for _, event := range events {
+		count := GetTimesAnEventHappened(event.Message)
+		happened := count - previous
+		switch {
+		case previous == 0:
+			// the repeats before the first observation may have happened long before the run, so they are not
+			// attributed to it.
+			happened = 1
+		case count < previous:
+			// the event was deleted and recreated.
+			happened = count
+		}
+		previous = count
+		if happened > 0 {
+			occurrences = append(occurrences, eventOccurrence{at: event.From, count: happened})
+		}
+	}
+	return occurrences
+}
+
+func findEventStorms(events monitorapi.Intervals, window time.Duration, eventsPerMinute float64) []EventStorm {
+	occurrences := eventOccurrences(events)
+	last := events[len(events)-1]
+
+	// totals[i] is the number of events before occurrences[i].
+	totals := make([]int, len(occurrences)+1)
+	for i, occurrence := range occurrences {
+		totals[i+1] = totals[i] + occurrence.count
+	}
+
+	storms := []EventStorm{}
+	var current *EventStorm
+	stormStart := 0
+	windowStart := 0
+	for i, occurrence := range occurrences {
+		for occurrence.at.Sub(occurrences[windowStart].at) >= window {
+			windowStart++
+		}
+		rate := float64(totals[i+1]-totals[windowStart]) / window.Minutes()
+		if rate <= eventsPerMinute {
+			continue
+		}
+
+		// windows that overlap the current storm extend it.
+		if current == nil || occurrences[windowStart].at.After(current.To) {
+			if current != nil {
+				storms = append(storms, *current)
+			}
+			current = &EventStorm{
+				Locator: last.Locator,
+				Message: last.Message,
+				From:    occurrences[windowStart].at,
+			}
+			stormStart = windowStart
+		}
+		current.To = occurrence.at
+		current.Events = totals[i+1] - totals[stormStart]
+		if rate > current.PeakEventsPerMinute {
+			current.PeakEventsPerMinute = rate
+		}
+	}
+	if current != nil {
+		storms = append(storms, *current)
+	}
+	return storms
+}
+
+// ComputeEventStormIntervals returns an EventStorm interval for every kube event that repeated faster than
+// EventStormEventsPerMinute over EventStormWindow.
+func ComputeEventStormIntervals(intervals monitorapi.Intervals) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	for _, storm := range FindEventStorms(intervals, EventStormWindow, EventStormEventsPerMinute) {
+		to := storm.To
+		if !to.After(storm.From) {
+			// intervals with from == to are not charted.
+			to = storm.From.Add(time.Second)
+		}
+		ret = append(ret, monitorapi.NewInterval(monitorapi.SourceEventStorm, monitorapi.Warning).
+			Locator(storm.Locator).
+			Message(monitorapi.NewMessage().
+				Reason(monitorapi.EventStormReason).
+				Constructed(monitorapi.ConstructionOwnerEventStorm).
+				WithAnnotation(monitorapi.AnnotationEventReason, string(storm.Message.Reason)).
+				WithAnnotation(monitorapi.AnnotationStormEvents, strconv.Itoa(storm.Events)).
+				WithAnnotation(monitorapi.AnnotationEventsPerMinute, strconv.FormatFloat(storm.PeakEventsPerMinute, 'f', 1, 64)).
+				HumanMessage(storm.Message.HumanMessage)).
+			Display().
+			Build(storm.From, to))
+	}
+	return ret
+}
+
+// eventStormEvent returns the event of an EventStorm interval, with the number of events of the storm as its count, so
+// that it can be checked against the matchers, and the peak rate of the storm.
+func eventStormEvent(storm monitorapi.Interval) (monitorapi.Interval, float64) {
+	event := storm
+	event.Message.Reason = monitorapi.IntervalReason(storm.Message.Annotations[monitorapi.AnnotationEventReason])
+	event.Message.Annotations = map[monitorapi.AnnotationKey]string{}
+	for k, v := range storm.Message.Annotations {
+		event.Message.Annotations[k] = v
+	}
+	event.Message.Annotations[monitorapi.AnnotationCount] = storm.Message.Annotations[monitorapi.AnnotationStormEvents]
+	eventsPerMinute, err := strconv.ParseFloat(storm.Message.Annotations[monitorapi.AnnotationEventsPerMinute], 64)
+	if err != nil {
+		logrus.Warnf("event storm had a non-numeric rate? %+v", storm.Message)
+	}
+	return event, eventsPerMinute
+}
+
+// testEventStorms checks the EventStorm intervals against the registry.  Storms without a matcher fail when they
+// are faster than EventStormFailureEventsPerMinute, and flake otherwise.
+func (d *duplicateEventsEvaluator) testEventStorms(events monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	const testName = "[sig-arch] events should not repeat faster than the allowed rate"
+
+	failures := []string{}
+	flakes := []string{}
+	for _, interval := range events {
+		if interval.Source != monitorapi.SourceEventStorm {
+			continue
+		}
+		event, eventsPerMinute := eventStormEvent(interval)
+		if allowed, _ := d.registry.AllowedStormByAny(event, eventsPerMinute, d.topology); allowed {
+			continue
+		}
+		msg := fmt.Sprintf("event happened %d times at up to %.1f per minute from %s to %s: %s - reason/%s %s",
+			GetTimesAnEventHappened(event.Message), eventsPerMinute, interval.From.Format("15:04:05Z"), interval.To.Format("15:04:05Z"),
+			event.Locator.OldLocator(), event.Message.Reason, event.Message.HumanMessage)
+		if eventsPerMinute > EventStormFailureEventsPerMinute {
+			failures = append(failures, appendToFirstLine(msg, " result=reject "))
+		} else {
+			flakes = append(flakes, appendToFirstLine(msg, " result=allow "))
+		}
+	}
+
+	if len(failures) == 0 && len(flakes) == 0 {
+		return []*junitapi.JUnitTestCase{{Name: testName}}
+	}
+	output := ""
+	if len(failures) > 0 {
+		output = fmt.Sprintf("%d events repeated faster than %.1f per minute over %v:\n%s",
+			len(failures), EventStormFailureEventsPerMinute, EventStormWindow, strings.Join(failures, "\n"))
+	}
+	if len(flakes) > 0 {
+		if len(output) > 0 {
+			output += "\n\n"
+		}
+		output += fmt.Sprintf("%d events repeated faster than %.1f per minute over %v:\n%s",
+			len(flakes), EventStormEventsPerMinute, EventStormWindow, strings.Join(flakes, "\n"))
+	}
+	tests := []*junitapi.JUnitTestCase{
+		{
+			Name:          testName,
+			FailureOutput: &junitapi.FailureOutput{Output: output},
+		},
+	}
+	if len(failures) == 0 {
+		// Add a success for flakes
+		tests = append(tests, &junitapi.JUnitTestCase{Name: testName})
+	}
+	return tests
+}
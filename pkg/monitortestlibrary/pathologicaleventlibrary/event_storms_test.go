@@ -0,0 +1,153 @@
+package pathologicaleventlibrary
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// kubeEvents returns the intervals recorded when an event with the given counts is observed every interval.
+func kubeEvents(reason monitorapi.IntervalReason, start time.Time, every time.Duration, counts ...int) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	for i, count := range counts {
+		from := start.Add(time.Duration(i) * every)
+		ret = append(ret, monitorapi.NewInterval(monitorapi.SourceKubeEvent, monitorapi.Warning).
+			Locator(monitorapi.NewLocator().NodeFromName("node-1")).
+			Message(monitorapi.NewMessage().Reason(reason).HumanMessage("something happened").
+				WithAnnotation(monitorapi.AnnotationCount, strconv.Itoa(count))).
+			Build(from, from.Add(time.Second)))
+	}
+	return ret
+}
+
+func TestFindEventStorms(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		intervals      monitorapi.Intervals
+		expectedStorms []EventStorm
+	}{
+		{
+			name: "slow steady repeats",
+			// 50 events in under an hour is far over the total count threshold, but only one per minute.
+			intervals: kubeEvents("Slow", start, time.Minute, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20,
+				21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35, 36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50),
+			expectedStorms: []EventStorm{},
+		},
+		{
+			name: "short storm",
+			// quiet, then 30 events in two minutes and one more while the window still holds them.
+			intervals: append(
+				kubeEvents("Storm", start, time.Minute, 1, 2, 3),
+				kubeEvents("Storm", start.Add(10*time.Minute), time.Minute, 13, 23, 33, 34)...),
+			expectedStorms: []EventStorm{
+				{
+					From:                start.Add(10 * time.Minute),
+					To:                  start.Add(13 * time.Minute),
+					Events:              31,
+					PeakEventsPerMinute: 6.2,
+				},
+			},
+		},
+		{
+			name:           "count before the run is not a storm",
+			intervals:      kubeEvents("Old", start, time.Minute, 500, 501),
+			expectedStorms: []EventStorm{},
+		},
+		{
+			name:      "recreated event",
+			intervals: kubeEvents("Recreated", start, time.Minute, 1, 30, 2),
+			expectedStorms: []EventStorm{
+				{
+					From:                start,
+					To:                  start.Add(2 * time.Minute),
+					Events:              32,
+					PeakEventsPerMinute: 6.4,
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storms := FindEventStorms(test.intervals, EventStormWindow, EventStormEventsPerMinute)
+			require.Len(t, storms, len(test.expectedStorms))
+			for i, expected := range test.expectedStorms {
+				assert.Equal(t, expected.From, storms[i].From)
+				assert.Equal(t, expected.To, storms[i].To)
+				assert.Equal(t, expected.Events, storms[i].Events)
+				assert.InDelta(t, expected.PeakEventsPerMinute, storms[i].PeakEventsPerMinute, 0.01)
+			}
+		})
+	}
+}
+
+func TestEventStormAllowances(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	storms := ComputeEventStormIntervals(kubeEvents("Storm", start, time.Minute, 1, 31))
+	require.Len(t, storms, 1)
+	assert.Equal(t, monitorapi.EventStormReason, storms[0].Message.Reason)
+	assert.Equal(t, "6.2", storms[0].Message.Annotations[monitorapi.AnnotationEventsPerMinute])
+
+	definitions, err := ParsePathologicalEventMatcherDefinitions([]byte(`
+version: v1
+matchers:
+- name: SlowStorm
+  messageReasonRegex: ^Storm$
+  allowedEventsPerMinute: 5
+`))
+	require.NoError(t, err)
+	matcher, err := definitions.Matchers[0].toMatcher()
+	require.NoError(t, err)
+	evaluator := duplicateEventsEvaluator{
+		registry: &AllowedPathologicalEventRegistry{matchers: map[string]EventMatcher{}},
+	}
+	evaluator.registry.AddPathologicalEventMatcherOrDie(matcher)
+
+	// the storm is faster than the matcher allows, but under the failure rate.
+	tests := evaluator.testEventStorms(storms)
+	require.Len(t, tests, 2, "expected a flake")
+	require.NotNil(t, tests[0].FailureOutput)
+	assert.Contains(t, tests[0].FailureOutput.Output, "event happened 31 times at up to 6.2 per minute")
+	assert.Nil(t, tests[1].FailureOutput)
+
+	fastStorms := ComputeEventStormIntervals(kubeEvents("Storm", start, time.Minute, 1, 201))
+	require.Len(t, fastStorms, 1)
+	tests = evaluator.testEventStorms(fastStorms)
+	require.Len(t, tests, 1, "expected a failure")
+	require.NotNil(t, tests[0].FailureOutput)
+	assert.Contains(t, tests[0].FailureOutput.Output, "event happened 201 times at up to 40.2 per minute")
+
+	matcher.allowedEventsPerMinute = 10
+	tests = evaluator.testEventStorms(storms)
+	require.Len(t, tests, 1)
+	assert.Nil(t, tests[0].FailureOutput)
+}
+
+func TestEventStormsAreNotRepeatedEvents(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	events := monitorapi.Intervals{}
+	for i, count := range []int{1, 15, 30} {
+		from := start.Add(time.Duration(i) * time.Minute)
+		events = append(events, monitorapi.NewInterval(monitorapi.SourceKubeEvent, monitorapi.Warning).
+			Locator(monitorapi.NewLocator().PodFromNames("openshift-etcd", "etcd-guard-master-0", "")).
+			Message(monitorapi.NewMessage().Reason("Unhealthy").
+				HumanMessage("Readiness probe failed: HTTP probe failed with statuscode: 500").
+				WithAnnotation(monitorapi.AnnotationCount, strconv.Itoa(count))).
+			Build(from, from.Add(time.Second)))
+	}
+	storms := ComputeEventStormIntervals(events)
+	require.Len(t, storms, 1)
+	assert.Equal(t, "30", storms[0].Message.Annotations[monitorapi.AnnotationStormEvents])
+
+	// the repeats are allowed by KubeletUnhealthyReadinessProbeFailed, the storm of them must not fail as a repeat.
+	evaluator := duplicateEventsEvaluator{registry: NewUniversalPathologicalEventMatchers(nil, nil)}
+	junits := evaluator.testDuplicatedEvents("events should not repeat", false, append(events, storms...), nil, false)
+	for _, junit := range junits {
+		assert.Nil(t, junit.FailureOutput, "unexpected failure of %s", junit.Name)
+	}
+}
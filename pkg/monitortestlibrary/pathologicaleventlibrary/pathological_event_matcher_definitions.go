@@ -0,0 +1,226 @@
+package pathologicaleventlibrary
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// PathologicalEventMatchersFileEnvVar names a file with matchers that are added to the ones compiled into
+// openshift-tests.  Matchers with the name of an existing matcher replace it, so that teams can iterate on their
+// allowances without rebuilding.
+const PathologicalEventMatchersFileEnvVar = "PATHOLOGICAL_EVENT_MATCHERS_FILE"
+
+// pathologicalEventMatchersVersion is the only version of the format we understand.
+const pathologicalEventMatchersVersion = "v1"
+
+// expiresLayout is the format of the expiry date of a matcher.
+const expiresLayout = "2006-01-02"
+
+//go:embed pathological_event_matchers.yaml
+var pathologicalEventMatchersYAML []byte
+
+// PathologicalEventMatcherDefinitions is the versioned file format for pathological event matchers.  It may be YAML
+// or JSON.
+type PathologicalEventMatcherDefinitions struct {
+	Version  string                               `json:"version"`
+	Matchers []PathologicalEventMatcherDefinition `json:"matchers"`
+}
+
+// PathologicalEventMatcherDefinition describes a SimplePathologicalEventMatcher.
+type PathologicalEventMatcherDefinition struct {
+	Name                    string            `json:"name"`
+	LocatorKeyRegexes       map[string]string `json:"locatorKeyRegexes,omitempty"`
+	MessageReasonRegex      string            `json:"messageReasonRegex,omitempty"`
+	MessageHumanRegex       string            `json:"messageHumanRegex,omitempty"`
+	RepeatThresholdOverride int               `json:"repeatThresholdOverride,omitempty"`
+	// AllowedEventsPerMinute limits the rate of the event storms the matcher allows.
+	AllowedEventsPerMinute float64 `json:"allowedEventsPerMinute,omitempty"`
+	NeverAllow             bool    `json:"neverAllow,omitempty"`
+	Topology               string  `json:"topology,omitempty"`
+	// UpgradeOnly adds the matcher to the upgrade registry only.
+	UpgradeOnly bool `json:"upgradeOnly,omitempty"`
+	// Owner is the jira component that owns the allowance.
+	Owner string `json:"owner,omitempty"`
+	Jira  string `json:"jira,omitempty"`
+	// Expires is the YYYY-MM-DD date after which the allowance is reported as expired.
+	Expires string `json:"expires,omitempty"`
+}
+
+var validTopologies = sets.New[string](string(v1.HighlyAvailableTopologyMode), string(v1.SingleReplicaTopologyMode), string(v1.ExternalTopologyMode))
+
+// toMatcher compiles the definition, validating it along the way.
+func (d PathologicalEventMatcherDefinition) toMatcher() (*SimplePathologicalEventMatcher, error) {
+	if len(d.Name) == 0 {
+		return nil, fmt.Errorf("name is required")
+	}
+	matcher := &SimplePathologicalEventMatcher{
+		name:                    d.Name,
+		repeatThresholdOverride: d.RepeatThresholdOverride,
+		allowedEventsPerMinute:  d.AllowedEventsPerMinute,
+		neverAllow:              d.NeverAllow,
+		jira:                    d.Jira,
+		owner:                   d.Owner,
+	}
+
+	errs := []string{}
+	compile := func(field, expr string) *regexp.Regexp {
+		r, err := regexp.Compile(expr)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid %s: %v", field, err))
+		}
+		return r
+	}
+	if len(d.LocatorKeyRegexes) > 0 {
+		matcher.locatorKeyRegexes = map[monitorapi.LocatorKey]*regexp.Regexp{}
+		for k, expr := range d.LocatorKeyRegexes {
+			matcher.locatorKeyRegexes[monitorapi.LocatorKey(k)] = compile("locatorKeyRegexes."+k, expr)
+		}
+	}
+	if len(d.MessageReasonRegex) > 0 {
+		matcher.messageReasonRegex = compile("messageReasonRegex", d.MessageReasonRegex)
+	}
+	if len(d.MessageHumanRegex) > 0 {
+		matcher.messageHumanRegex = compile("messageHumanRegex", d.MessageHumanRegex)
+	}
+	if len(d.LocatorKeyRegexes) == 0 && len(d.MessageReasonRegex) == 0 && len(d.MessageHumanRegex) == 0 {
+		errs = append(errs, "at least one of locatorKeyRegexes, messageReasonRegex and messageHumanRegex is required")
+	}
+	if d.RepeatThresholdOverride < 0 {
+		errs = append(errs, "repeatThresholdOverride must not be negative")
+	}
+	if d.AllowedEventsPerMinute < 0 {
+		errs = append(errs, "allowedEventsPerMinute must not be negative")
+	}
+	if len(d.Topology) > 0 {
+		if !validTopologies.Has(d.Topology) {
+			errs = append(errs, fmt.Sprintf("topology must be one of %v, not %q", sets.List(validTopologies), d.Topology))
+		}
+		topology := v1.TopologyMode(d.Topology)
+		matcher.topology = &topology
+	}
+	if len(d.Expires) > 0 {
+		expires, err := time.Parse(expiresLayout, d.Expires)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("expires must be a YYYY-MM-DD date: %v", err))
+		}
+		matcher.expires = &expires
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return matcher, nil
+}
+
+// ParsePathologicalEventMatcherDefinitions reads and validates pathological event matchers in YAML or JSON.
+func ParsePathologicalEventMatcherDefinitions(data []byte) (*PathologicalEventMatcherDefinitions, error) {
+	definitions := &PathologicalEventMatcherDefinitions{}
+	if err := yaml.UnmarshalStrict(data, definitions); err != nil {
+		return nil, err
+	}
+	if definitions.Version != pathologicalEventMatchersVersion {
+		return nil, fmt.Errorf("unsupported version %q, expected %q", definitions.Version, pathologicalEventMatchersVersion)
+	}
+
+	errs := []string{}
+	seen := map[string]int{}
+	for i, definition := range definitions.Matchers {
+		if _, err := definition.toMatcher(); err != nil {
+			errs = append(errs, fmt.Sprintf("matchers[%d] %s: %v", i, definition.Name, err))
+		}
+		if previous, ok := seen[definition.Name]; ok {
+			errs = append(errs, fmt.Sprintf("matchers[%d] %s: duplicates matchers[%d]", i, definition.Name, previous))
+			continue
+		}
+		seen[definition.Name] = i
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid pathological event matchers:\n%s", strings.Join(errs, "\n"))
+	}
+	return definitions, nil
+}
+
+var (
+	readPathologicalEventMatcherDefinitions sync.Once
+	pathologicalEventMatcherDefinitions     []PathologicalEventMatcherDefinition
+	pathologicalEventMatcherOverrides       []PathologicalEventMatcherDefinition
+	pathologicalEventMatcherOverridesErr    error
+)
+
+// getPathologicalEventMatcherDefinitions returns the matchers compiled in and the ones from
+// PathologicalEventMatchersFileEnvVar.  A file that cannot be used is reported as an error and none of its matchers
+// are returned, so that the run goes on with the matchers compiled in.
+func getPathologicalEventMatcherDefinitions() (defaults, overrides []PathologicalEventMatcherDefinition, overridesErr error) {
+	readPathologicalEventMatcherDefinitions.Do(
+		func() {
+			definitions, err := ParsePathologicalEventMatcherDefinitions(pathologicalEventMatchersYAML)
+			if err != nil {
+				panic(err)
+			}
+			pathologicalEventMatcherDefinitions = definitions.Matchers
+
+			pathologicalEventMatcherOverrides, pathologicalEventMatcherOverridesErr = readPathologicalEventMatcherOverrides(os.Getenv(PathologicalEventMatchersFileEnvVar))
+			if pathologicalEventMatcherOverridesErr != nil {
+				logrus.WithError(pathologicalEventMatcherOverridesErr).Error("ignoring pathological event matcher overrides")
+			}
+		})
+
+	return pathologicalEventMatcherDefinitions, pathologicalEventMatcherOverrides, pathologicalEventMatcherOverridesErr
+}
+
+// readPathologicalEventMatcherOverrides reads the matchers in filename, if any.
+func readPathologicalEventMatcherOverrides(filename string) ([]PathologicalEventMatcherDefinition, error) {
+	if len(filename) == 0 {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s=%s: %w", PathologicalEventMatchersFileEnvVar, filename, err)
+	}
+	overrides, err := ParsePathologicalEventMatcherDefinitions(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s=%s: %w", PathologicalEventMatchersFileEnvVar, filename, err)
+	}
+	return overrides.Matchers, nil
+}
+
+// addDefinedPathologicalEventMatchers registers the matchers compiled in for the universal or the upgrade registry.
+func (r *AllowedPathologicalEventRegistry) addDefinedPathologicalEventMatchers(upgrade bool) {
+	defaults, _, _ := getPathologicalEventMatcherDefinitions()
+	for _, definition := range defaults {
+		if definition.UpgradeOnly != upgrade {
+			continue
+		}
+		// definitions were validated when they were read.
+		matcher, _ := definition.toMatcher()
+		r.AddPathologicalEventMatcherOrDie(matcher)
+	}
+}
+
+// applyPathologicalEventMatcherOverrides registers the matchers from PathologicalEventMatchersFileEnvVar for the
+// universal or the upgrade registry.  They replace registered matchers of the same name, including the ones written in
+// go, so it must be called once everything else is registered.
+func (r *AllowedPathologicalEventRegistry) applyPathologicalEventMatcherOverrides(upgrade bool) {
+	_, overrides, _ := getPathologicalEventMatcherDefinitions()
+	for _, definition := range overrides {
+		if definition.UpgradeOnly != upgrade {
+			continue
+		}
+		matcher, _ := definition.toMatcher()
+		if _, ok := r.matchers[matcher.Name()]; ok {
+			logrus.Infof("pathological event matcher %s replaced by %s", matcher.Name(), os.Getenv(PathologicalEventMatchersFileEnvVar))
+		}
+		r.matchers[matcher.Name()] = matcher
+	}
+}
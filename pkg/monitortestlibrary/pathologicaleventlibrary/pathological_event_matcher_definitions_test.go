@@ -0,0 +1,190 @@
+package pathologicaleventlibrary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPathologicalEventMatcherDefinitions(t *testing.T) {
+	definitions, err := ParsePathologicalEventMatcherDefinitions(pathologicalEventMatchersYAML)
+	require.NoError(t, err)
+	assert.NotEmpty(t, definitions.Matchers)
+}
+
+func TestParsePathologicalEventMatcherDefinitions(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			data: `
+version: v1
+matchers:
+- name: FooBackOff
+  locatorKeyRegexes:
+    ns: ^e2e-foo
+  messageReasonRegex: ^BackOff$
+  repeatThresholdOverride: 50
+  topology: SingleReplica
+  owner: Foo
+  jira: https://issues.redhat.com/browse/OCPBUGS-1
+  expires: "2024-01-31"
+`,
+		},
+		{
+			name:          "wrong version",
+			data:          "version: v2\nmatchers: []\n",
+			expectedError: `unsupported version "v2"`,
+		},
+		{
+			name:          "unknown field",
+			data:          "version: v1\nmatchers:\n- name: Foo\n  messageReasonRegex: Foo\n  reason: Foo\n",
+			expectedError: `unknown field "reason"`,
+		},
+		{
+			name:          "nothing to match",
+			data:          "version: v1\nmatchers:\n- name: Foo\n",
+			expectedError: "at least one of locatorKeyRegexes, messageReasonRegex and messageHumanRegex is required",
+		},
+		{
+			name:          "invalid regex",
+			data:          "version: v1\nmatchers:\n- name: Foo\n  messageHumanRegex: \"(\"\n",
+			expectedError: "invalid messageHumanRegex",
+		},
+		{
+			name:          "invalid topology",
+			data:          "version: v1\nmatchers:\n- name: Foo\n  messageReasonRegex: Foo\n  topology: Tiny\n",
+			expectedError: `not "Tiny"`,
+		},
+		{
+			name:          "invalid expiry",
+			data:          "version: v1\nmatchers:\n- name: Foo\n  messageReasonRegex: Foo\n  expires: next week\n",
+			expectedError: "expires must be a YYYY-MM-DD date",
+		},
+		{
+			name:          "duplicate",
+			data:          "version: v1\nmatchers:\n- name: Foo\n  messageReasonRegex: Foo\n- name: Foo\n  messageReasonRegex: Bar\n",
+			expectedError: "matchers[1] Foo: duplicates matchers[0]",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParsePathologicalEventMatcherDefinitions([]byte(test.data))
+			if len(test.expectedError) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedError)
+		})
+	}
+}
+
+func TestExpiredPathologicalEventMatchers(t *testing.T) {
+	definitions, err := ParsePathologicalEventMatcherDefinitions([]byte(`
+version: v1
+matchers:
+- name: Expired
+  messageReasonRegex: ^Expired$
+  owner: Foo
+  jira: https://issues.redhat.com/browse/OCPBUGS-1
+  expires: "2024-01-31"
+- name: Current
+  messageReasonRegex: ^Current$
+  expires: "2024-03-31"
+- name: Forever
+  messageReasonRegex: ^Forever$
+`))
+	require.NoError(t, err)
+
+	registry := &AllowedPathologicalEventRegistry{matchers: map[string]EventMatcher{}}
+	for _, definition := range definitions.Matchers {
+		matcher, err := definition.toMatcher()
+		require.NoError(t, err)
+		registry.AddPathologicalEventMatcherOrDie(matcher)
+	}
+
+	// expired allowances still allow their events.
+	allowed, matcher := registry.AllowedByAny(monitorapi.Interval{
+		Condition: monitorapi.Condition{
+			Message: monitorapi.NewMessage().Reason("Expired").HumanMessage("repeated").Build(),
+		},
+	}, "")
+	assert.True(t, allowed)
+	assert.Equal(t, "Expired", matcher.Name())
+
+	now := time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)
+	tests := testExpiredPathologicalEventMatchers(registry, now)
+	require.Len(t, tests, 1)
+	require.NotNil(t, tests[0].FailureOutput)
+	assert.Contains(t, tests[0].FailureOutput.Output, `Expired expired on 2024-01-31, owner="Foo" jira="https://issues.redhat.com/browse/OCPBUGS-1"`)
+	assert.NotContains(t, tests[0].FailureOutput.Output, "Current")
+
+	tests = testExpiredPathologicalEventMatchers(registry, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.Len(t, tests, 1)
+	assert.Nil(t, tests[0].FailureOutput)
+}
+
+func TestReadPathologicalEventMatcherOverrides(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("version: v1\nmatchers:\n- name: FooBackOff\n  messageReasonRegex: ^BackOff$\n"), 0644))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("version: v1\nmatchers:\n- name: FooBackOff\n"), 0644))
+
+	tests := []struct {
+		name          string
+		filename      string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name:     "valid",
+			filename: valid,
+			expected: []string{"FooBackOff"},
+		},
+		{
+			name:          "unreadable",
+			filename:      filepath.Join(dir, "missing.yaml"),
+			expectedError: "unable to read " + PathologicalEventMatchersFileEnvVar,
+		},
+		{
+			name:          "invalid",
+			filename:      invalid,
+			expectedError: "invalid " + PathologicalEventMatchersFileEnvVar,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			overrides, err := readPathologicalEventMatcherOverrides(test.filename)
+			junits := pathologicalEventMatcherOverridesTestCases(err)
+			require.Len(t, junits, 1)
+			if len(test.expectedError) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedError)
+				assert.Empty(t, overrides)
+				require.NotNil(t, junits[0].FailureOutput)
+				assert.Contains(t, junits[0].FailureOutput.Output, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Nil(t, junits[0].FailureOutput)
+			names := []string{}
+			for _, override := range overrides {
+				names = append(names, override.Name)
+			}
+			assert.ElementsMatch(t, test.expected, names)
+		})
+	}
+}
@@ -226,6 +226,23 @@ func GetJobType(ctx context.Context, clientConfig *rest.Config) (*JobType, error
 	}, nil
 }
 
+// GetPlatformType returns the lower case infrastructure platform type, for instance aws, vsphere or nutanix.  Unlike
+// JobType.Platform it is set for every platform, not only for the ones we keep historical data for.
+func GetPlatformType(ctx context.Context, clientConfig *rest.Config) (string, error) {
+	configClient, err := configclient.NewForConfig(clientConfig)
+	if err != nil {
+		return "", err
+	}
+	infrastructure, err := configClient.Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if infrastructure.Status.PlatformStatus == nil {
+		return strings.ToLower(string(infrastructure.Status.Platform)), nil
+	}
+	return strings.ToLower(string(infrastructure.Status.PlatformStatus.Type)), nil
+}
+
 func VersionFromHistory(history configv1.UpdateHistory) string {
 	versionParts := strings.Split(history.Version, ".")
 	if len(versionParts) < 2 {
@@ -0,0 +1,88 @@
+package prometheussnapshot
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// QueriesEnvVar enables the snapshot.  It is either the path to a file with SnapshotQueries or "default" for the
+// queries compiled into openshift-tests.
+const QueriesEnvVar = "PROMETHEUS_SNAPSHOT_QUERIES"
+
+const defaultStep = 30 * time.Second
+
+//go:embed default_queries.yaml
+var defaultQueriesYAML []byte
+
+// SnapshotQueries is the format of the file in PROMETHEUS_SNAPSHOT_QUERIES.
+type SnapshotQueries struct {
+	Queries []SnapshotQuery `json:"queries"`
+}
+
+// SnapshotQuery is a range query evaluated over the run window.
+type SnapshotQuery struct {
+	// Name identifies the query in the snapshot, the query itself can be used as well.  When the query is a selector,
+	// like ALERTS, selectors that narrow it with more label matchers can be answered from the snapshot too.
+	Name  string `json:"name"`
+	Query string `json:"query"`
+	// Step defaults to 30s.
+	Step *metav1.Duration `json:"step,omitempty"`
+}
+
+func (q SnapshotQuery) step() time.Duration {
+	if q.Step == nil {
+		return defaultStep
+	}
+	return q.Step.Duration
+}
+
+// ParseSnapshotQueries reads and validates queries in YAML or JSON.
+func ParseSnapshotQueries(data []byte) ([]SnapshotQuery, error) {
+	queries := &SnapshotQueries{}
+	if err := yaml.UnmarshalStrict(data, queries); err != nil {
+		return nil, err
+	}
+
+	names := sets.New[string]()
+	for i, query := range queries.Queries {
+		switch {
+		case len(query.Name) == 0:
+			return nil, fmt.Errorf("queries[%d]: name is required", i)
+		case len(query.Query) == 0:
+			return nil, fmt.Errorf("queries[%d] %s: query is required", i, query.Name)
+		case query.Step != nil && query.Step.Duration < time.Second:
+			return nil, fmt.Errorf("queries[%d] %s: step must be at least 1s", i, query.Name)
+		case names.Has(query.Name):
+			return nil, fmt.Errorf("queries[%d] %s: duplicate name", i, query.Name)
+		}
+		names.Insert(query.Name)
+	}
+	return queries.Queries, nil
+}
+
+// LoadSnapshotQueries returns the queries from PROMETHEUS_SNAPSHOT_QUERIES, or nil if the snapshot is not enabled.
+func LoadSnapshotQueries() ([]SnapshotQuery, error) {
+	source := os.Getenv(QueriesEnvVar)
+	switch source {
+	case "":
+		return nil, nil
+	case "default":
+		return ParseSnapshotQueries(defaultQueriesYAML)
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s=%s: %w", QueriesEnvVar, source, err)
+	}
+	queries, err := ParseSnapshotQueries(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queries in %s: %w", source, err)
+	}
+	return queries, nil
+}
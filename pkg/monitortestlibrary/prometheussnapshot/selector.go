@@ -0,0 +1,106 @@
+package prometheussnapshot
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+var (
+	selectorRegex = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(?:\{(.*)\})?$`)
+	matcherRegex  = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*"((?:[^"\\]|\\.)*)"\s*(?:,|$)`)
+)
+
+// labelMatcher is a label matcher of a selector, like alertstate="firing".
+type labelMatcher struct {
+	name     prometheustypes.LabelName
+	operator string
+	value    string
+	regex    *regexp.Regexp
+}
+
+func (m labelMatcher) matches(metric prometheustypes.Metric) bool {
+	value := string(metric[m.name])
+	switch m.operator {
+	case "=":
+		return value == m.value
+	case "!=":
+		return value != m.value
+	case "=~":
+		return m.regex.MatchString(value)
+	default:
+		return !m.regex.MatchString(value)
+	}
+}
+
+// selector is an instant vector selector, like ALERTS{alertstate="firing"}.  The snapshot cannot evaluate PromQL, but
+// the series of a selector can be picked from the series captured for a broader selector of the same metric.
+type selector struct {
+	metricName string
+	matchers   []labelMatcher
+}
+
+// parseSelector returns the selector in query, or false if the query is anything else.
+func parseSelector(query string) (*selector, bool) {
+	parts := selectorRegex.FindStringSubmatch(strings.TrimSpace(query))
+	if parts == nil {
+		return nil, false
+	}
+	ret := &selector{metricName: parts[1]}
+	for remaining := parts[2]; len(strings.TrimSpace(remaining)) > 0; {
+		matcherParts := matcherRegex.FindStringSubmatch(remaining)
+		if matcherParts == nil {
+			return nil, false
+		}
+		remaining = remaining[len(matcherParts[0]):]
+
+		value, err := strconv.Unquote(`"` + matcherParts[3] + `"`)
+		if err != nil {
+			return nil, false
+		}
+		matcher := labelMatcher{name: prometheustypes.LabelName(matcherParts[1]), operator: matcherParts[2], value: value}
+		if strings.HasSuffix(matcher.operator, "~") {
+			// prometheus anchors the regular expressions of label matchers.
+			if matcher.regex, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", value)); err != nil {
+				return nil, false
+			}
+		}
+		ret.matchers = append(ret.matchers, matcher)
+	}
+	return ret, true
+}
+
+// narrows returns true if every series selected by s is selected by broader as well.
+func (s *selector) narrows(broader *selector) bool {
+	if s.metricName != broader.metricName {
+		return false
+	}
+	for _, broaderMatcher := range broader.matchers {
+		found := false
+		for _, matcher := range s.matchers {
+			if matcher.name == broaderMatcher.name && matcher.operator == broaderMatcher.operator && matcher.value == broaderMatcher.value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *selector) matches(metric prometheustypes.Metric) bool {
+	if string(metric[prometheustypes.MetricNameLabel]) != s.metricName {
+		return false
+	}
+	for _, matcher := range s.matchers {
+		if !matcher.matches(metric) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,273 @@
+package prometheussnapshot
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prometheustypes "github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	snapshotVersion = "v1"
+
+	// maxPointsPerQuery stays below the 11000 points per series prometheus allows in a range query.
+	maxPointsPerQuery = 10000
+
+	// lookbackDelta is how far back an instant query looks for a sample, the same as the prometheus default.
+	lookbackDelta = 5 * time.Minute
+)
+
+// Querier is the part of the prometheus API that can be answered from a snapshot as well as from a live cluster, so
+// that invariants written against it can be re-evaluated offline.
+type Querier interface {
+	Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error)
+	QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error)
+}
+
+// Snapshot holds the results of range queries over the run window.
+type Snapshot struct {
+	Version string        `json:"version"`
+	Start   time.Time     `json:"start"`
+	End     time.Time     `json:"end"`
+	Results []QueryResult `json:"results"`
+}
+
+// QueryResult is the matrix a SnapshotQuery returned.
+type QueryResult struct {
+	Name   string                 `json:"name"`
+	Query  string                 `json:"query"`
+	Step   time.Duration          `json:"step"`
+	Matrix prometheustypes.Matrix `json:"matrix,omitempty"`
+	// Error is set when the query failed, the other queries are still captured.
+	Error string `json:"error,omitempty"`
+}
+
+var _ Querier = &Snapshot{}
+
+// Capture evaluates every query over the window.  Failing queries are recorded in the snapshot rather than failing it.
+func Capture(ctx context.Context, querier Querier, queries []SnapshotQuery, start, end time.Time) *Snapshot {
+	snapshot := &Snapshot{
+		Version: snapshotVersion,
+		Start:   start,
+		End:     end,
+	}
+	for _, query := range queries {
+		result := QueryResult{
+			Name:  query.Name,
+			Query: query.Query,
+			Step:  query.step(),
+		}
+		matrix, err := captureRange(ctx, querier, query.Query, start, end, query.step())
+		if err != nil {
+			logrus.WithError(err).Warnf("unable to capture %s", query.Name)
+			result.Error = err.Error()
+		}
+		result.Matrix = matrix
+		snapshot.Results = append(snapshot.Results, result)
+	}
+	return snapshot
+}
+
+// captureRange splits the window so that no series has more points than prometheus allows and stitches the series
+// back together.
+func captureRange(ctx context.Context, querier Querier, query string, start, end time.Time, step time.Duration) (prometheustypes.Matrix, error) {
+	seriesByFingerprint := map[prometheustypes.Fingerprint]*prometheustypes.SampleStream{}
+	for chunkStart := start; !chunkStart.After(end); chunkStart = chunkStart.Add(maxPointsPerQuery * step) {
+		chunkEnd := chunkStart.Add((maxPointsPerQuery - 1) * step)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		value, warnings, err := querier.QueryRange(ctx, query, prometheusv1.Range{Start: chunkStart, End: chunkEnd, Step: step})
+		if err != nil {
+			return nil, err
+		}
+		if len(warnings) > 0 {
+			logrus.Warnf("warnings capturing %q: %v", query, strings.Join(warnings, ", "))
+		}
+		matrix, ok := value.(prometheustypes.Matrix)
+		if !ok {
+			return nil, fmt.Errorf("expected a matrix, got %v", value.Type())
+		}
+		for _, series := range matrix {
+			fingerprint := series.Metric.Fingerprint()
+			if existing, ok := seriesByFingerprint[fingerprint]; ok {
+				existing.Values = append(existing.Values, series.Values...)
+				continue
+			}
+			seriesByFingerprint[fingerprint] = series
+		}
+	}
+
+	ret := prometheustypes.Matrix{}
+	for _, series := range seriesByFingerprint {
+		ret = append(ret, series)
+	}
+	sort.Sort(ret)
+	return ret, nil
+}
+
+// WriteSnapshot writes the snapshot as gzipped JSON.
+func WriteSnapshot(filename string, snapshot *Snapshot) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	if err := json.NewEncoder(gzipWriter).Encode(snapshot); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot.
+func ReadSnapshot(filename string) (*Snapshot, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+	snapshot := &Snapshot{}
+	if err := json.NewDecoder(gzipReader).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", filename, err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %q in %s, expected %q", snapshot.Version, filename, snapshotVersion)
+	}
+	return snapshot, nil
+}
+
+// result finds a captured query by name or by the query itself.  The snapshot cannot evaluate PromQL, so only captured
+// queries can be answered, and selectors that narrow a captured selector, like ALERTS{alertstate="firing"} when ALERTS
+// was captured.
+func (s *Snapshot) result(query string) (*QueryResult, error) {
+	query = strings.TrimSpace(query)
+	for i := range s.Results {
+		if s.Results[i].Name == query || strings.TrimSpace(s.Results[i].Query) == query {
+			if len(s.Results[i].Error) > 0 {
+				return nil, fmt.Errorf("query %q failed when the snapshot was captured: %v", query, s.Results[i].Error)
+			}
+			return &s.Results[i], nil
+		}
+	}
+
+	if querySelector, ok := parseSelector(query); ok {
+		for i := range s.Results {
+			capturedSelector, ok := parseSelector(s.Results[i].Query)
+			if !ok || !querySelector.narrows(capturedSelector) {
+				continue
+			}
+			if len(s.Results[i].Error) > 0 {
+				return nil, fmt.Errorf("query %q failed when the snapshot was captured: %v", s.Results[i].Query, s.Results[i].Error)
+			}
+			ret := &QueryResult{Name: s.Results[i].Name, Query: query, Step: s.Results[i].Step}
+			for _, series := range s.Results[i].Matrix {
+				if querySelector.matches(series.Metric) {
+					ret.Matrix = append(ret.Matrix, series)
+				}
+			}
+			return ret, nil
+		}
+	}
+	return nil, fmt.Errorf("query %q is not in the snapshot, the captured queries are %v", query, s.QueryNames())
+}
+
+// QueryNames returns the names of the captured queries.
+func (s *Snapshot) QueryNames() []string {
+	ret := []string{}
+	for _, result := range s.Results {
+		ret = append(ret, result.Name)
+	}
+	return ret
+}
+
+// QueryRange returns the captured samples of the query in the range.  The samples keep the resolution they were
+// captured with, a warning is returned when that is not the step that was asked for.
+func (s *Snapshot) QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error) {
+	result, err := s.result(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var warnings prometheusv1.Warnings
+	if r.Step != result.Step {
+		warnings = append(warnings, fmt.Sprintf("snapshot of %q has a step of %v, not %v", result.Name, result.Step, r.Step))
+	}
+	if r.Start.Before(s.Start) || r.End.After(s.End) {
+		warnings = append(warnings, fmt.Sprintf("snapshot only covers %v to %v", s.Start.UTC(), s.End.UTC()))
+	}
+
+	ret := prometheustypes.Matrix{}
+	for _, series := range result.Matrix {
+		values := []prometheustypes.SamplePair{}
+		for _, sample := range series.Values {
+			timestamp := sample.Timestamp.Time()
+			if timestamp.Before(r.Start) || timestamp.After(r.End) {
+				continue
+			}
+			values = append(values, sample)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		ret = append(ret, &prometheustypes.SampleStream{Metric: series.Metric, Values: values})
+	}
+	return ret, warnings, nil
+}
+
+// Query returns the latest captured sample of every series of the query at or before ts, as long as it is within the
+// lookback of prometheus.
+func (s *Snapshot) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error) {
+	result, err := s.result(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ts.IsZero() {
+		ts = s.End
+	}
+
+	var warnings prometheusv1.Warnings
+	if ts.Before(s.Start) || ts.After(s.End.Add(lookbackDelta)) {
+		warnings = append(warnings, fmt.Sprintf("snapshot only covers %v to %v", s.Start.UTC(), s.End.UTC()))
+	}
+
+	ret := prometheustypes.Vector{}
+	for _, series := range result.Matrix {
+		var latest *prometheustypes.SamplePair
+		for i := range series.Values {
+			timestamp := series.Values[i].Timestamp.Time()
+			if timestamp.After(ts) {
+				break
+			}
+			if ts.Sub(timestamp) <= lookbackDelta {
+				latest = &series.Values[i]
+			}
+		}
+		if latest == nil {
+			continue
+		}
+		ret = append(ret, &prometheustypes.Sample{
+			Metric:    series.Metric,
+			Value:     latest.Value,
+			Timestamp: prometheustypes.TimeFromUnixNano(ts.UnixNano()),
+		})
+	}
+	return ret, warnings, nil
+}
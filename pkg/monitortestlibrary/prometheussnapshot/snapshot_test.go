@@ -0,0 +1,178 @@
+package prometheussnapshot
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+// fakeQuerier returns a sample with the value of the minute for every step of the range.
+type fakeQuerier struct {
+	ranges []prometheusv1.Range
+}
+
+func (f *fakeQuerier) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error) {
+	return nil, nil, fmt.Errorf("not implemented")
+}
+
+func (f *fakeQuerier) QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error) {
+	if query == "broken" {
+		return nil, nil, fmt.Errorf("bad query")
+	}
+	f.ranges = append(f.ranges, r)
+	series := &prometheustypes.SampleStream{Metric: prometheustypes.Metric{"__name__": "up", "job": "kubelet"}}
+	for ts := r.Start; !ts.After(r.End); ts = ts.Add(r.Step) {
+		series.Values = append(series.Values, prometheustypes.SamplePair{
+			Timestamp: prometheustypes.TimeFromUnixNano(ts.UnixNano()),
+			Value:     prometheustypes.SampleValue(ts.Minute()),
+		})
+	}
+	return prometheustypes.Matrix{series}, nil, nil
+}
+
+func TestDefaultQueries(t *testing.T) {
+	if _, err := ParseSnapshotQueries(defaultQueriesYAML); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCaptureAndQuery(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(4 * time.Hour)
+	querier := &fakeQuerier{}
+
+	queries, err := ParseSnapshotQueries([]byte(`
+queries:
+- name: up
+  query: up
+  step: 1s
+- name: broken
+  query: broken
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := Capture(context.TODO(), querier, queries, start, end)
+
+	// four hours at a one second step does not fit in one query.
+	if len(querier.ranges) != 2 {
+		t.Errorf("expected the window to be split, got %v", querier.ranges)
+	}
+	if len(snapshot.Results[1].Error) == 0 {
+		t.Errorf("expected the broken query to be recorded as failed")
+	}
+
+	filename := filepath.Join(t.TempDir(), "snapshot.json.gz")
+	if err := WriteSnapshot(filename, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err = ReadSnapshot(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if points := len(snapshot.Results[0].Matrix[0].Values); points != 4*3600+1 {
+		t.Errorf("expected every point of the window, got %d", points)
+	}
+
+	value, warnings, err := snapshot.QueryRange(context.TODO(), "up", prometheusv1.Range{Start: start.Add(30 * time.Minute), End: start.Add(31 * time.Minute), Step: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+	if points := len(value.(prometheustypes.Matrix)[0].Values); points != 61 {
+		t.Errorf("expected 61 points in the range, got %d", points)
+	}
+
+	value, _, err = snapshot.Query(context.TODO(), "up", start.Add(45*time.Minute+500*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector := value.(prometheustypes.Vector)
+	if len(vector) != 1 || vector[0].Value != 45 {
+		t.Errorf("expected the sample at 10:45, got %v", vector)
+	}
+
+	if _, _, err := snapshot.Query(context.TODO(), "broken", end); err == nil {
+		t.Errorf("expected an error for a query that failed during capture")
+	}
+	if _, _, err := snapshot.Query(context.TODO(), "rate(up[5m])", end); err == nil {
+		t.Errorf("expected an error for a query that was not captured")
+	}
+	if names := snapshot.QueryNames(); !reflect.DeepEqual(names, []string{"up", "broken"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+}
+
+func TestQuerySelector(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	sample := prometheustypes.SamplePair{Timestamp: prometheustypes.TimeFromUnixNano(start.UnixNano()), Value: 1}
+	alert := func(alertName, alertState string) *prometheustypes.SampleStream {
+		return &prometheustypes.SampleStream{
+			Metric: prometheustypes.Metric{"__name__": "ALERTS", "alertname": prometheustypes.LabelValue(alertName), "alertstate": prometheustypes.LabelValue(alertState)},
+			Values: []prometheustypes.SamplePair{sample},
+		}
+	}
+	snapshot := &Snapshot{
+		Version: snapshotVersion,
+		Start:   start,
+		End:     start.Add(time.Hour),
+		Results: []QueryResult{
+			{
+				Name:  "alerts",
+				Query: "ALERTS",
+				Step:  defaultStep,
+				Matrix: prometheustypes.Matrix{
+					alert("Watchdog", "firing"),
+					alert("KubePodCrashLooping", "pending"),
+					alert("KubePodCrashLooping", "firing"),
+				},
+			},
+			{Name: "up", Query: "up == 0", Step: defaultStep},
+		},
+	}
+
+	tests := []struct {
+		query       string
+		expected    []string
+		expectedErr bool
+	}{
+		{query: "ALERTS", expected: []string{"Watchdog", "KubePodCrashLooping", "KubePodCrashLooping"}},
+		{query: `ALERTS{alertstate="firing"}`, expected: []string{"Watchdog", "KubePodCrashLooping"}},
+		{query: `ALERTS{alertstate="pending"}`, expected: []string{"KubePodCrashLooping"}},
+		{query: `ALERTS{alertstate=~"firing|pending", alertname!="Watchdog"}`, expected: []string{"KubePodCrashLooping", "KubePodCrashLooping"}},
+		{query: `ALERTS{alertname!~"Watch.*",alertstate="firing"}`, expected: []string{"KubePodCrashLooping"}},
+		{query: `ALERTS{alertname="Missing"}`, expected: []string{}},
+		{query: `up{job="kubelet"}`, expectedErr: true},
+		{query: `count(ALERTS{alertstate="firing"})`, expectedErr: true},
+		{query: `ALERTS{alertstate="firing"} == 1`, expectedErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			value, _, err := snapshot.Query(context.TODO(), test.query, start)
+			if test.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := []string{}
+			for _, sample := range value.(prometheustypes.Vector) {
+				actual = append(actual, string(sample.Metric["alertname"]))
+			}
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package disruptionoauthlogin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+const (
+	// authorizePath requests a token with the implicit grant of the challenging client, the same way oc login does for
+	// password based identity providers.
+	authorizePath = "/oauth/authorize?response_type=token&client_id=openshift-challenging-client"
+
+	whoamiPath         = "/apis/user.openshift.io/v1/users/~"
+	userOAuthTokenPath = "/apis/oauth.openshift.io/v1/useroauthaccesstokens/"
+
+	sha256Prefix = "sha256~"
+)
+
+// loginFlow is a backenddisruption.RequestFunc that logs in against the oauth-server, proves the token it got works by
+// asking the kube-apiserver who it belongs to and then logs out again so that we do not pile up a token per sample.
+// Every request of a sample carries the audit ID of the sample.
+type loginFlow struct {
+	username     string
+	password     string
+	apiServerURL string
+}
+
+func (l *loginFlow) check(ctx context.Context, httpClient *http.Client, authorizeURL, auditID string) error {
+	token, err := l.requestToken(ctx, httpClient, authorizeURL, auditID)
+	if err != nil {
+		return fmt.Errorf("failed to request token: %w", err)
+	}
+	// the token is already issued, failing to remove it is not a failure of the login flow.
+	defer l.logout(ctx, httpClient, token, auditID)
+
+	if err := l.whoami(ctx, httpClient, token, auditID); err != nil {
+		return fmt.Errorf("failed to validate token: %w", err)
+	}
+	return nil
+}
+
+func (l *loginFlow) requestToken(ctx context.Context, httpClient *http.Client, authorizeURL string, auditID string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, authorizeURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(audit.HeaderAuditID, auditID)
+	req.SetBasicAuth(l.username, l.password)
+	// the oauth-server refuses basic auth challenges without a CSRF header, any value will do.
+	req.Header.Set("X-CSRF-Token", "1")
+
+	// the token is handed back in the fragment of the redirect, so we must not follow it.
+	noRedirectClient := *httpClient
+	noRedirectClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := noRedirectClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	body, err := readAndClose(resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusFound {
+		return "", fmt.Errorf("expected a redirect with the token, got %v: %v", resp.Status, string(body))
+	}
+
+	location, err := resp.Location()
+	if err != nil {
+		return "", err
+	}
+	values, err := url.ParseQuery(location.Fragment)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse redirect %q: %w", location.Redacted(), err)
+	}
+	if oauthErr := values.Get("error"); len(oauthErr) > 0 {
+		return "", fmt.Errorf("%s: %s", oauthErr, values.Get("error_description"))
+	}
+	token := values.Get("access_token")
+	if len(token) == 0 {
+		return "", fmt.Errorf("redirect did not contain an access_token")
+	}
+	return token, nil
+}
+
+func (l *loginFlow) whoami(ctx context.Context, httpClient *http.Client, token string, auditID string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.apiServerURL+whoamiPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(audit.HeaderAuditID, auditID)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	body, err := readAndClose(resp)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error running request: %v: %v", resp.Status, string(body))
+	}
+
+	user := struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return err
+	}
+	if user.Metadata.Name != l.username {
+		return fmt.Errorf("token belongs to %q, expected %q", user.Metadata.Name, l.username)
+	}
+	return nil
+}
+
+func (l *loginFlow) logout(ctx context.Context, httpClient *http.Client, token string, auditID string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, l.apiServerURL+userOAuthTokenPath+tokenObjectName(token), nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set(audit.HeaderAuditID, auditID)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	_, _ = readAndClose(resp)
+}
+
+// tokenObjectName returns the name of the useroauthaccesstoken backing a sha256~ token.
+func tokenObjectName(token string) string {
+	if !strings.HasPrefix(token, sha256Prefix) {
+		return token
+	}
+	h := sha256.Sum256([]byte(strings.TrimPrefix(token, sha256Prefix)))
+	return sha256Prefix + base64.RawURLEncoding.EncodeToString(h[0:])
+}
+
+func readAndClose(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,107 @@
+package disruptionoauthlogin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+func newFakeOAuthServer(t *testing.T, issuedToken, tokenOwner string, deletedTokens *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if auditID := req.Header.Get(audit.HeaderAuditID); auditID != "audit-id" {
+			t.Errorf("expected the audit ID of the sample on %v %v, got %q", req.Method, req.URL, auditID)
+		}
+		switch {
+		case req.URL.Path == "/oauth/authorize":
+			username, password, ok := req.BasicAuth()
+			if !ok || username != "user" || password != "secret" || len(req.Header.Get("X-CSRF-Token")) == 0 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, req, "/oauth/token/implicit#access_token="+issuedToken+"&token_type=Bearer", http.StatusFound)
+
+		case req.URL.Path == whoamiPath:
+			if req.Header.Get("Authorization") != "Bearer "+issuedToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"kind":"User","metadata":{"name":%q}}`, tokenOwner)
+
+		case strings.HasPrefix(req.URL.Path, userOAuthTokenPath) && req.Method == http.MethodDelete:
+			*deletedTokens = append(*deletedTokens, strings.TrimPrefix(req.URL.Path, userOAuthTokenPath))
+
+		default:
+			t.Errorf("unexpected request %v %v", req.Method, req.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func Test_loginFlow_check(t *testing.T) {
+	tests := []struct {
+		name          string
+		password      string
+		tokenOwner    string
+		expectedError string
+		expectLogout  bool
+	}{
+		{
+			name:         "successful login",
+			password:     "secret",
+			tokenOwner:   "user",
+			expectLogout: true,
+		},
+		{
+			name:          "wrong password",
+			password:      "wrong",
+			tokenOwner:    "user",
+			expectedError: "failed to request token: expected a redirect with the token, got 401 Unauthorized",
+		},
+		{
+			name:          "token for another user",
+			password:      "secret",
+			tokenOwner:    "someone-else",
+			expectedError: `failed to validate token: token belongs to "someone-else", expected "user"`,
+			expectLogout:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deletedTokens := []string{}
+			server := newFakeOAuthServer(t, "sha256~abc", tt.tokenOwner, &deletedTokens)
+			defer server.Close()
+
+			login := &loginFlow{username: "user", password: tt.password, apiServerURL: server.URL}
+			err := login.check(context.TODO(), server.Client(), server.URL+authorizePath, "audit-id")
+			switch {
+			case len(tt.expectedError) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(tt.expectedError) > 0 && (err == nil || !strings.HasPrefix(err.Error(), tt.expectedError)):
+				t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+			}
+
+			if tt.expectLogout {
+				if len(deletedTokens) != 1 || deletedTokens[0] != tokenObjectName("sha256~abc") {
+					t.Errorf("expected the token to be deleted, got %v", deletedTokens)
+				}
+			} else if len(deletedTokens) != 0 {
+				t.Errorf("expected no token to be deleted, got %v", deletedTokens)
+			}
+		})
+	}
+}
+
+func Test_tokenObjectName(t *testing.T) {
+	// sha256("abc") in unpadded base64url
+	if actual, expected := tokenObjectName("sha256~abc"), "sha256~ungWv48Bz-pBQUDeXa4iI7ADYaOWF3qctBD_YfIAFa0"; actual != expected {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := tokenObjectName("legacy"); actual != "legacy" {
+		t.Errorf("expected legacy tokens to be used as their own name, got %v", actual)
+	}
+}
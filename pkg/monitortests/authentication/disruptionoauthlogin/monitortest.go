@@ -0,0 +1,360 @@
+package disruptionoauthlogin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned"
+	userclient "github.com/openshift/client-go/user/clientset/versioned"
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+const (
+	newConnectionTestName    = "[sig-auth] disruption/oauth-login connection/new should be available throughout the test"
+	reusedConnectionTestName = "[sig-auth] disruption/oauth-login connection/reused should be available throughout the test"
+
+	// these are the backend names used to store and look up the historical disruption of the login flow.
+	newConnectionBackendName    = "oauth-login-new-connections"
+	reusedConnectionBackendName = "oauth-login-reused-connections"
+
+	oauthNamespace = "openshift-authentication"
+	oauthRouteName = "oauth-openshift"
+
+	identityProviderName = "disruption-oauth-login"
+	htpasswdSecretName   = "disruption-oauth-login-htpasswd"
+	username             = "disruption-oauth-login"
+)
+
+type availability struct {
+	kubeClient   kubernetes.Interface
+	configClient configclient.Interface
+	userClient   userclient.Interface
+	oauthClient  oauthclient.Interface
+
+	// cleanupRequired is set as soon as we created anything on the cluster.
+	cleanupRequired bool
+
+	// stopWaiting stops waiting for the oauth-server to accept logins when the collection ends first.
+	stopWaiting context.CancelFunc
+
+	// lock protects the fields set once the oauth-server accepts logins, or the collection ended.
+	lock              sync.Mutex
+	collected         bool
+	startErr          error
+	disruptionChecker *disruptionlibrary.Availability
+
+	notSupportedReason error
+	suppressJunit      bool
+}
+
+// NewAvailabilityInvariant logs in as an htpasswd user against the oauth-server for the whole run.  The identity
+// provider and the user are created for the run and removed during cleanup.  Adding the identity provider rolls out
+// the oauth-server, so this is an opt-in monitor test.
+func NewAvailabilityInvariant() monitortestframework.MonitorTest {
+	return &availability{}
+}
+
+func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
+	return &availability{
+		suppressJunit: true,
+	}
+}
+
+func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	var err error
+
+	w.kubeClient, err = kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	w.configClient, err = configclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	w.userClient, err = userclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	w.oauthClient, err = oauthclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	infrastructure, err := w.configClient.ConfigV1().Infrastructures().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if infrastructure.Status.ControlPlaneTopology == configv1.ExternalTopologyMode {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: "identity providers cannot be customized on external control planes"}
+		return w.notSupportedReason
+	}
+	authentication, err := w.configClient.ConfigV1().Authentications().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if authType := authentication.Spec.Type; len(authType) > 0 && authType != configv1.AuthenticationTypeIntegratedOAuth {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: fmt.Sprintf("the integrated oauth-server is not used, authentication type is %v", authType)}
+		return w.notSupportedReason
+	}
+	if _, err := w.kubeClient.CoreV1().Namespaces().Get(ctx, oauthNamespace, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: fmt.Sprintf("namespace %s not present", oauthNamespace)}
+		return w.notSupportedReason
+	} else if err != nil {
+		return err
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		return err
+	}
+	if err := w.createIdentityProvider(ctx, password); err != nil {
+		return err
+	}
+
+	login := &loginFlow{
+		username:     username,
+		password:     password,
+		apiServerURL: apiServerURL(adminRESTConfig),
+	}
+	newConnectionDisruptionSampler := backenddisruption.NewRouteBackend(
+		adminRESTConfig, oauthNamespace, oauthRouteName, newConnectionBackendName, authorizePath, monitorapi.NewConnectionType).
+		WithRequestFunc(login.check)
+	reusedConnectionDisruptionSampler := backenddisruption.NewRouteBackend(
+		adminRESTConfig, oauthNamespace, oauthRouteName, reusedConnectionBackendName, authorizePath, monitorapi.ReusedConnectionType).
+		WithRequestFunc(login.check)
+
+	disruptionChecker := disruptionlibrary.NewAvailabilityInvariant(
+		newConnectionTestName, reusedConnectionTestName,
+		newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
+	)
+
+	// the authentication operator has to roll out the oauth-server with the new identity provider before anyone can
+	// log in, which is not a disruption we want to measure.  The other monitor tests must not wait for it.
+	var waitCtx context.Context
+	waitCtx, w.stopWaiting = context.WithCancel(ctx)
+	go w.startOnceLoginsAreAccepted(ctx, waitCtx, adminRESTConfig, recorder, disruptionChecker, newConnectionDisruptionSampler)
+
+	return nil
+}
+
+func (w *availability) startOnceLoginsAreAccepted(ctx, waitCtx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter, disruptionChecker *disruptionlibrary.Availability, sampler *backenddisruption.BackendSampler) {
+	klog.Infof("Waiting for the oauth-server to accept logins from identity provider %s", identityProviderName)
+	err := wait.PollUntilContextTimeout(waitCtx, 10*time.Second, 10*time.Minute, true, func(ctx context.Context) (bool, error) {
+		if _, err := sampler.CheckConnection(ctx); err != nil {
+			klog.Infof("oauth-server does not accept logins yet: %v", err)
+			return false, nil
+		}
+		return true, nil
+	})
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.collected {
+		return
+	}
+	if err != nil {
+		w.startErr = fmt.Errorf("oauth-server never accepted logins from identity provider %s: %w", identityProviderName, err)
+		return
+	}
+	if err := disruptionChecker.StartCollection(ctx, adminRESTConfig, recorder); err != nil {
+		w.startErr = err
+		return
+	}
+	w.disruptionChecker = disruptionChecker
+}
+
+func (w *availability) createIdentityProvider(ctx context.Context, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-config",
+			Name:      htpasswdSecretName,
+		},
+		Data: map[string][]byte{
+			configv1.HTPasswdDataKey: []byte(fmt.Sprintf("%s:%s\n", username, hash)),
+		},
+	}
+	_, err = w.kubeClient.CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// left behind by an earlier run that did not clean up, the password is different now.
+		_, err = w.kubeClient.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("unable to create htpasswd secret: %w", err)
+	}
+	w.cleanupRequired = true
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		oauth, err := w.configClient.ConfigV1().OAuths().Get(ctx, "cluster", metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		for _, identityProvider := range oauth.Spec.IdentityProviders {
+			if identityProvider.Name == identityProviderName {
+				return nil
+			}
+		}
+		oauth.Spec.IdentityProviders = append(oauth.Spec.IdentityProviders, configv1.IdentityProvider{
+			Name:          identityProviderName,
+			MappingMethod: configv1.MappingMethodClaim,
+			IdentityProviderConfig: configv1.IdentityProviderConfig{
+				Type: configv1.IdentityProviderTypeHTPasswd,
+				HTPasswd: &configv1.HTPasswdIdentityProvider{
+					FileData: configv1.SecretNameReference{Name: htpasswdSecretName},
+				},
+			},
+		})
+		_, err = w.configClient.ConfigV1().OAuths().Update(ctx, oauth, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add identity provider %s: %w", identityProviderName, err)
+	}
+	return nil
+}
+
+func (w *availability) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, nil, w.notSupportedReason
+	}
+	// we failed and indicated it during setup.
+	if w.stopWaiting == nil {
+		return nil, nil, nil
+	}
+
+	w.stopWaiting()
+	w.lock.Lock()
+	w.collected = true
+	disruptionChecker, startErr := w.disruptionChecker, w.startErr
+	w.lock.Unlock()
+	if startErr != nil {
+		return nil, nil, startErr
+	}
+	if disruptionChecker == nil {
+		return nil, nil, fmt.Errorf("oauth-server did not accept logins from identity provider %s before the end of the run", identityProviderName)
+	}
+
+	return disruptionChecker.CollectData(ctx)
+}
+
+func (*availability) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (w *availability) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+	if w.suppressJunit {
+		return nil, nil
+	}
+	w.lock.Lock()
+	disruptionChecker := w.disruptionChecker
+	w.lock.Unlock()
+	// we failed and indicated it during setup or collection.
+	if disruptionChecker == nil {
+		return nil, nil
+	}
+
+	return disruptionChecker.EvaluateTestsFromConstructedIntervals(ctx, finalIntervals)
+}
+
+func (w *availability) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return w.notSupportedReason
+}
+
+// Cleanup removes the identity provider, which rolls out the oauth-server again, and everything the logins created.
+// Removal continues past failures so that as little as possible is left behind.
+func (w *availability) Cleanup(ctx context.Context) error {
+	if !w.cleanupRequired {
+		return w.notSupportedReason
+	}
+
+	errs := []error{}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		oauth, err := w.configClient.ConfigV1().OAuths().Get(ctx, "cluster", metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		identityProviders := []configv1.IdentityProvider{}
+		for _, identityProvider := range oauth.Spec.IdentityProviders {
+			if identityProvider.Name != identityProviderName {
+				identityProviders = append(identityProviders, identityProvider)
+			}
+		}
+		if len(identityProviders) == len(oauth.Spec.IdentityProviders) {
+			return nil
+		}
+		oauth.Spec.IdentityProviders = identityProviders
+		_, err = w.configClient.ConfigV1().OAuths().Update(ctx, oauth, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove identity provider %s: %w", identityProviderName, err))
+	}
+
+	err = w.kubeClient.CoreV1().Secrets("openshift-config").Delete(ctx, htpasswdSecretName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		errs = append(errs, fmt.Errorf("failed to delete secret: %w", err))
+	}
+
+	err = w.oauthClient.OauthV1().OAuthAccessTokens().DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("userName", username).String(),
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		errs = append(errs, fmt.Errorf("failed to delete access tokens: %w", err))
+	}
+
+	err = w.userClient.UserV1().Identities().Delete(ctx, fmt.Sprintf("%s:%s", identityProviderName, username), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		errs = append(errs, fmt.Errorf("failed to delete identity: %w", err))
+	}
+	err = w.userClient.UserV1().Users().Delete(ctx, username, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		errs = append(errs, fmt.Errorf("failed to delete user: %w", err))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func apiServerURL(config *rest.Config) string {
+	host := strings.TrimSuffix(config.Host, "/")
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	return host
+}
@@ -0,0 +1,109 @@
+package prometheusrulereferences
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/alertreferences"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	staleReferencesTestName  = "[sig-instrumentation] alert allowances and tests should reference alerting rules that exist on the cluster"
+	criticalCoverageTestName = "[sig-instrumentation] critical alerts should have alert tests"
+)
+
+type prometheusRuleReferences struct {
+	adminRESTConfig    *rest.Config
+	report             *alertreferences.Report
+	notSupportedReason error
+}
+
+// NewPrometheusRuleReferences checks the alerts origin allows and tests against the alerting rules of the cluster, so
+// that renamed and removed alerts do not linger in the allowances and critical alerts without tests are visible.
+func NewPrometheusRuleReferences() monitortestframework.MonitorTest {
+	return &prometheusRuleReferences{}
+}
+
+func (w *prometheusRuleReferences) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	w.adminRESTConfig = adminRESTConfig
+	return nil
+}
+
+func (w *prometheusRuleReferences) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	dynamicClient, err := dynamic.NewForConfig(w.adminRESTConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the rules are read at the end of the run so that upgrades are checked against the rules of the new version.
+	rules, err := alertreferences.ListAlertRules(ctx, dynamicClient)
+	if apierrors.IsNotFound(err) {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: "cluster does not serve PrometheusRules"}
+		return nil, nil, w.notSupportedReason
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to list PrometheusRules: %w", err)
+	}
+
+	report := alertreferences.Check(rules, alertreferences.OriginReferences())
+	w.report = &report
+	return nil, nil, nil
+}
+
+func (w *prometheusRuleReferences) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, w.notSupportedReason
+}
+
+func (w *prometheusRuleReferences) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+	if w.report == nil {
+		return nil, nil
+	}
+
+	ret := []*junitapi.JUnitTestCase{}
+	// allowances are shared by every platform and alerts differ between them, an alert missing from this cluster may
+	// exist on another platform, so stale references are only reported for the owners to review.
+	stale := w.report.StaleReferenceStrings()
+	ret = append(ret, &junitapi.JUnitTestCase{
+		Name: staleReferencesTestName,
+		SystemOut: fmt.Sprintf("%d references to alerts without an alerting rule on this cluster, remove them if the alert was renamed or removed on every platform:\n%s",
+			len(stale), strings.Join(stale, "\n")),
+	})
+
+	uncovered := w.report.UncoveredCriticalAlertStrings()
+	ret = append(ret, &junitapi.JUnitTestCase{
+		Name:      criticalCoverageTestName,
+		SystemOut: fmt.Sprintf("%d critical alerts without an alert test:\n%s", len(uncovered), strings.Join(uncovered, "\n")),
+	})
+	return ret, nil
+}
+
+func (w *prometheusRuleReferences) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	if w.notSupportedReason != nil {
+		return w.notSupportedReason
+	}
+	if w.report == nil {
+		return nil
+	}
+	jsonContent, err := json.MarshalIndent(w.report, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(storageDir, fmt.Sprintf("alert-references%s.json", timeSuffix)), jsonContent, 0644)
+}
+
+func (w *prometheusRuleReferences) Cleanup(ctx context.Context) error {
+	return nil
+}
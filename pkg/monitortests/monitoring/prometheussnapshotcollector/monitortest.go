@@ -0,0 +1,98 @@
+package prometheussnapshotcollector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	routeclient "github.com/openshift/client-go/route/clientset/versioned"
+	"github.com/openshift/library-go/test/library/metrics"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/prometheussnapshot"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type prometheusSnapshotCollector struct {
+	adminRESTConfig    *rest.Config
+	queries            []prometheussnapshot.SnapshotQuery
+	snapshot           *prometheussnapshot.Snapshot
+	notSupportedReason error
+}
+
+// NewPrometheusSnapshotCollector captures the series selected by PROMETHEUS_SNAPSHOT_QUERIES over the run, so that
+// metrics can still be looked at after the cluster is gone.
+func NewPrometheusSnapshotCollector() monitortestframework.MonitorTest {
+	return &prometheusSnapshotCollector{}
+}
+
+func (w *prometheusSnapshotCollector) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	w.adminRESTConfig = adminRESTConfig
+
+	queries, err := prometheussnapshot.LoadSnapshotQueries()
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{
+			Reason: fmt.Sprintf("set %s to capture a prometheus snapshot", prometheussnapshot.QueriesEnvVar),
+		}
+		return w.notSupportedReason
+	}
+	w.queries = queries
+	return nil
+}
+
+func (w *prometheusSnapshotCollector) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, nil, w.notSupportedReason
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(w.adminRESTConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	routeClient, err := routeclient.NewForConfig(w.adminRESTConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = kubeClient.CoreV1().Namespaces().Get(ctx, "openshift-monitoring", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: "cluster has no openshift-monitoring"}
+		return nil, nil, w.notSupportedReason
+	}
+	prometheusClient, err := metrics.NewPrometheusClient(ctx, kubeClient, routeClient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.snapshot = prometheussnapshot.Capture(ctx, prometheusClient, w.queries, beginning, end)
+	return nil, nil, nil
+}
+
+func (w *prometheusSnapshotCollector) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, w.notSupportedReason
+}
+
+func (w *prometheusSnapshotCollector) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return nil, w.notSupportedReason
+}
+
+func (w *prometheusSnapshotCollector) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	if w.notSupportedReason != nil {
+		return w.notSupportedReason
+	}
+	if w.snapshot == nil {
+		return nil
+	}
+	return prometheussnapshot.WriteSnapshot(filepath.Join(storageDir, fmt.Sprintf("prometheus-snapshot%s.json.gz", timeSuffix)), w.snapshot)
+}
+
+func (w *prometheusSnapshotCollector) Cleanup(ctx context.Context) error {
+	return nil
+}
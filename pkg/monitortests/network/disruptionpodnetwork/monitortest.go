@@ -18,10 +18,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/selection"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
@@ -50,6 +53,12 @@ var (
 	podNetworkTargetService                  *corev1.Service
 	hostNetworkTargetDeployment              *appsv1.Deployment
 	hostNetworkTargetService                 *corev1.Service
+	pollerMetricsService                     *corev1.Service
+	pollerServiceMonitor                     *unstructured.Unstructured
+	prometheusRole                           *rbacv1.Role
+	prometheusRoleBinding                    *rbacv1.RoleBinding
+
+	serviceMonitorGVR = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"}
 )
 
 func yamlOrDie(name string) []byte {
@@ -74,6 +83,10 @@ func init() {
 	podNetworkTargetService = resourceread.ReadServiceV1OrDie(yamlOrDie("pod-network-target-service.yaml"))
 	hostNetworkTargetDeployment = resourceread.ReadDeploymentV1OrDie(yamlOrDie("host-network-target-deployment.yaml"))
 	hostNetworkTargetService = resourceread.ReadServiceV1OrDie(yamlOrDie("host-network-target-service.yaml"))
+	pollerMetricsService = resourceread.ReadServiceV1OrDie(yamlOrDie("poller-metrics-service.yaml"))
+	pollerServiceMonitor = resourceread.ReadUnstructuredOrDie(yamlOrDie("poller-servicemonitor.yaml"))
+	prometheusRole = resourceread.ReadRoleV1OrDie(yamlOrDie("prometheus-role.yaml"))
+	prometheusRoleBinding = resourceread.ReadRoleBindingV1OrDie(yamlOrDie("prometheus-rolebinding.yaml"))
 }
 
 type podNetworkAvalibility struct {
@@ -126,6 +139,10 @@ func (pna *podNetworkAvalibility) StartCollection(ctx context.Context, adminREST
 		return err
 	}
 
+	if err := pna.createPollerMetricsScraping(ctx, adminRESTConfig); err != nil {
+		return err
+	}
+
 	// our pods tolerate masters, so create one for each of them.
 	nodes, err := pna.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -198,6 +215,32 @@ func (pna *podNetworkAvalibility) StartCollection(ctx context.Context, adminREST
 	return nil
 }
 
+// createPollerMetricsScraping exposes the /metrics endpoints of the pollers to the cluster monitoring stack, so that
+// disruption can be observed continuously on long-lived clusters, not only from the intervals collected at the end.
+func (pna *podNetworkAvalibility) createPollerMetricsScraping(ctx context.Context, adminRESTConfig *rest.Config) error {
+	if _, err := pna.kubeClient.CoreV1().Services(pna.namespaceName).Create(ctx, pollerMetricsService, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	if _, err := pna.kubeClient.RbacV1().Roles(pna.namespaceName).Create(ctx, prometheusRole, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	if _, err := pna.kubeClient.RbacV1().RoleBindings(pna.namespaceName).Create(ctx, prometheusRoleBinding, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	_, err = dynamicClient.Resource(serviceMonitorGVR).Namespace(pna.namespaceName).Create(ctx, pollerServiceMonitor, metav1.CreateOptions{})
+	if apierrors.IsNotFound(err) {
+		// not every cluster has the monitoring stack installed.  The pollers still serve metrics, we just can't scrape them.
+		klog.Infof("Unable to create the disruption poller ServiceMonitor, monitoring.coreos.com is not available: %v", err)
+		return nil
+	}
+	return err
+}
+
 func (pna *podNetworkAvalibility) serviceHasEndpoints(ctx context.Context) (bool, error) {
 	targetServiceLabel, err := labels.NewRequirement("kubernetes.io/service-name", selection.Equals, []string{pna.targetService.Name})
 	if err != nil {
@@ -0,0 +1,301 @@
+package alertanalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prometheustypes "github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxDetailedFirings limits how many firing intervals we query the expression for, so that a run with an alert
+	// storm does not spend the end of the run querying prometheus.
+	maxDetailedFirings = 200
+	// maxSamplesPerFiring limits the resolution of the expression values in the artifact.
+	maxSamplesPerFiring = 60
+
+	alertDetailsDir = "alert-details"
+)
+
+// AlertDetails is what we know about why an alert fired, written to one artifact per alert.
+type AlertDetails struct {
+	AlertName string             `json:"alertName"`
+	Rules     []AlertRuleDetails `json:"rules"`
+	Firings   []AlertFiring      `json:"firings"`
+}
+
+// AlertRuleDetails describes an alerting rule with the name of the alert.  There may be more than one, for instance
+// one per severity.
+type AlertRuleDetails struct {
+	Group       string            `json:"group"`
+	Expression  string            `json:"expression"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Severity    string            `json:"severity,omitempty"`
+	RunbookURL  string            `json:"runbookURL,omitempty"`
+	Summary     string            `json:"summary,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// AlertFiring is one firing interval of the alert with the value of the expression of the rule during the interval.
+type AlertFiring struct {
+	Labels     map[string]string `json:"labels"`
+	From       time.Time         `json:"from"`
+	To         time.Time         `json:"to"`
+	Expression string            `json:"expression,omitempty"`
+	// Values of the series of the expression that produced the alert.
+	Values []AlertExpressionSample `json:"values,omitempty"`
+	// Error is set when the expression could not be evaluated over the interval.
+	Error string `json:"error,omitempty"`
+}
+
+type AlertExpressionSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+type alertingRule struct {
+	group string
+	rule  prometheusv1.AlertingRule
+}
+
+func (r alertingRule) toDetails() AlertRuleDetails {
+	ret := AlertRuleDetails{
+		Group:       r.group,
+		Expression:  r.rule.Query,
+		Labels:      map[string]string{},
+		Severity:    string(r.rule.Labels["severity"]),
+		RunbookURL:  string(r.rule.Annotations["runbook_url"]),
+		Summary:     string(r.rule.Annotations["summary"]),
+		Description: string(r.rule.Annotations["description"]),
+	}
+	if r.rule.Duration > 0 {
+		ret.For = (time.Duration(r.rule.Duration) * time.Second).String()
+	}
+	for k, v := range r.rule.Labels {
+		ret.Labels[string(k)] = string(v)
+	}
+	return ret
+}
+
+// alertingRulesByName indexes the alerting rules by the name of the alert they produce.
+func alertingRulesByName(rules prometheusv1.RulesResult) map[string][]alertingRule {
+	ret := map[string][]alertingRule{}
+	for _, group := range rules.Groups {
+		for _, rule := range group.Rules {
+			alertRule, ok := rule.(prometheusv1.AlertingRule)
+			if !ok {
+				continue
+			}
+			ret[alertRule.Name] = append(ret[alertRule.Name], alertingRule{group: group.Name, rule: alertRule})
+		}
+	}
+	return ret
+}
+
+// ruleForAlert returns the rule that produced the alert.  Every label of a rule ends up on its alerts, so the rule is
+// the one whose labels the alert has.
+func ruleForAlert(rules []alertingRule, alertLabels prometheustypes.Metric) *alertingRule {
+	for i := range rules {
+		matches := true
+		for k, v := range rules[i].rule.Labels {
+			if alertLabels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// seriesForAlert returns the series of the expression that produced the alert.  Alerts carry every label of the
+// series of the expression, plus the labels of the rule.
+func seriesForAlert(value prometheustypes.Value, alertLabels prometheustypes.Metric) *prometheustypes.SampleStream {
+	matrix, ok := value.(prometheustypes.Matrix)
+	if !ok {
+		return nil
+	}
+	for _, series := range matrix {
+		matches := true
+		for k, v := range series.Metric {
+			if k == prometheustypes.MetricNameLabel {
+				continue
+			}
+			if alertLabels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return series
+		}
+	}
+	return nil
+}
+
+// summarizeSamples is the short form of the values we show on the timeline.
+func summarizeSamples(samples []AlertExpressionSample) string {
+	if len(samples) == 0 {
+		return ""
+	}
+	minValue, maxValue := samples[0].Value, samples[0].Value
+	for _, sample := range samples {
+		if sample.Value < minValue {
+			minValue = sample.Value
+		}
+		if sample.Value > maxValue {
+			maxValue = sample.Value
+		}
+	}
+	formatValue := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', 4, 64)
+	}
+	return fmt.Sprintf("min=%s max=%s last=%s", formatValue(minValue), formatValue(maxValue), formatValue(samples[len(samples)-1].Value))
+}
+
+type rangeQuerier interface {
+	QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error)
+}
+
+// describeFiringAlerts adds the expression, runbook and value of the expression to the firing alert intervals and
+// collects the same information per alert for the artifacts.  firingAlerts are the series the intervals were created
+// from, the human message of an alert interval is the label set of its series.
+func describeFiringAlerts(ctx context.Context, client rangeQuerier, rules map[string][]alertingRule, firingAlerts prometheustypes.Value, intervals []monitorapi.Interval) ([]monitorapi.Interval, map[string]*AlertDetails) {
+	alertLabelsByMessage := map[string]prometheustypes.Metric{}
+	if matrix, ok := firingAlerts.(prometheustypes.Matrix); ok {
+		for _, alert := range matrix {
+			alertLabelsByMessage[alert.Metric.String()] = alert.Metric
+		}
+	}
+
+	details := map[string]*AlertDetails{}
+	ret := make([]monitorapi.Interval, 0, len(intervals))
+	queried := 0
+	for _, interval := range intervals {
+		alertLabels, ok := alertLabelsByMessage[interval.Message.HumanMessage]
+		if !ok {
+			ret = append(ret, interval)
+			continue
+		}
+		alertName := string(alertLabels[prometheustypes.AlertNameLabel])
+		alertRules := rules[alertName]
+		if _, ok := details[alertName]; !ok {
+			details[alertName] = &AlertDetails{AlertName: alertName, Rules: []AlertRuleDetails{}, Firings: []AlertFiring{}}
+			for _, rule := range alertRules {
+				details[alertName].Rules = append(details[alertName].Rules, rule.toDetails())
+			}
+		}
+
+		firing := AlertFiring{
+			Labels: map[string]string{},
+			From:   interval.From,
+			To:     interval.To,
+		}
+		for k, v := range alertLabels {
+			firing.Labels[string(k)] = string(v)
+		}
+
+		// intervals built from the same series share their annotations.
+		annotations := map[monitorapi.AnnotationKey]string{}
+		for k, v := range interval.Message.Annotations {
+			annotations[k] = v
+		}
+		interval.Message.Annotations = annotations
+
+		rule := ruleForAlert(alertRules, alertLabels)
+		switch {
+		case rule == nil:
+			firing.Error = "no alerting rule found for the alert"
+		case queried >= maxDetailedFirings:
+			firing.Expression = rule.rule.Query
+			firing.Error = fmt.Sprintf("skipped, only the first %d firing alerts are evaluated", maxDetailedFirings)
+		default:
+			queried++
+			firing.Expression = rule.rule.Query
+			firing.Values, firing.Error = expressionValues(ctx, client, rule.rule.Query, alertLabels, interval.From, interval.To)
+		}
+
+		if rule != nil {
+			annotations[monitorapi.AnnotationAlertExpression] = rule.rule.Query
+			if runbookURL := string(rule.rule.Annotations["runbook_url"]); len(runbookURL) > 0 {
+				annotations[monitorapi.AnnotationRunbookURL] = runbookURL
+			}
+			if _, ok := annotations[monitorapi.AnnotationSeverity]; !ok && len(rule.rule.Labels["severity"]) > 0 {
+				annotations[monitorapi.AnnotationSeverity] = string(rule.rule.Labels["severity"])
+			}
+		}
+		if summary := summarizeSamples(firing.Values); len(summary) > 0 {
+			annotations[monitorapi.AnnotationAlertExpressionValue] = summary
+		}
+
+		details[alertName].Firings = append(details[alertName].Firings, firing)
+		ret = append(ret, interval)
+	}
+
+	return ret, details
+}
+
+// expressionValues evaluates the expression over the firing interval and returns the values of the series that
+// produced the alert.  Failures are returned as a message for the artifact, they do not fail the collection.
+func expressionValues(ctx context.Context, client rangeQuerier, expression string, alertLabels prometheustypes.Metric, from, to time.Time) ([]AlertExpressionSample, string) {
+	step := to.Sub(from) / maxSamplesPerFiring
+	if step < 2*time.Second {
+		step = 2 * time.Second
+	}
+	value, warnings, err := client.QueryRange(ctx, expression, prometheusv1.Range{Start: from, End: to, Step: step})
+	if err != nil {
+		return nil, err.Error()
+	}
+	if len(warnings) > 0 {
+		logrus.Warnf("warnings evaluating %q: %v", expression, strings.Join(warnings, ", "))
+	}
+	series := seriesForAlert(value, alertLabels)
+	if series == nil {
+		return nil, "no series of the expression matches the alert"
+	}
+	ret := []AlertExpressionSample{}
+	for _, sample := range series.Values {
+		ret = append(ret, AlertExpressionSample{Timestamp: sample.Timestamp.Time(), Value: float64(sample.Value)})
+	}
+	return ret, ""
+}
+
+// writeAlertDetails writes an artifact per alert into the alert-details directory.
+func writeAlertDetails(artifactDir, timeSuffix string, details map[string]*AlertDetails) error {
+	if len(details) == 0 {
+		return nil
+	}
+	dir := filepath.Join(artifactDir, alertDetailsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	alertNames := []string{}
+	for alertName := range details {
+		alertNames = append(alertNames, alertName)
+	}
+	sort.Strings(alertNames)
+	for _, alertName := range alertNames {
+		jsonContent, err := json.MarshalIndent(details[alertName], "", "    ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%s%s.json", alertName, timeSuffix)), jsonContent, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package alertanalyzer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prometheustypes "github.com/prometheus/common/model"
+)
+
+type fakeRangeQuerier struct {
+	results map[string]prometheustypes.Value
+}
+
+func (f *fakeRangeQuerier) QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error) {
+	result, ok := f.results[query]
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected query %q", query)
+	}
+	return result, nil, nil
+}
+
+func Test_describeFiringAlerts(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	sample := func(offset time.Duration, value float64) prometheustypes.SamplePair {
+		return prometheustypes.SamplePair{Timestamp: prometheustypes.TimeFromUnixNano(start.Add(offset).UnixNano()), Value: prometheustypes.SampleValue(value)}
+	}
+
+	rules := alertingRulesByName(prometheusv1.RulesResult{
+		Groups: []prometheusv1.RuleGroup{
+			{
+				Name: "kube-apiserver",
+				Rules: prometheusv1.Rules{
+					prometheusv1.RecordingRule{Name: "apiserver:errors:rate5m", Query: "rate(apiserver_errors[5m])"},
+					prometheusv1.AlertingRule{
+						Name:        "KubeAPIErrorBudgetBurn",
+						Query:       "apiserver:errors:rate5m > 0.1",
+						Labels:      prometheustypes.LabelSet{"severity": "warning"},
+						Annotations: prometheustypes.LabelSet{"runbook_url": "https://example.com/warning"},
+					},
+					prometheusv1.AlertingRule{
+						Name:        "KubeAPIErrorBudgetBurn",
+						Query:       "apiserver:errors:rate5m > 0.5",
+						Labels:      prometheustypes.LabelSet{"severity": "critical"},
+						Annotations: prometheustypes.LabelSet{"runbook_url": "https://example.com/critical"},
+					},
+				},
+			},
+		},
+	})
+
+	alertLabels := prometheustypes.Metric{"alertname": "KubeAPIErrorBudgetBurn", "alertstate": "firing", "severity": "critical", "namespace": "openshift-kube-apiserver"}
+	unknownAlertLabels := prometheustypes.Metric{"alertname": "Unknown", "alertstate": "firing", "severity": "warning"}
+	firingAlerts := prometheustypes.Matrix{
+		{Metric: alertLabels, Values: []prometheustypes.SamplePair{sample(0, 1), sample(2*time.Second, 1)}},
+		{Metric: unknownAlertLabels, Values: []prometheustypes.SamplePair{sample(0, 1)}},
+	}
+	intervals, err := createEventIntervalsForAlerts(context.TODO(), firingAlerts, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &fakeRangeQuerier{
+		results: map[string]prometheustypes.Value{
+			"apiserver:errors:rate5m > 0.5": prometheustypes.Matrix{
+				{
+					Metric: prometheustypes.Metric{"namespace": "openshift-other"},
+					Values: []prometheustypes.SamplePair{sample(0, 10)},
+				},
+				{
+					Metric: prometheustypes.Metric{"__name__": "apiserver:errors:rate5m", "namespace": "openshift-kube-apiserver"},
+					Values: []prometheustypes.SamplePair{sample(0, 0.75), sample(2*time.Second, 0.5)},
+				},
+			},
+		},
+	}
+	described, details := describeFiringAlerts(context.TODO(), client, rules, firingAlerts, intervals)
+	if len(described) != len(intervals) {
+		t.Fatalf("expected %d intervals, got %d", len(intervals), len(described))
+	}
+
+	expectedAnnotations := map[monitorapi.AnnotationKey]string{
+		monitorapi.AnnotationAlertExpression:      "apiserver:errors:rate5m > 0.5",
+		monitorapi.AnnotationRunbookURL:           "https://example.com/critical",
+		monitorapi.AnnotationAlertExpressionValue: "min=0.5 max=0.75 last=0.5",
+		monitorapi.AnnotationSeverity:             "critical",
+	}
+	var budgetBurnInterval *monitorapi.Interval
+	for i := range described {
+		if described[i].Locator.Keys[monitorapi.LocatorAlertKey] == "KubeAPIErrorBudgetBurn" {
+			budgetBurnInterval = &described[i]
+		}
+	}
+	if budgetBurnInterval == nil {
+		t.Fatalf("missing interval for KubeAPIErrorBudgetBurn in %v", described)
+	}
+	for k, v := range expectedAnnotations {
+		if actual := budgetBurnInterval.Message.Annotations[k]; actual != v {
+			t.Errorf("expected annotation %v=%q, got %q", k, v, actual)
+		}
+	}
+
+	if len(details) != 2 {
+		t.Fatalf("expected details for two alerts, got %v", details)
+	}
+	budgetBurn := details["KubeAPIErrorBudgetBurn"]
+	if len(budgetBurn.Rules) != 2 || len(budgetBurn.Firings) != 1 {
+		t.Fatalf("expected two rules and one firing, got %#v", budgetBurn)
+	}
+	if firing := budgetBurn.Firings[0]; len(firing.Values) != 2 || len(firing.Error) > 0 {
+		t.Errorf("expected the values of the matching series, got %#v", firing)
+	}
+	if firing := details["Unknown"].Firings[0]; len(firing.Error) == 0 {
+		t.Errorf("expected an error for an alert without a rule, got %#v", firing)
+	}
+}
@@ -21,24 +21,26 @@ import (
 	"k8s.io/client-go/rest"
 )
 
-func fetchEventIntervalsForAllAlerts(ctx context.Context, restConfig *rest.Config, startTime time.Time) ([]monitorapi.Interval, error) {
+// fetchEventIntervalsForAllAlerts returns the intervals for every pending and firing alert, and the details of the
+// firing alerts by alert name.
+func fetchEventIntervalsForAllAlerts(ctx context.Context, restConfig *rest.Config, startTime time.Time) ([]monitorapi.Interval, map[string]*AlertDetails, error) {
 	kubeClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	routeClient, err := routeclient.NewForConfig(restConfig)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	_, err = kubeClient.CoreV1().Namespaces().Get(ctx, "openshift-monitoring", metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
-		return []monitorapi.Interval{}, nil
+		return []monitorapi.Interval{}, nil, nil
 	}
 
 	prometheusClient, err := metrics.NewPrometheusClient(ctx, kubeClient, routeClient)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Ensure that all Thanos queriers are connected to all Prometheus sidecars
@@ -66,7 +68,7 @@ func fetchEventIntervalsForAllAlerts(ctx context.Context, restConfig *rest.Confi
 
 		return true, nil
 	}); err != nil {
-		return nil, fmt.Errorf("Thanos queriers not connected to all Prometheus sidecars: %w", err)
+		return nil, nil, fmt.Errorf("Thanos queriers not connected to all Prometheus sidecars: %w", err)
 	}
 
 	timeRange := prometheusv1.Range{
@@ -74,9 +76,25 @@ func fetchEventIntervalsForAllAlerts(ctx context.Context, restConfig *rest.Confi
 		End:   time.Now(),
 		Step:  2 * time.Second,
 	}
-	alerts, warningsForQuery, err := prometheusClient.QueryRange(ctx, `ALERTS{alertstate="firing"}`, timeRange)
+	// the details help debugging, but we still want the alert intervals when we cannot get them.
+	var rules map[string][]alertingRule
+	rulesResult, err := prometheusClient.Rules(ctx)
 	if err != nil {
-		return nil, err
+		logrus.WithError(err).Warning("unable to get the alerting rules, firing alerts will not have details")
+	} else {
+		rules = alertingRulesByName(rulesResult)
+	}
+	return alertIntervals(ctx, prometheusClient, rules, timeRange)
+}
+
+// alertIntervals returns the intervals for every pending and firing alert in the range, and the details of the firing
+// alerts by alert name when the rules are known.  Only range queries of ALERTS are needed without the rules, so that a
+// prometheussnapshot.Snapshot can stand in for prometheus.
+func alertIntervals(ctx context.Context, client rangeQuerier, rules map[string][]alertingRule, timeRange prometheusv1.Range) ([]monitorapi.Interval, map[string]*AlertDetails, error) {
+	startTime := timeRange.Start
+	alerts, warningsForQuery, err := client.QueryRange(ctx, `ALERTS{alertstate="firing"}`, timeRange)
+	if err != nil {
+		return nil, nil, err
 	}
 	if len(warningsForQuery) > 0 {
 		fmt.Printf("#### warnings \n\t%v\n", strings.Join(warningsForQuery, "\n\t"))
@@ -84,19 +102,24 @@ func fetchEventIntervalsForAllAlerts(ctx context.Context, restConfig *rest.Confi
 
 	firingAlerts, err := createEventIntervalsForAlerts(ctx, alerts, startTime)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	alerts, warningsForQuery, err = prometheusClient.QueryRange(ctx, `ALERTS{alertstate="pending"}`, timeRange)
+	var alertDetails map[string]*AlertDetails
+	if rules != nil {
+		firingAlerts, alertDetails = describeFiringAlerts(ctx, client, rules, alerts, firingAlerts)
+	}
+
+	alerts, warningsForQuery, err = client.QueryRange(ctx, `ALERTS{alertstate="pending"}`, timeRange)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(warningsForQuery) > 0 {
 		fmt.Printf("#### warnings \n\t%v\n", strings.Join(warningsForQuery, "\n\t"))
 	}
 	pendingAlerts, err := createEventIntervalsForAlerts(ctx, alerts, startTime)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// firing alerts trump pending alerts, so if the alerts will overlap when we render, then we want to have pending
@@ -107,7 +130,7 @@ func fetchEventIntervalsForAllAlerts(ctx context.Context, restConfig *rest.Confi
 	ret = append(ret, firingAlerts...)
 	ret = append(ret, pendingAlerts...)
 
-	return ret, nil
+	return ret, alertDetails, nil
 }
 
 // blackoutEvents filters startingEvents and rewrites into potentially multiple events to avoid overlap with the blackoutWindows.
@@ -1,12 +1,18 @@
 package alertanalyzer
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/prometheussnapshot"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	prometheustypes "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_nonOverlappingBlackoutWindowsFromEvents(t *testing.T) {
@@ -344,3 +350,76 @@ func Test_blackoutEvents(t *testing.T) {
 		})
 	}
 }
+
+// fakeAlertsQuerier answers the range queries of ALERTS from the samples of its series, and nothing for any other
+// query.
+type fakeAlertsQuerier struct {
+	series prometheustypes.Matrix
+}
+
+func (f *fakeAlertsQuerier) Query(ctx context.Context, query string, ts time.Time, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error) {
+	return nil, nil, fmt.Errorf("not implemented")
+}
+
+func (f *fakeAlertsQuerier) QueryRange(ctx context.Context, query string, r prometheusv1.Range, opts ...prometheusv1.Option) (prometheustypes.Value, prometheusv1.Warnings, error) {
+	alertStates := map[string]string{
+		`ALERTS`:                       "",
+		`ALERTS{alertstate="firing"}`:  "firing",
+		`ALERTS{alertstate="pending"}`: "pending",
+	}
+	alertState, ok := alertStates[query]
+	if !ok {
+		return prometheustypes.Matrix{}, nil, nil
+	}
+	ret := prometheustypes.Matrix{}
+	for _, series := range f.series {
+		if len(alertState) > 0 && string(series.Metric["alertstate"]) != alertState {
+			continue
+		}
+		values := []prometheustypes.SamplePair{}
+		for _, sample := range series.Values {
+			if !sample.Timestamp.Time().Before(r.Start) && !sample.Timestamp.Time().After(r.End) {
+				values = append(values, sample)
+			}
+		}
+		if len(values) > 0 {
+			ret = append(ret, &prometheustypes.SampleStream{Metric: series.Metric, Values: values})
+		}
+	}
+	return ret, nil, nil
+}
+
+func Test_alertIntervalsFromSnapshot(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	alertSeries := func(labels prometheustypes.Metric, from, to time.Time) *prometheustypes.SampleStream {
+		series := &prometheustypes.SampleStream{Metric: labels}
+		for ts := from; !ts.After(to); ts = ts.Add(2 * time.Second) {
+			series.Values = append(series.Values, prometheustypes.SamplePair{Timestamp: prometheustypes.TimeFromUnixNano(ts.UnixNano()), Value: 1})
+		}
+		return series
+	}
+	live := &fakeAlertsQuerier{
+		series: prometheustypes.Matrix{
+			alertSeries(prometheustypes.Metric{"__name__": "ALERTS", "alertname": "Watchdog", "alertstate": "firing", "severity": "none"}, start, end),
+			alertSeries(prometheustypes.Metric{"__name__": "ALERTS", "alertname": "KubePodCrashLooping", "alertstate": "pending", "severity": "warning"}, start.Add(10*time.Minute), start.Add(15*time.Minute)),
+			alertSeries(prometheustypes.Metric{"__name__": "ALERTS", "alertname": "KubePodCrashLooping", "alertstate": "firing", "severity": "warning"}, start.Add(15*time.Minute), start.Add(25*time.Minute)),
+			alertSeries(prometheustypes.Metric{"__name__": "ALERTS", "alertname": "KubePodCrashLooping", "alertstate": "firing", "severity": "warning"}, start.Add(40*time.Minute), start.Add(45*time.Minute)),
+		},
+	}
+	timeRange := prometheusv1.Range{Start: start, End: end, Step: 2 * time.Second}
+
+	t.Setenv(prometheussnapshot.QueriesEnvVar, "default")
+	queries, err := prometheussnapshot.LoadSnapshotQueries()
+	require.NoError(t, err)
+	snapshot := prometheussnapshot.Capture(context.TODO(), live, queries, start, end)
+
+	expected, _, err := alertIntervals(context.TODO(), live, nil, timeRange)
+	require.NoError(t, err)
+	actual, _, err := alertIntervals(context.TODO(), snapshot, nil, timeRange)
+	require.NoError(t, err)
+
+	// the watchdog, two firings and the pending crash loop.
+	assert.Len(t, expected, 4)
+	assert.ElementsMatch(t, monitorapi.Intervals(expected).Strings(), monitorapi.Intervals(actual).Strings())
+}
@@ -13,6 +13,7 @@ import (
 
 type alertSummarySerializer struct {
 	adminRESTConfig *rest.Config
+	alertDetails    map[string]*AlertDetails
 }
 
 func NewAlertSummarySerializer() monitortestframework.MonitorTest {
@@ -25,7 +26,8 @@ func (w *alertSummarySerializer) StartCollection(ctx context.Context, adminRESTC
 }
 
 func (w *alertSummarySerializer) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
-	intervals, err := fetchEventIntervalsForAllAlerts(ctx, w.adminRESTConfig, beginning)
+	intervals, alertDetails, err := fetchEventIntervalsForAllAlerts(ctx, w.adminRESTConfig, beginning)
+	w.alertDetails = alertDetails
 	return intervals, nil, err
 }
 
@@ -37,7 +39,10 @@ func (*alertSummarySerializer) EvaluateTestsFromConstructedIntervals(ctx context
 	return nil, nil
 }
 
-func (*alertSummarySerializer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+func (w *alertSummarySerializer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	if err := writeAlertDetails(storageDir, timeSuffix, w.alertDetails); err != nil {
+		return err
+	}
 	return writeAlertDataForJobRun(storageDir, nil, finalIntervals, timeSuffix)
 }
 
@@ -0,0 +1,253 @@
+package disruptionconsensus
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// AnnotationFailingSamplers lists the samplers that were failing during a consensus interval.
+	AnnotationFailingSamplers monitorapi.AnnotationKey = "failing-samplers"
+	// AnnotationTotalSamplers is the number of samplers that reported on the logical backend during the run.
+	AnnotationTotalSamplers monitorapi.AnnotationKey = "total-samplers"
+)
+
+var (
+	// in-cluster samplers started by run-disruption append the node name to every message.
+	userProvidedNodeRegex = regexp.MustCompile(`user-provided-message=(\S+)`)
+	// the poll-service and watch-endpoint-slice pollers encode the node they run on in the instance name.
+	instanceNodeRegex = regexp.MustCompile(`-from-node-(.+?)-to-`)
+)
+
+// sampler identifies one vantage point checking a logical backend.  Local samplers run inside openshift-tests and have
+// no node, in-cluster samplers run on a node.
+type sampler struct {
+	instance string
+	node     string
+}
+
+func (s sampler) String() string {
+	if len(s.node) == 0 {
+		return fmt.Sprintf("%s from %s", s.instance, backenddisruption.OpenshiftTestsSource)
+	}
+	return fmt.Sprintf("%s from node/%s", s.instance, s.node)
+}
+
+func (s sampler) isLocal() bool {
+	return len(s.node) == 0
+}
+
+func samplerFor(interval monitorapi.Interval) sampler {
+	ret := sampler{
+		instance: interval.Locator.Keys[monitorapi.LocatorDisruptionKey],
+	}
+	if matches := userProvidedNodeRegex.FindStringSubmatch(interval.Message.HumanMessage); len(matches) > 1 {
+		ret.node = matches[1]
+	} else if matches := instanceNodeRegex.FindStringSubmatch(ret.instance); len(matches) > 1 {
+		ret.node = matches[1]
+	}
+	return ret
+}
+
+// logicalBackendFor groups samplers which check the same server over different paths.  In-cluster samplers have a
+// backend-disruption-name per load balancer, but they share their target with the samplers from openshift-tests.
+func logicalBackendFor(interval monitorapi.Interval) string {
+	target := interval.Locator.Keys[monitorapi.LocatorTargetKey]
+	connectionType := interval.Locator.Keys[monitorapi.LocatorConnectionKey]
+	if len(target) > 0 && len(connectionType) > 0 {
+		return fmt.Sprintf("%s-%s-connections", target, connectionType)
+	}
+	return monitorapi.BackendDisruptionNameFromLocator(interval.Locator)
+}
+
+type failure struct {
+	sampler  sampler
+	from, to time.Time
+}
+
+type logicalBackend struct {
+	name     string
+	samplers sets.Set[sampler]
+	failures []failure
+}
+
+func logicalBackendsFrom(intervals monitorapi.Intervals, end time.Time) map[string]*logicalBackend {
+	ret := map[string]*logicalBackend{}
+	for _, interval := range intervals {
+		if !monitorapi.IsDisruptionEvent(interval) {
+			continue
+		}
+		name := logicalBackendFor(interval)
+		if len(name) == 0 {
+			continue
+		}
+		backend, ok := ret[name]
+		if !ok {
+			backend = &logicalBackend{name: name, samplers: sets.New[sampler]()}
+			ret[name] = backend
+		}
+
+		currSampler := samplerFor(interval)
+		backend.samplers.Insert(currSampler)
+		if interval.Level != monitorapi.Error {
+			continue
+		}
+		to := interval.To
+		if to.IsZero() {
+			to = end
+		}
+		if !to.After(interval.From) {
+			continue
+		}
+		backend.failures = append(backend.failures, failure{sampler: currSampler, from: interval.From, to: to})
+	}
+	return ret
+}
+
+// classify decides what a set of simultaneously failing samplers tells us about the backend.
+func (b *logicalBackend) classify(failing sets.Set[sampler]) (monitorapi.IntervalReason, monitorapi.IntervalLevel) {
+	if failing.Len() == b.samplers.Len() {
+		return monitorapi.DisruptionConsensusEverywhereReason, monitorapi.Error
+	}
+
+	allLocal := true
+	failingNodes := sets.New[string]()
+	for _, curr := range failing.UnsortedList() {
+		if !curr.isLocal() {
+			allLocal = false
+			failingNodes.Insert(curr.node)
+		}
+	}
+	hasInClusterSamplers := false
+	for _, curr := range b.samplers.UnsortedList() {
+		if !curr.isLocal() {
+			hasInClusterSamplers = true
+			break
+		}
+	}
+
+	switch {
+	case allLocal && hasInClusterSamplers:
+		return monitorapi.DisruptionConsensusOpenShiftTestsOnlyReason, monitorapi.Warning
+	case !allLocal && failingNodes.Len() == 1 && failing.Len() == samplersOnNode(failing, failingNodes.UnsortedList()[0]):
+		return monitorapi.DisruptionConsensusSingleNodeReason, monitorapi.Warning
+	default:
+		return monitorapi.DisruptionConsensusPartialReason, monitorapi.Warning
+	}
+}
+
+func samplersOnNode(samplers sets.Set[sampler], node string) int {
+	count := 0
+	for _, curr := range samplers.UnsortedList() {
+		if curr.node == node {
+			count++
+		}
+	}
+	return count
+}
+
+type consensusSegment struct {
+	reason   monitorapi.IntervalReason
+	level    monitorapi.IntervalLevel
+	failing  sets.Set[sampler]
+	from, to time.Time
+}
+
+// segments sweeps over the failures in time order and returns a segment for every stretch of time during which the
+// classification did not change.
+func (b *logicalBackend) segments() []consensusSegment {
+	boundaries := []time.Time{}
+	for _, curr := range b.failures {
+		boundaries = append(boundaries, curr.from, curr.to)
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+	times := []time.Time{}
+	for _, curr := range boundaries {
+		if len(times) == 0 || !times[len(times)-1].Equal(curr) {
+			times = append(times, curr)
+		}
+	}
+
+	ret := []consensusSegment{}
+	for i := 0; i+1 < len(times); i++ {
+		from, to := times[i], times[i+1]
+		failing := sets.New[sampler]()
+		for _, curr := range b.failures {
+			if !curr.from.After(from) && !curr.to.Before(to) {
+				failing.Insert(curr.sampler)
+			}
+		}
+		if failing.Len() == 0 {
+			continue
+		}
+
+		reason, level := b.classify(failing)
+		if len(ret) > 0 {
+			prev := &ret[len(ret)-1]
+			if prev.reason == reason && prev.to.Equal(from) {
+				prev.to = to
+				prev.failing = prev.failing.Union(failing)
+				continue
+			}
+		}
+		ret = append(ret, consensusSegment{reason: reason, level: level, failing: failing, from: from, to: to})
+	}
+	return ret
+}
+
+func samplerNames(samplers sets.Set[sampler]) []string {
+	ret := []string{}
+	for _, curr := range samplers.UnsortedList() {
+		ret = append(ret, curr.String())
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func humanMessageFor(reason monitorapi.IntervalReason, backendName string, failing, total int) string {
+	switch reason {
+	case monitorapi.DisruptionConsensusEverywhereReason:
+		return fmt.Sprintf("%s was unreachable from all %d samplers", backendName, total)
+	case monitorapi.DisruptionConsensusOpenShiftTestsOnlyReason:
+		return fmt.Sprintf("%s was only unreachable from openshift-tests, in-cluster samplers succeeded", backendName)
+	case monitorapi.DisruptionConsensusSingleNodeReason:
+		return fmt.Sprintf("%s was only unreachable from a single node", backendName)
+	default:
+		return fmt.Sprintf("%s was unreachable from %d of %d samplers", backendName, failing, total)
+	}
+}
+
+// computeConsensusIntervals aligns the disruption intervals of every sampler by logical backend and time, and
+// produces intervals that distinguish a backend that is down everywhere from a problem with a single path.
+// Backends with only one sampler have nothing to agree on and are skipped.
+func computeConsensusIntervals(startingIntervals monitorapi.Intervals, end time.Time) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	for _, backend := range logicalBackendsFrom(startingIntervals, end) {
+		if backend.samplers.Len() < 2 {
+			continue
+		}
+		for _, segment := range backend.segments() {
+			ret = append(ret,
+				monitorapi.NewInterval(monitorapi.SourceDisruptionConsensus, segment.level).
+					Locator(monitorapi.NewLocator().DisruptionConsensus(backend.name)).
+					Message(monitorapi.NewMessage().
+						Reason(segment.reason).
+						WithAnnotation(AnnotationFailingSamplers, strings.Join(samplerNames(segment.failing), ", ")).
+						WithAnnotation(AnnotationTotalSamplers, fmt.Sprintf("%d", backend.samplers.Len())).
+						HumanMessage(humanMessageFor(segment.reason, backend.name, segment.failing.Len(), backend.samplers.Len())),
+					).
+					Display().
+					Build(segment.from, segment.to),
+			)
+		}
+	}
+	sort.Sort(ret)
+	return ret
+}
@@ -0,0 +1,190 @@
+package disruptionconsensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+)
+
+func disruptionInterval(instance, humanMessage string, level monitorapi.IntervalLevel, from, to time.Time) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceDisruption, level).
+		Locator(monitorapi.NewLocator().Disruption("kube-api-new-connections", instance, "", "", "kube-api", monitorapi.NewConnectionType)).
+		Message(monitorapi.NewMessage().HumanMessage(humanMessage)).
+		Build(from, to)
+}
+
+func Test_computeConsensusIntervals(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	local := func(level monitorapi.IntervalLevel, from, to int) monitorapi.Interval {
+		return disruptionInterval("kube-api-new-connections", "local", level, at(from), at(to))
+	}
+	nodeA := func(level monitorapi.IntervalLevel, from, to int) monitorapi.Interval {
+		return disruptionInterval("kube-api-from-node-a-to-service", "node a", level, at(from), at(to))
+	}
+	nodeB := func(level monitorapi.IntervalLevel, from, to int) monitorapi.Interval {
+		return disruptionInterval("kube-api-from-node-b-to-service", "node b", level, at(from), at(to))
+	}
+
+	tests := []struct {
+		name     string
+		input    monitorapi.Intervals
+		expected []monitorapi.IntervalReason
+	}{
+		{
+			name:     "single sampler has no consensus",
+			input:    monitorapi.Intervals{local(monitorapi.Error, 10, 20)},
+			expected: nil,
+		},
+		{
+			name: "every sampler failing",
+			input: monitorapi.Intervals{
+				local(monitorapi.Error, 10, 20),
+				nodeA(monitorapi.Error, 10, 20),
+				nodeB(monitorapi.Error, 10, 20),
+			},
+			expected: []monitorapi.IntervalReason{monitorapi.DisruptionConsensusEverywhereReason},
+		},
+		{
+			name: "only openshift-tests failing",
+			input: monitorapi.Intervals{
+				local(monitorapi.Error, 10, 20),
+				nodeA(monitorapi.Info, 0, 60),
+				nodeB(monitorapi.Info, 0, 60),
+			},
+			expected: []monitorapi.IntervalReason{monitorapi.DisruptionConsensusOpenShiftTestsOnlyReason},
+		},
+		{
+			name: "only one node failing",
+			input: monitorapi.Intervals{
+				local(monitorapi.Info, 0, 60),
+				nodeA(monitorapi.Error, 10, 20),
+				nodeB(monitorapi.Info, 0, 60),
+			},
+			expected: []monitorapi.IntervalReason{monitorapi.DisruptionConsensusSingleNodeReason},
+		},
+		{
+			name: "partial overlap escalates and recovers",
+			input: monitorapi.Intervals{
+				local(monitorapi.Error, 10, 30),
+				nodeA(monitorapi.Error, 20, 40),
+				nodeB(monitorapi.Error, 20, 30),
+			},
+			expected: []monitorapi.IntervalReason{
+				monitorapi.DisruptionConsensusOpenShiftTestsOnlyReason,
+				monitorapi.DisruptionConsensusEverywhereReason,
+				monitorapi.DisruptionConsensusSingleNodeReason,
+			},
+		},
+		{
+			name: "two of three failing",
+			input: monitorapi.Intervals{
+				local(monitorapi.Error, 10, 20),
+				nodeA(monitorapi.Error, 10, 20),
+				nodeB(monitorapi.Info, 0, 60),
+			},
+			expected: []monitorapi.IntervalReason{monitorapi.DisruptionConsensusPartialReason},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := computeConsensusIntervals(tt.input, end)
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %d intervals, got %d:\n%v", len(tt.expected), len(actual), actual.Strings())
+			}
+			for i := range actual {
+				if actual[i].Message.Reason != tt.expected[i] {
+					t.Errorf("interval %d: expected %v, got %v", i, tt.expected[i], actual[i].Message.Reason)
+				}
+				expectedLevel := monitorapi.Warning
+				if tt.expected[i] == monitorapi.DisruptionConsensusEverywhereReason {
+					expectedLevel = monitorapi.Error
+				}
+				if actual[i].Level != expectedLevel {
+					t.Errorf("interval %d: expected level %v, got %v", i, expectedLevel, actual[i].Level)
+				}
+				if len(monitorapi.BackendDisruptionNameFromLocator(actual[i].Locator)) > 0 {
+					t.Errorf("interval %d: consensus intervals must not be counted as disruption: %v", i, actual[i].Locator)
+				}
+			}
+		})
+	}
+}
+
+func Test_createConsensusJunit(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+	everywhere := computeConsensusIntervals(monitorapi.Intervals{
+		disruptionInterval("kube-api-new-connections", "local", monitorapi.Error, at(10), at(30)),
+		disruptionInterval("kube-api-from-node-a-to-service", "node a", monitorapi.Error, at(10), at(30)),
+	}, end)
+	partial := computeConsensusIntervals(monitorapi.Intervals{
+		disruptionInterval("kube-api-new-connections", "local", monitorapi.Error, at(10), at(30)),
+		disruptionInterval("kube-api-from-node-a-to-service", "node a", monitorapi.Info, at(0), at(60)),
+	}, end)
+
+	tests := []struct {
+		name              string
+		intervals         monitorapi.Intervals
+		allowedDisruption historicaldata.StatisticalDuration
+		expectedFailures  int
+		expectedPasses    int
+	}{
+		{
+			name:              "no consensus outage",
+			intervals:         partial,
+			allowedDisruption: historicaldata.StatisticalDuration{P99: time.Second, Confidence: 1},
+			expectedPasses:    1,
+		},
+		{
+			name:              "within the historical disruption",
+			intervals:         everywhere,
+			allowedDisruption: historicaldata.StatisticalDuration{P99: 15 * time.Second, Confidence: 1},
+			expectedPasses:    1,
+		},
+		{
+			name:              "over the historical disruption",
+			intervals:         everywhere,
+			allowedDisruption: historicaldata.StatisticalDuration{P99: 5 * time.Second, Confidence: 1},
+			expectedFailures:  1,
+		},
+		{
+			name:              "over low confidence historical disruption",
+			intervals:         everywhere,
+			allowedDisruption: historicaldata.StatisticalDuration{P99: 5 * time.Second, Fallback: "platform", Confidence: 0.5},
+			expectedFailures:  1,
+			expectedPasses:    1,
+		},
+		{
+			name:             "no historical disruption",
+			intervals:        everywhere,
+			expectedFailures: 1,
+			expectedPasses:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := createConsensusJunit("kube-api-new-connections", tt.intervals, tt.allowedDisruption, historicaldata.Dataset{})
+			failures, passes := 0, 0
+			for _, junit := range actual {
+				if junit.FailureOutput != nil {
+					failures++
+				} else {
+					passes++
+				}
+			}
+			if failures != tt.expectedFailures || passes != tt.expectedPasses {
+				t.Errorf("expected %d failures and %d passes, got %d and %d: %v", tt.expectedFailures, tt.expectedPasses, failures, passes, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package disruptionconsensus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/client-go/rest"
+)
+
+type disruptionConsensus struct {
+	// store the rest config so we can get the JobType at the end of the run
+	adminRESTConfig *rest.Config
+}
+
+// NewAnalyzer compares the disruption reported by the different samplers of the same backend, the local openshift-tests
+// samplers and the in-cluster ones, so that only outages seen by every sampler count against the historical disruption
+// of the backend.
+func NewAnalyzer() monitortestframework.MonitorTest {
+	return &disruptionConsensus{}
+}
+
+func (w *disruptionConsensus) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	w.adminRESTConfig = adminRESTConfig
+	return nil
+}
+
+func (w *disruptionConsensus) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	return nil, nil, nil
+}
+
+func (*disruptionConsensus) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return computeConsensusIntervals(startingIntervals, end), nil
+}
+
+func (w *disruptionConsensus) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	consensusIntervals := finalIntervals.Filter(func(eventInterval monitorapi.Interval) bool {
+		return eventInterval.Source == monitorapi.SourceDisruptionConsensus
+	})
+	if len(consensusIntervals) == 0 {
+		return nil, nil
+	}
+
+	jobType, err := platformidentification.GetJobType(ctx, w.adminRESTConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	byBackend := map[string]monitorapi.Intervals{}
+	for _, interval := range consensusIntervals {
+		backendName := interval.Locator.Keys[monitorapi.LocatorTargetKey]
+		byBackend[backendName] = append(byBackend[backendName], interval)
+	}
+	backendNames := []string{}
+	for backendName := range byBackend {
+		backendNames = append(backendNames, backendName)
+	}
+	sort.Strings(backendNames)
+
+	ret := []*junitapi.JUnitTestCase{}
+	for _, backendName := range backendNames {
+		allowedDisruption, _, err := allowedbackenddisruption.GetAllowedDisruptionPercentiles(backendName, *jobType)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get allowed disruption for %s: %w", backendName, err)
+		}
+		ret = append(ret, createConsensusJunit(backendName, byBackend[backendName], allowedDisruption, allowedbackenddisruption.GetCurrentResults().Dataset)...)
+	}
+
+	return ret, nil
+}
+
+// createConsensusJunit fails when the backend was unreachable from every sampler for longer than the P99 of the
+// disruption of the backend, with the same grace as the backend disruption tests.  The disruption seen by every sampler
+// is at most the disruption seen by any one of them, so the historical disruption of the backend is a safe allowance.
+// Backends without historical data, or with low confidence data, only flake.
+func createConsensusJunit(backendName string, intervals monitorapi.Intervals, allowedDisruption historicaldata.StatisticalDuration, dataset historicaldata.Dataset) []*junitapi.JUnitTestCase {
+	testName := fmt.Sprintf("[sig-network] disruption for %s should not be observed by every sampler at the same time", backendName)
+
+	outages := intervals.Filter(monitorapi.IsErrorEvent)
+	outageDuration := outages.Duration(1 * time.Second).Round(time.Second)
+	summary := fmt.Sprintf("%d consensus outages totaling %s, %d intervals where only some samplers failed:\n%s",
+		len(outages), outageDuration, len(intervals)-len(outages), strings.Join(intervals.Strings(), "\n"))
+	if len(outages) == 0 {
+		return []*junitapi.JUnitTestCase{{Name: testName, SystemOut: summary}}
+	}
+
+	if allowedDisruption == (historicaldata.StatisticalDuration{}) {
+		failureMessage := fmt.Sprintf("%s was unreachable from every sampler, there is no historical data to decide whether that is a regression, using %v\n\n%s",
+			backendName, dataset, summary)
+		return []*junitapi.JUnitTestCase{
+			{
+				Name:          testName,
+				FailureOutput: &junitapi.FailureOutput{Output: failureMessage},
+				SystemOut:     failureMessage,
+			},
+			{Name: testName},
+		}
+	}
+
+	finalAllowedDisruption, allowedDetails := disruptionlibrary.CalculateAllowedDisruptionWithGrace(allowedDisruption.P99)
+	allowedDetails = append(allowedDetails, allowedDisruption.DescribeMatch())
+	if outageDuration <= finalAllowedDisruption {
+		return []*junitapi.JUnitTestCase{
+			{
+				Name:      testName,
+				SystemOut: fmt.Sprintf("%s (maxAllowed=%s)\n%s\nusing %v", summary, finalAllowedDisruption, strings.Join(allowedDetails, "\n"), dataset),
+			},
+		}
+	}
+
+	failureMessage := fmt.Sprintf("%s was unreachable from every sampler for at least %s (maxAllowed=%s):\n%s\nusing %v\n\n%s",
+		backendName, outageDuration, finalAllowedDisruption, strings.Join(allowedDetails, "\n"), dataset, summary)
+	failure := &junitapi.JUnitTestCase{
+		Name:          testName,
+		FailureOutput: &junitapi.FailureOutput{Output: failureMessage},
+		SystemOut:     failureMessage,
+	}
+	// Data from a low confidence fallback is not trusted to fail the test, only to flake it.
+	if allowedDisruption.LowConfidence() {
+		return []*junitapi.JUnitTestCase{failure, {Name: testName}}
+	}
+	return []*junitapi.JUnitTestCase{failure}
+}
+
+func (*disruptionConsensus) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (*disruptionConsensus) Cleanup(ctx context.Context) error {
+	return nil
+}
@@ -2,12 +2,20 @@ package disruptionexternalservicemonitoring
 
 import (
 	"context"
-	_ "embed"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
 	"github.com/openshift/origin/pkg/monitortestframework"
 	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	exutil "github.com/openshift/origin/test/extended/util"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	"github.com/openshift/origin/pkg/monitor/backenddisruption"
@@ -15,44 +23,92 @@ import (
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 )
 
-const (
-	newConnectionTestName    = "[sig-trt] disruption/ci-cluster-network-liveness connection/new should be available throughout the test"
-	reusedConnectionTestName = "[sig-trt] disruption/ci-cluster-network-liveness connection/reused should be available throughout the test"
-
-	externalServiceURL = "http://static.redhat.com/test/rhel-networkmanager.txt"
-)
-
 type availability struct {
+	provider ExternalServiceProvider
+
 	disruptionChecker  *disruptionlibrary.Availability
 	notSupportedReason error
 	suppressJunit      bool
 }
 
-func NewAvailabilityInvariant() monitortestframework.MonitorTest {
-	return &availability{}
+// NewAvailabilityInvariant samples the endpoint of the provider.  Junit results are only produced for providers that
+// ask for them.
+func NewAvailabilityInvariant(provider ExternalServiceProvider) monitortestframework.MonitorTest {
+	return &availability{
+		provider:      provider,
+		suppressJunit: !provider.Junit,
+	}
 }
 
-func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
+func NewRecordAvailabilityOnly(provider ExternalServiceProvider) monitortestframework.MonitorTest {
 	return &availability{
+		provider:      provider,
 		suppressJunit: true,
 	}
 }
 
 func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
-	newConnectionDisruptionSampler := backenddisruption.NewSimpleBackendFromOpenshiftTests(
-		externalServiceURL,
-		"ci-cluster-network-liveness-new-connections",
-		"",
-		monitorapi.NewConnectionType)
+	if w.provider.SkipOnMicroShift {
+		kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+		if err != nil {
+			return err
+		}
+		isMicroShift, err := exutil.IsMicroShiftCluster(kubeClient)
+		if err != nil {
+			return fmt.Errorf("unable to determine if cluster is MicroShift: %v", err)
+		}
+		if isMicroShift {
+			w.notSupportedReason = &monitortestframework.NotSupportedError{
+				Reason: "platform MicroShift not supported",
+			}
+			return w.notSupportedReason
+		}
+	}
 
-	reusedConnectionDisruptionSampler := backenddisruption.NewSimpleBackendFromOpenshiftTests(
-		externalServiceURL,
-		"ci-cluster-network-liveness-reused-connections",
-		"",
-		monitorapi.ReusedConnectionType)
+	if len(w.provider.Platforms) > 0 {
+		platform, err := platformidentification.GetPlatformType(ctx, adminRESTConfig)
+		if err != nil {
+			return fmt.Errorf("unable to determine the platform: %w", err)
+		}
+		if !w.provider.supportsPlatform(platform) {
+			w.notSupportedReason = &monitortestframework.NotSupportedError{
+				Reason: fmt.Sprintf("%s disruption monitor only runs on %v, not on %v", w.provider.Name, w.provider.Platforms, platform),
+			}
+			return w.notSupportedReason
+		}
+	}
+
+	externalServiceURL := w.provider.URL
+	if w.provider.ClusterMirror {
+		mirrorURL, err := clusterMirrorURL(ctx, adminRESTConfig)
+		if err != nil {
+			return err
+		}
+		if len(mirrorURL) == 0 {
+			w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: "cluster has no ImageDigestMirrorSets"}
+			return w.notSupportedReason
+		}
+		externalServiceURL = mirrorURL + w.provider.Path
+	}
+
+	// Proxy jobs may require a whitelist we don't want to deal with.  Endpoints that do work through the proxy are
+	// sampled through it, the samplers honor HTTP_PROXY.
+	if w.provider.SkipWhenProxied {
+		proxied, err := isProxied(externalServiceURL)
+		if err != nil {
+			return err
+		}
+		if proxied {
+			w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: fmt.Sprintf("%s disruption monitor is disabled when HTTP_PROXY is in use", w.provider.Name)}
+			return w.notSupportedReason
+		}
+	}
+
+	newConnectionDisruptionSampler := w.newSampler(externalServiceURL, monitorapi.NewConnectionType)
+	reusedConnectionDisruptionSampler := w.newSampler(externalServiceURL, monitorapi.ReusedConnectionType)
 
 	w.disruptionChecker = disruptionlibrary.NewAvailabilityInvariant(
-		newConnectionTestName, reusedConnectionTestName,
+		w.provider.newConnectionTestName(), w.provider.reusedConnectionTestName(),
 		newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
 	)
 	if err := w.disruptionChecker.StartCollection(ctx, adminRESTConfig, recorder); err != nil {
@@ -62,10 +118,69 @@ func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *res
 	return nil
 }
 
+func (w *availability) newSampler(externalServiceURL string, connectionType monitorapi.BackendConnectionType) *backenddisruption.BackendSampler {
+	sampler := backenddisruption.NewSimpleBackendFromOpenshiftTests(
+		externalServiceURL,
+		fmt.Sprintf("%s-%s-connections", w.provider.Name, connectionType),
+		"",
+		connectionType)
+	if w.provider.ExpectedStatusCode > 0 {
+		sampler = sampler.WithExpectedStatusCode(w.provider.ExpectedStatusCode)
+	}
+	if len(w.provider.ExpectedBody) > 0 {
+		sampler = sampler.WithExpectedBody(w.provider.ExpectedBody)
+	}
+	if len(w.provider.ExpectedBodyRegex) > 0 {
+		sampler = sampler.WithExpectedBodyRegex(w.provider.ExpectedBodyRegex)
+	}
+	return sampler
+}
+
+// clusterMirrorURL returns the registry of the first mirror of the cluster or empty if there are no mirrors.
+func clusterMirrorURL(ctx context.Context, adminRESTConfig *rest.Config) (string, error) {
+	configClient, err := configclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return "", err
+	}
+	mirrorSets, err := configClient.ConfigV1().ImageDigestMirrorSets().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to list ImageDigestMirrorSets: %w", err)
+	}
+	for _, mirrorSet := range mirrorSets.Items {
+		for _, digestMirrors := range mirrorSet.Spec.ImageDigestMirrors {
+			for _, mirror := range digestMirrors.Mirrors {
+				registry := strings.SplitN(string(mirror), "/", 2)[0]
+				return "https://" + registry, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// isProxied is true when HTTP_PROXY, HTTPS_PROXY and NO_PROXY send requests for externalServiceURL through a proxy.
+func isProxied(externalServiceURL string) (bool, error) {
+	parsedURL, err := url.Parse(externalServiceURL)
+	if err != nil {
+		return false, err
+	}
+	proxy, err := http.ProxyFromEnvironment(&http.Request{
+		Method: http.MethodGet,
+		URL:    parsedURL,
+	})
+	if err != nil {
+		return false, err
+	}
+	return proxy != nil, nil
+}
+
 func (w *availability) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
 	if w.notSupportedReason != nil {
 		return nil, nil, w.notSupportedReason
 	}
+	// we failed and indicated it during setup.
+	if w.disruptionChecker == nil {
+		return nil, nil, nil
+	}
 	return w.disruptionChecker.CollectData(ctx)
 }
 
@@ -77,8 +192,15 @@ func (w *availability) EvaluateTestsFromConstructedIntervals(ctx context.Context
 	if w.suppressJunit {
 		return nil, nil
 	}
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+	// we failed and indicated it during setup.
+	if w.disruptionChecker == nil {
+		return nil, nil
+	}
 
-	return nil, w.notSupportedReason
+	return w.disruptionChecker.EvaluateTestsFromConstructedIntervals(ctx, finalIntervals)
 }
 
 func (w *availability) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
@@ -0,0 +1,176 @@
+package disruptionexternalservicemonitoring
+
+import (
+	_ "embed"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// ProvidersFileEnvVar points to a file with additional providers.  Providers with the name of a default provider
+// replace it, so an environment can also point a default provider at a local endpoint.
+const ProvidersFileEnvVar = "EXTERNAL_SERVICE_PROVIDERS_FILE"
+
+//go:embed providers.yaml
+var defaultProvidersYAML []byte
+
+// ExternalServiceProviders is the format of providers.yaml and of the file in EXTERNAL_SERVICE_PROVIDERS_FILE.
+type ExternalServiceProviders struct {
+	Providers []ExternalServiceProvider `json:"providers"`
+}
+
+// ExternalServiceProvider describes an external endpoint that openshift-tests samples during the run, usually
+// something hosted in a cloud, and in which environments it makes sense to sample it.
+type ExternalServiceProvider struct {
+	// Name is used for the test names and as the prefix of the disruption backend names.
+	Name string `json:"name"`
+	// MonitorTestName is the name the monitor test is registered under, it defaults to external-<name>-availability.
+	MonitorTestName string `json:"monitorTestName,omitempty"`
+
+	// URL is sampled with a GET.  Exactly one of URL and ClusterMirror must be set.
+	URL string `json:"url,omitempty"`
+	// ClusterMirror samples the first mirror registry of the cluster's ImageDigestMirrorSets instead of a fixed URL.
+	// Clusters without mirrors skip the provider.
+	ClusterMirror bool `json:"clusterMirror,omitempty"`
+	// Path is appended to the mirror registry host when ClusterMirror is set.
+	Path string `json:"path,omitempty"`
+
+	// ExpectedStatusCode is accepted in addition to the 2xx and 3xx status codes.
+	ExpectedStatusCode int `json:"expectedStatusCode,omitempty"`
+	// ExpectedBody is an exact match for the body of the response.
+	ExpectedBody string `json:"expectedBody,omitempty"`
+	// ExpectedBodyRegex is a regular expression the body of the response must match.
+	ExpectedBodyRegex string `json:"expectedBodyRegex,omitempty"`
+
+	// Platforms limits the provider to clusters on these platforms, as lower case infrastructure platform types like
+	// aws, vsphere, ibmcloud or nutanix.  An empty list samples from every platform.
+	Platforms []string `json:"platforms,omitempty"`
+	// SkipOnMicroShift skips the provider on MicroShift clusters.
+	SkipOnMicroShift bool `json:"skipOnMicroShift,omitempty"`
+	// SkipWhenProxied skips the provider when openshift-tests would reach the endpoint through HTTP_PROXY, for
+	// endpoints that proxies are unlikely to allow.  Otherwise the endpoint is sampled through the proxy.
+	SkipWhenProxied bool `json:"skipWhenProxied,omitempty"`
+
+	// Junit fails the run when the disruption exceeds the historical data.  By default the disruption is only recorded.
+	Junit bool `json:"junit,omitempty"`
+}
+
+func (p ExternalServiceProvider) newConnectionTestName() string {
+	return fmt.Sprintf("[sig-trt] disruption/%s connection/new should be available throughout the test", p.Name)
+}
+
+func (p ExternalServiceProvider) reusedConnectionTestName() string {
+	return fmt.Sprintf("[sig-trt] disruption/%s connection/reused should be available throughout the test", p.Name)
+}
+
+func (p ExternalServiceProvider) validate() error {
+	if len(p.Name) == 0 {
+		return fmt.Errorf("name is required")
+	}
+	switch {
+	case len(p.URL) == 0 && !p.ClusterMirror:
+		return fmt.Errorf("%s: one of url or clusterMirror is required", p.Name)
+	case len(p.URL) > 0 && p.ClusterMirror:
+		return fmt.Errorf("%s: url and clusterMirror are mutually exclusive", p.Name)
+	case len(p.URL) > 0:
+		if _, err := url.Parse(p.URL); err != nil {
+			return fmt.Errorf("%s: invalid url: %w", p.Name, err)
+		}
+	}
+	if len(p.ExpectedBodyRegex) > 0 {
+		if _, err := regexp.Compile(p.ExpectedBodyRegex); err != nil {
+			return fmt.Errorf("%s: invalid expectedBodyRegex: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p ExternalServiceProvider) supportsPlatform(platform string) bool {
+	if len(p.Platforms) == 0 {
+		return true
+	}
+	for _, curr := range p.Platforms {
+		if strings.EqualFold(curr, platform) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseProviders(data []byte) ([]ExternalServiceProvider, error) {
+	providers := &ExternalServiceProviders{}
+	if err := yaml.UnmarshalStrict(data, providers); err != nil {
+		return nil, err
+	}
+	for i := range providers.Providers {
+		if err := providers.Providers[i].validate(); err != nil {
+			return nil, err
+		}
+		if len(providers.Providers[i].MonitorTestName) == 0 {
+			providers.Providers[i].MonitorTestName = fmt.Sprintf("external-%s-availability", providers.Providers[i].Name)
+		}
+	}
+	return providers.Providers, nil
+}
+
+// mergeProviders replaces the defaults with overrides of the same name and appends the remaining overrides.
+func mergeProviders(defaults, overrides []ExternalServiceProvider) ([]ExternalServiceProvider, error) {
+	overridesByName := map[string]ExternalServiceProvider{}
+	for _, override := range overrides {
+		if _, ok := overridesByName[override.Name]; ok {
+			return nil, fmt.Errorf("provider %s is defined twice", override.Name)
+		}
+		overridesByName[override.Name] = override
+	}
+
+	ret := []ExternalServiceProvider{}
+	replaced := sets.New[string]()
+	for _, provider := range defaults {
+		if override, ok := overridesByName[provider.Name]; ok {
+			provider = override
+			replaced.Insert(provider.Name)
+		}
+		ret = append(ret, provider)
+	}
+	for _, override := range overrides {
+		if !replaced.Has(override.Name) {
+			ret = append(ret, override)
+		}
+	}
+
+	monitorTestNames := sets.New[string]()
+	for _, provider := range ret {
+		if monitorTestNames.Has(provider.MonitorTestName) {
+			return nil, fmt.Errorf("monitor test %s is used by more than one provider", provider.MonitorTestName)
+		}
+		monitorTestNames.Insert(provider.MonitorTestName)
+	}
+	return ret, nil
+}
+
+// LoadProviders returns the default providers merged with the providers from EXTERNAL_SERVICE_PROVIDERS_FILE.
+func LoadProviders() ([]ExternalServiceProvider, error) {
+	defaults, err := parseProviders(defaultProvidersYAML)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default providers: %w", err)
+	}
+
+	providersFile := os.Getenv(ProvidersFileEnvVar)
+	if len(providersFile) == 0 {
+		return defaults, nil
+	}
+	data, err := os.ReadFile(providersFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s=%s: %w", ProvidersFileEnvVar, providersFile, err)
+	}
+	overrides, err := parseProviders(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid providers in %s: %w", providersFile, err)
+	}
+	return mergeProviders(defaults, overrides)
+}
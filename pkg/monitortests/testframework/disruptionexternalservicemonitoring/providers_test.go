@@ -0,0 +1,129 @@
+package disruptionexternalservicemonitoring
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultProviders(t *testing.T) {
+	providers, err := parseProviders(defaultProvidersYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mergeProviders(providers, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// these monitor test names are used to disable monitor tests in CI, they must not change.
+	expectedMonitorTestNames := map[string]string{
+		"ci-cluster-network-liveness": "external-service-availability",
+		"gcp-network-liveness":        "external-gcp-cloud-service-availability",
+		"aws-network-liveness":        "external-aws-cloud-service-availability",
+		"azure-network-liveness":      "external-azure-cloud-service-availability",
+	}
+	for _, provider := range providers {
+		expected, ok := expectedMonitorTestNames[provider.Name]
+		if !ok {
+			continue
+		}
+		delete(expectedMonitorTestNames, provider.Name)
+		if provider.MonitorTestName != expected {
+			t.Errorf("%s: expected monitor test %s, got %s", provider.Name, expected, provider.MonitorTestName)
+		}
+	}
+	if len(expectedMonitorTestNames) > 0 {
+		t.Errorf("missing providers: %v", expectedMonitorTestNames)
+	}
+}
+
+func TestParseProviders(t *testing.T) {
+	tests := []struct {
+		name          string
+		yaml          string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			yaml: `
+providers:
+- name: nutanix-network-liveness
+  url: http://example.com/health
+  platforms: [nutanix]
+`,
+		},
+		{
+			name: "missing endpoint",
+			yaml: `
+providers:
+- name: nutanix-network-liveness
+`,
+			expectedError: "one of url or clusterMirror is required",
+		},
+		{
+			name: "url and mirror",
+			yaml: `
+providers:
+- name: nutanix-network-liveness
+  url: http://example.com/health
+  clusterMirror: true
+`,
+			expectedError: "mutually exclusive",
+		},
+		{
+			name: "unknown field",
+			yaml: `
+providers:
+- name: nutanix-network-liveness
+  url: http://example.com/health
+  platform: nutanix
+`,
+			expectedError: "unknown field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers, err := parseProviders([]byte(tt.yaml))
+			switch {
+			case len(tt.expectedError) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(tt.expectedError) > 0 && (err == nil || !strings.Contains(err.Error(), tt.expectedError)):
+				t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+			case err != nil:
+				return
+			}
+			if actual := providers[0].MonitorTestName; actual != "external-nutanix-network-liveness-availability" {
+				t.Errorf("unexpected default monitor test name %v", actual)
+			}
+			if !providers[0].supportsPlatform("nutanix") || providers[0].supportsPlatform("aws") {
+				t.Errorf("expected the provider to only support nutanix")
+			}
+		})
+	}
+}
+
+func TestMergeProviders(t *testing.T) {
+	defaults := []ExternalServiceProvider{
+		{Name: "a", MonitorTestName: "external-a-availability", URL: "http://a"},
+		{Name: "b", MonitorTestName: "external-b-availability", URL: "http://b"},
+	}
+	overrides := []ExternalServiceProvider{
+		{Name: "c", MonitorTestName: "external-c-availability", URL: "http://c"},
+		{Name: "a", MonitorTestName: "external-a-availability", URL: "http://mirror.local/a"},
+	}
+	merged, err := mergeProviders(defaults, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := []string{}
+	for _, provider := range merged {
+		actual = append(actual, provider.Name+"="+provider.URL)
+	}
+	if expected := "a=http://mirror.local/a,b=http://b,c=http://c"; strings.Join(actual, ",") != expected {
+		t.Errorf("expected %v, got %v", expected, strings.Join(actual, ","))
+	}
+
+	_, err = mergeProviders(defaults, []ExternalServiceProvider{{Name: "c", MonitorTestName: "external-a-availability", URL: "http://c"}})
+	if err == nil {
+		t.Errorf("expected duplicate monitor test names to be rejected")
+	}
+}
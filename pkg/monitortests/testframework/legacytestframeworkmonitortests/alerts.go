@@ -115,6 +115,8 @@ func RunAlertTests(jobType *platformidentification.JobType,
 	// TODO: Run a test to ensure no new alerts fired:
 	ret = append(ret, runNoNewAlertsFiringTest(allowedalerts.GetHistoricalData(), firingIntervals)...)
 
+	ret = append(ret, allowedalerts.AlertTestDefinitionsFileTestCases()...)
+
 	return ret
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortestframework"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/pathologicaleventlibrary"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -37,6 +38,7 @@ func (w *eventWatcher) CollectData(ctx context.Context, storageDir string, begin
 
 func (*eventWatcher) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
 	constructedIntervals := monitorapi.Intervals{}
+	constructedIntervals = append(constructedIntervals, pathologicaleventlibrary.ComputeEventStormIntervals(startingIntervals)...)
 
 	return constructedIntervals, nil
 }
@@ -1,12 +1,40 @@
 package cmd
 
 import (
+	"os"
+
+	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
 	"github.com/openshift/origin/pkg/resourcewatch/operator"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kubectl/pkg/util/templates"
 )
 
+type RunResourceWatchFlags struct {
+	Kubeconfig         string
+	RepositoryPath     string
+	ConfigFile         string
+	Resources          []string
+	Namespaces         []string
+	ExcludedNamespaces []string
+	LabelSelector      string
+}
+
+func NewRunResourceWatchFlags() *RunResourceWatchFlags {
+	repositoryPath := "/repository"
+	if repositoryPathEnv := os.Getenv("REPOSITORY_PATH"); len(repositoryPathEnv) > 0 {
+		repositoryPath = repositoryPathEnv
+	}
+	return &RunResourceWatchFlags{
+		RepositoryPath: repositoryPath,
+	}
+}
+
 func NewRunResourceWatchCommand() *cobra.Command {
+	f := NewRunResourceWatchFlags()
+
 	cmd := &cobra.Command{
 		Use:   "run-resourcewatch",
 		Short: "Run watch for resource changes and commit each to a git repository",
@@ -14,8 +42,36 @@ func NewRunResourceWatchCommand() *cobra.Command {
 			Watches specific resources using the given kubeconfig for create/update/delete,
 			and commits the latest state of the resource to a git repo. This allows you to
 			see precisely how a resource changed over time.
-			By default /repository will be used, specify REPOSITORY_PATH env var to
+			By default /repository will be used, specify REPOSITORY_PATH env var or --repository to
 			override.
+
+			When the repository was written by a previous run, the changes made while resourcewatch was
+			down are committed before watching: they are replayed from the last observed resourceVersion
+			when the cluster still has them, and deletions and updates found by comparing the repository
+			with the cluster are committed by resourcewatch-restart.
+
+			By default the configuration and operators of the cluster and the workloads they run are
+			watched in all namespaces.  --config reads the resources, namespaces, label selector and
+			redacted fields from a YAML file like:
+
+			  resources:
+			  - clusteroperators.config.openshift.io
+			  - "*.operator.openshift.io"
+			  - secrets
+			  namespaces:
+			  - openshift-etcd
+			  excludedNamespaces:
+			  - openshift-must-gather
+			  labelSelector: app!=noisy
+			  redactions:
+			  - resource: secrets
+			    fields:
+			    - data
+			    - stringData
+
+			The flags override the values of the file.  Resources that are not served by the cluster are
+			reported and not watched.
+
 			Sample invocation against an external cluster:
 			  $ REPOSITORY_PATH="/tmp/resource-watch-repo" openshift-tests run-resourcewatch --kubeconfig /path/to/kubeconfig --namespace default
 		`),
@@ -23,11 +79,77 @@ func NewRunResourceWatchCommand() *cobra.Command {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return operator.RunResourceWatch()
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run()
 		},
 	}
-	var dummy string
-	cmd.Flags().StringVar(&dummy, "kubeconfig", "", "This option is not used any more. It will be removed in later releases")
-	cmd.Flags().StringVar(&dummy, "namespace", "", "This option is not used any more. It will be removed in later releases")
+
+	f.BindFlags(cmd.Flags())
+
 	return cmd
 }
+
+func (f *RunResourceWatchFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Kubeconfig, "kubeconfig", f.Kubeconfig, "The kubeconfig of the cluster, defaults to KUBECONFIG or the in-cluster config.")
+	flags.StringVar(&f.RepositoryPath, "repository", f.RepositoryPath, "The git repository changes are committed to, defaults to REPOSITORY_PATH or /repository.")
+	flags.StringVar(&f.ConfigFile, "config", f.ConfigFile, "A file selecting the resources to watch.")
+	flags.StringSliceVar(&f.Resources, "resource", f.Resources, "Resources to watch, like pods, deployments.apps, clusteroperators.v1.config.openshift.io or *.operator.openshift.io for a whole API group.")
+	flags.StringSliceVar(&f.Namespaces, "namespace", f.Namespaces, "Only watch namespaced resources in these namespaces.")
+	flags.StringSliceVar(&f.ExcludedNamespaces, "exclude-namespace", f.ExcludedNamespaces, "Do not watch resources in these namespaces.")
+	flags.StringVarP(&f.LabelSelector, "selector", "l", f.LabelSelector, "Only watch resources matching this label selector.")
+}
+
+func (f *RunResourceWatchFlags) ToOptions() (*RunResourceWatchOptions, error) {
+	config := operator.DefaultResourceWatchConfig()
+	if len(f.ConfigFile) > 0 {
+		var err error
+		if config, err = operator.ReadResourceWatchConfig(f.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
+	if len(f.Resources) > 0 {
+		config.Resources = f.Resources
+	}
+	if len(f.Namespaces) > 0 {
+		config.Namespaces = f.Namespaces
+	}
+	if len(f.ExcludedNamespaces) > 0 {
+		config.ExcludedNamespaces = f.ExcludedNamespaces
+	}
+	if len(f.LabelSelector) > 0 {
+		config.LabelSelector = f.LabelSelector
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var kubeConfig *rest.Config
+	var err error
+	if len(f.Kubeconfig) > 0 {
+		kubeConfig, err = clientcmd.BuildConfigFromFlags("", f.Kubeconfig)
+	} else {
+		kubeConfig, err = clusterinfo.GetMonitorRESTConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &RunResourceWatchOptions{
+		KubeConfig:     kubeConfig,
+		RepositoryPath: f.RepositoryPath,
+		Config:         config,
+	}, nil
+}
+
+type RunResourceWatchOptions struct {
+	KubeConfig     *rest.Config
+	RepositoryPath string
+	Config         *operator.ResourceWatchConfig
+}
+
+func (o *RunResourceWatchOptions) Run() error {
+	return operator.RunResourceWatch(o.KubeConfig, o.RepositoryPath, o.Config)
+}
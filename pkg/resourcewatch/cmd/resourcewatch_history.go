@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type ResourceWatchHistoryFlags struct {
+	RepositoryPath string
+	Namespace      string
+	MinReversions  int
+	IntervalsFile  string
+
+	genericclioptions.IOStreams
+}
+
+func NewResourceWatchHistoryFlags(streams genericclioptions.IOStreams) *ResourceWatchHistoryFlags {
+	repositoryPath := "/repository"
+	if repositoryPathEnv := os.Getenv("REPOSITORY_PATH"); len(repositoryPathEnv) > 0 {
+		repositoryPath = repositoryPathEnv
+	}
+	return &ResourceWatchHistoryFlags{
+		RepositoryPath: repositoryPath,
+		MinReversions:  2,
+		IOStreams:      streams,
+	}
+}
+
+func NewResourceWatchHistoryCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewResourceWatchHistoryFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "resourcewatch-history RESOURCE[.GROUP]/NAME",
+		Short: "Show who changed which fields of a resource recorded by run-resourcewatch",
+		Long: templates.LongDesc(`
+			Shows the field level change history of a resource recorded in a run-resourcewatch repository: which
+			manager changed which field to what value and when.  Fields that returned to a previous value at least
+			--min-reversions times, changed by more than one manager, are reported as flapping.
+
+			The changes can be written as monitor intervals to overlay them on the timeline of a job run.
+
+			Sample invocation against the repository gathered by a job run:
+			  $ openshift-tests resourcewatch-history --repository /tmp/resource-watch-repo clusteroperators.config.openshift.io/authentication --intervals-file e2e-events_resourcewatch.json
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions(args)
+			if err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (f *ResourceWatchHistoryFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.RepositoryPath, "repository", f.RepositoryPath, "The run-resourcewatch repository, defaults to REPOSITORY_PATH or /repository.")
+	flags.StringVarP(&f.Namespace, "namespace", "n", f.Namespace, "The namespace of the resource, empty for cluster scoped resources.")
+	flags.IntVar(&f.MinReversions, "min-reversions", f.MinReversions, "How many times a field must return to a previous value to be flapping.")
+	flags.StringVar(&f.IntervalsFile, "intervals-file", f.IntervalsFile, "Write the changes as monitor intervals to this file.")
+}
+
+func (f *ResourceWatchHistoryFlags) ToOptions(args []string) (*ResourceWatchHistoryOptions, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("exactly one RESOURCE[.GROUP]/NAME is required")
+	}
+	resource, name, found := strings.Cut(args[0], "/")
+	if !found || len(resource) == 0 || len(name) == 0 {
+		return nil, fmt.Errorf("%q is not RESOURCE[.GROUP]/NAME", args[0])
+	}
+	if f.MinReversions < 1 {
+		return nil, fmt.Errorf("--min-reversions must be at least 1")
+	}
+
+	return &ResourceWatchHistoryOptions{
+		RepositoryPath: f.RepositoryPath,
+		Filename:       storage.ResourceFilename(schema.ParseGroupResource(resource).WithVersion(""), f.Namespace, name),
+		MinReversions:  f.MinReversions,
+		IntervalsFile:  f.IntervalsFile,
+		IOStreams:      f.IOStreams,
+	}, nil
+}
+
+type ResourceWatchHistoryOptions struct {
+	RepositoryPath string
+	// Filename is the path of the resource in the repository.
+	Filename      string
+	MinReversions int
+	IntervalsFile string
+
+	genericclioptions.IOStreams
+}
+
+func (o *ResourceWatchHistoryOptions) Run() error {
+	history, err := storage.ReadResourceHistory(o.RepositoryPath, o.Filename)
+	if err != nil {
+		return err
+	}
+	blame, err := storage.BlameResource(history, o.MinReversions)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tFIELD\tMANAGERS\tOLD\tNEW")
+	for _, change := range blame.Changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", change.Time.UTC().Format(time.RFC3339), change.Path,
+			strings.Join(change.Managers, ","), truncate(change.OldValue), truncate(change.NewValue))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(blame.Flaps) > 0 {
+		fmt.Fprintf(o.Out, "\nFlapping fields:\n")
+		for _, flap := range blame.Flaps {
+			fmt.Fprintf(o.Out, "  %s changed %d times between %s and %s by %s\n", flap.Path, flap.Changes,
+				flap.From.UTC().Format(time.RFC3339), flap.To.UTC().Format(time.RFC3339), strings.Join(flap.Managers, ", "))
+		}
+	}
+
+	if len(o.IntervalsFile) > 0 {
+		if err := monitorserialization.EventsToFile(o.IntervalsFile, blame.Intervals()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncate keeps whole objects and lists from making the table unreadable.
+func truncate(value string) string {
+	const maxLength = 80
+	if len(value) == 0 {
+		return "<unset>"
+	}
+	if len(value) > maxLength {
+		return value[:maxLength-3] + "..."
+	}
+	return value
+}
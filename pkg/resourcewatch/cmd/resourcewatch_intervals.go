@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type ResourceWatchIntervalsFlags struct {
+	RepositoryPath string
+	From           string
+	To             string
+	IntervalsFile  string
+	ResourcesDir   string
+
+	genericclioptions.IOStreams
+}
+
+func NewResourceWatchIntervalsFlags(streams genericclioptions.IOStreams) *ResourceWatchIntervalsFlags {
+	repositoryPath := "/repository"
+	if repositoryPathEnv := os.Getenv("REPOSITORY_PATH"); len(repositoryPathEnv) > 0 {
+		repositoryPath = repositoryPathEnv
+	}
+	return &ResourceWatchIntervalsFlags{
+		RepositoryPath: repositoryPath,
+		IntervalsFile:  "e2e-events_resourcewatch.json",
+		IOStreams:      streams,
+	}
+}
+
+func NewResourceWatchIntervalsCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	f := NewResourceWatchIntervalsFlags(streams)
+
+	cmd := &cobra.Command{
+		Use:   "resourcewatch-intervals",
+		Short: "Convert the history recorded by run-resourcewatch to monitor intervals",
+		Long: templates.LongDesc(`
+			Walks the history of a run-resourcewatch repository and writes monitor intervals for it: every
+			resource created, updated and deleted, every bump of a generation and every transition of the
+			conditions of ClusterOperators and Nodes.  The intervals file can be overlaid on the timeline of
+			the job run the repository was gathered from.
+
+			With --resources-dir, the last recorded state of every resource is also written like the
+			resources tracked by the monitor, with their observed update and recreation counts.
+
+			Sample invocation against the repository gathered by a job run:
+			  $ openshift-tests resourcewatch-intervals --repository /tmp/resource-watch-repo --from 2023-07-06T01:00:00Z --intervals-file e2e-events_resourcewatch.json
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (f *ResourceWatchIntervalsFlags) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.RepositoryPath, "repository", f.RepositoryPath, "The run-resourcewatch repository, defaults to REPOSITORY_PATH or /repository.")
+	flags.StringVar(&f.From, "from", f.From, "Only convert the changes committed after this RFC3339 time.")
+	flags.StringVar(&f.To, "to", f.To, "Only convert the changes committed before this RFC3339 time.")
+	flags.StringVar(&f.IntervalsFile, "intervals-file", f.IntervalsFile, "Write the intervals to this file.")
+	flags.StringVar(&f.ResourcesDir, "resources-dir", f.ResourcesDir, "Write the recorded resources to resource-<resource>.zip files in this directory.")
+}
+
+func (f *ResourceWatchIntervalsFlags) ToOptions() (*ResourceWatchIntervalsOptions, error) {
+	if len(f.IntervalsFile) == 0 {
+		return nil, fmt.Errorf("--intervals-file is required")
+	}
+	o := &ResourceWatchIntervalsOptions{
+		RepositoryPath: f.RepositoryPath,
+		IntervalsFile:  f.IntervalsFile,
+		ResourcesDir:   f.ResourcesDir,
+		IOStreams:      f.IOStreams,
+	}
+	var err error
+	if len(f.From) > 0 {
+		if o.From, err = time.Parse(time.RFC3339, f.From); err != nil {
+			return nil, fmt.Errorf("--from: %w", err)
+		}
+	}
+	if len(f.To) > 0 {
+		if o.To, err = time.Parse(time.RFC3339, f.To); err != nil {
+			return nil, fmt.Errorf("--to: %w", err)
+		}
+	}
+	return o, nil
+}
+
+type ResourceWatchIntervalsOptions struct {
+	RepositoryPath string
+	From           time.Time
+	To             time.Time
+	IntervalsFile  string
+	ResourcesDir   string
+
+	genericclioptions.IOStreams
+}
+
+func (o *ResourceWatchIntervalsOptions) Run() error {
+	changes, err := storage.ReadRepositoryHistory(o.RepositoryPath, o.From, o.To)
+	if err != nil {
+		return err
+	}
+	recorder := monitor.NewRecorder()
+	storage.RecordHistory(changes, recorder)
+
+	intervals := recorder.Intervals(time.Time{}, time.Time{})
+	if err := monitorserialization.EventsToFile(o.IntervalsFile, intervals); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Wrote %d intervals for %d changes to %s\n", len(intervals), len(changes), o.IntervalsFile)
+
+	if len(o.ResourcesDir) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(o.ResourcesDir, os.ModePerm); err != nil {
+		return err
+	}
+	for resourceType, instances := range recorder.CurrentResourceState() {
+		filename := filepath.Join(o.ResourcesDir, fmt.Sprintf("resource-%s.zip", resourceType))
+		if err := monitorserialization.InstanceMapToFile(filename, resourceType, instances); err != nil {
+			return err
+		}
+	}
+	return nil
+}
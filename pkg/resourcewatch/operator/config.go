@@ -0,0 +1,346 @@
+package operator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// ResourceWatchConfig selects the resources run-resourcewatch records.
+type ResourceWatchConfig struct {
+	// Resources are resource, resource.group or resource.version.group, like kubectl accepts, or *.group for every
+	// resource of an API group.  When the version is omitted the preferred version of the group is watched.
+	Resources []string `json:"resources"`
+	// Namespaces limits namespaced resources to these namespaces.  All namespaces are watched when empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// ExcludedNamespaces are never watched.
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+	// LabelSelector limits all resources to those with matching labels.
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// Redactions are fields whose values must not be written to the repository, in addition to the data, stringData and
+	// last-applied-configuration annotation of secrets which are always redacted.
+	Redactions []Redaction `json:"redactions,omitempty"`
+}
+
+// Redaction replaces the values of fields of a resource before it is recorded.
+type Redaction struct {
+	// Resource is resource or resource.group.
+	Resource string `json:"resource"`
+	// Fields are dot separated paths, like data.  When a field is a map, like the data of a secret, its keys are
+	// kept so that it is still visible which keys changed.
+	Fields []string `json:"fields"`
+}
+
+const redactedValue = "<redacted>"
+
+// DefaultResourceWatchConfig watches the configuration and operators of the cluster, and the workloads they run.
+func DefaultResourceWatchConfig() *ResourceWatchConfig {
+	return &ResourceWatchConfig{
+		Resources: []string{
+			"apiservers.v1.config.openshift.io",
+			"authentications.v1.config.openshift.io",
+			"builds.v1.config.openshift.io",
+			"clusteroperators.v1.config.openshift.io",
+			"clusterversions.v1.config.openshift.io",
+			"consoles.v1.config.openshift.io",
+			"dnses.v1.config.openshift.io",
+			"featuregates.v1.config.openshift.io",
+			"imagecontentpolicies.v1.config.openshift.io",
+			"images.v1.config.openshift.io",
+			"infrastructures.v1.config.openshift.io",
+			"ingresses.v1.config.openshift.io",
+			"networks.v1.config.openshift.io",
+			"nodes.v1.config.openshift.io",
+			"oauths.v1.config.openshift.io",
+			"operatorhubs.v1.config.openshift.io",
+			"projects.v1.config.openshift.io",
+			"proxies.v1.config.openshift.io",
+			"schedulers.v1.config.openshift.io",
+			"authentications.v1.operator.openshift.io",
+			"cloudcredentials.v1.operator.openshift.io",
+			"clustercsidrivers.v1.operator.openshift.io",
+			"configs.v1.operator.openshift.io",
+			"consoles.v1.operator.openshift.io",
+			"csisnapshotcontrollers.v1.operator.openshift.io",
+			"dnses.v1.operator.openshift.io",
+			"etcds.v1.operator.openshift.io",
+			"imagecontentsourcepolicies.v1.operator.openshift.io",
+			"insightsoperators.v1.operator.openshift.io",
+			"kubeapiservers.v1.operator.openshift.io",
+			"kubecontrollermanagers.v1.operator.openshift.io",
+			"kubeschedulers.v1.operator.openshift.io",
+			"kubestorageversionmigrators.v1.operator.openshift.io",
+			"networks.v1.operator.openshift.io",
+			"openshiftapiservers.v1.operator.openshift.io",
+			"openshiftcontrollermanagers.v1.operator.openshift.io",
+			"servicecas.v1.operator.openshift.io",
+			"storages.v1.operator.openshift.io",
+			"deployments.v1.apps",
+			"daemonsets.v1.apps",
+			"statefulsets.v1.apps",
+			"replicasets.v1.apps",
+			"events.v1.events.k8s.io",
+			"poddisruptionbudgets.v1.policy",
+			"pods",
+			"nodes",
+			"replicationcontrollers",
+			"services",
+			"serviceaccounts",
+		},
+	}
+}
+
+// defaultRedactions are applied whatever the config, so that a config watching secrets cannot write their values to the
+// repository by omitting a redaction.
+var defaultRedactions = []Redaction{
+	{Resource: "secrets", Fields: []string{"data", "stringData"}},
+}
+
+// lastAppliedConfigurationPath is the annotation kubectl apply keeps the applied object in, which for a secret includes
+// its data.  The annotation name contains dots, so it cannot be written as a dot separated Redaction field.
+var lastAppliedConfigurationPath = []string{"metadata", "annotations", corev1.LastAppliedConfigAnnotation}
+
+// ReadResourceWatchConfig reads a ResourceWatchConfig from a YAML or JSON file.
+func ReadResourceWatchConfig(filename string) (*ResourceWatchConfig, error) {
+	configBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config := &ResourceWatchConfig{}
+	if err := yaml.UnmarshalStrict(configBytes, config); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", filename, err)
+	}
+	return config, nil
+}
+
+// Validate checks what can be checked without a cluster.  Resources are checked against discovery when the watch starts.
+func (c *ResourceWatchConfig) Validate() error {
+	if len(c.Resources) == 0 {
+		return fmt.Errorf("at least one resource must be watched")
+	}
+	for _, resource := range c.Resources {
+		if len(resource) == 0 || strings.Contains(resource, "/") {
+			return fmt.Errorf("resource %q must be resource, resource.group, resource.version.group or *.group", resource)
+		}
+	}
+	if _, err := labels.Parse(c.LabelSelector); err != nil {
+		return fmt.Errorf("invalid label selector: %w", err)
+	}
+	for _, redaction := range c.Redactions {
+		if len(redaction.Resource) == 0 || len(redaction.Fields) == 0 {
+			return fmt.Errorf("redactions require a resource and fields: %#v", redaction)
+		}
+	}
+	return nil
+}
+
+// tweakClusterScopedListOptions applies the label selector to lists and watches.  Cluster scoped resources do not
+// support field selectors on their namespace.
+func (c *ResourceWatchConfig) tweakClusterScopedListOptions(options *metav1.ListOptions) {
+	options.LabelSelector = c.LabelSelector
+}
+
+// tweakNamespacedListOptions applies the label selector and namespace exclusions to lists and watches.
+func (c *ResourceWatchConfig) tweakNamespacedListOptions(options *metav1.ListOptions) {
+	options.LabelSelector = c.LabelSelector
+	if len(c.ExcludedNamespaces) == 0 {
+		return
+	}
+	selectors := []fields.Selector{}
+	for _, namespace := range c.ExcludedNamespaces {
+		selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+	}
+	options.FieldSelector = fields.AndSelectors(selectors...).String()
+}
+
+// watchNamespaces are the namespaces namespaced resources are watched in, metav1.NamespaceAll when not restricted.
+func (c *ResourceWatchConfig) watchNamespaces() []string {
+	if len(c.Namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return sets.NewString(c.Namespaces...).Difference(sets.NewString(c.ExcludedNamespaces...)).List()
+}
+
+type watchedResource struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
+// resolveResources finds the resources to watch in discovery.  Resources that are not served are returned separately,
+// so they can be reported instead of retried until they appear.
+func resolveResources(discoveryClient discovery.ServerResourcesInterface, specs []string) ([]watchedResource, []string, error) {
+	groups, resourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, nil, err
+		}
+		// aggregated APIs that are down should not prevent watching everything else.
+		klog.Warningf("Discovery is incomplete: %v", err)
+	}
+
+	preferredVersions := map[string]string{}
+	for _, group := range groups {
+		preferredVersions[group.Name] = group.PreferredVersion.Version
+	}
+	served := map[schema.GroupVersionResource]watchedResource{}
+	servedByGroupVersion := map[schema.GroupVersion][]watchedResource{}
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, resource := range resourceList.APIResources {
+			// subresources and resources that cannot be watched cannot be recorded.
+			if strings.Contains(resource.Name, "/") || !sets.NewString(resource.Verbs...).HasAll("list", "watch") {
+				continue
+			}
+			watched := watchedResource{gvr: groupVersion.WithResource(resource.Name), namespaced: resource.Namespaced}
+			served[watched.gvr] = watched
+			servedByGroupVersion[groupVersion] = append(servedByGroupVersion[groupVersion], watched)
+		}
+	}
+
+	ret := []watchedResource{}
+	seen := map[schema.GroupVersionResource]bool{}
+	add := func(resources ...watchedResource) {
+		for _, resource := range resources {
+			if !seen[resource.gvr] {
+				seen[resource.gvr] = true
+				ret = append(ret, resource)
+			}
+		}
+	}
+	unknown := []string{}
+	for _, spec := range specs {
+		if group, ok := strings.CutPrefix(spec, "*."); ok {
+			resources := servedByGroupVersion[schema.GroupVersion{Group: group, Version: preferredVersions[group]}]
+			if len(resources) == 0 {
+				unknown = append(unknown, spec)
+			}
+			add(resources...)
+			continue
+		}
+
+		gvr, groupResource := schema.ParseResourceArg(spec)
+		if gvr != nil {
+			if resource, ok := served[*gvr]; ok {
+				add(resource)
+				continue
+			}
+		}
+		if resource, ok := served[groupResource.WithVersion(preferredVersions[groupResource.Group])]; ok {
+			add(resource)
+			continue
+		}
+		unknown = append(unknown, spec)
+	}
+	return ret, unknown, nil
+}
+
+type resourceEventHandler interface {
+	OnAdd(gvr schema.GroupVersionResource, obj interface{})
+	OnUpdate(gvr schema.GroupVersionResource, oldObj, obj interface{})
+	OnDelete(gvr schema.GroupVersionResource, obj interface{})
+}
+
+// redactor replaces the values of redacted fields.
+type redactor map[schema.GroupResource][][]string
+
+// newRedactor redacts the fields of redactions, of defaultRedactions and the last applied configuration of secrets.
+func newRedactor(redactions []Redaction) redactor {
+	ret := redactor{
+		{Resource: "secrets"}: {lastAppliedConfigurationPath},
+	}
+	seen := map[schema.GroupResource]sets.String{}
+	for _, redaction := range append(append([]Redaction{}, defaultRedactions...), redactions...) {
+		groupResource := schema.ParseGroupResource(redaction.Resource)
+		if _, ok := seen[groupResource]; !ok {
+			seen[groupResource] = sets.NewString()
+		}
+		for _, field := range redaction.Fields {
+			if seen[groupResource].Has(field) {
+				continue
+			}
+			seen[groupResource].Insert(field)
+			ret[groupResource] = append(ret[groupResource], strings.Split(field, "."))
+		}
+	}
+	return ret
+}
+
+// redact returns a redacted copy, objects from the informer cache must not be modified.
+func (r redactor) redact(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	fieldPaths := r[gvr.GroupResource()]
+	if len(fieldPaths) == 0 || obj == nil {
+		return obj
+	}
+	redacted := obj.DeepCopy()
+	for _, fieldPath := range fieldPaths {
+		value, found, err := unstructured.NestedFieldNoCopy(redacted.Object, fieldPath...)
+		if err != nil || !found {
+			continue
+		}
+		if valueMap, ok := value.(map[string]interface{}); ok {
+			for key := range valueMap {
+				valueMap[key] = redactedValue
+			}
+			continue
+		}
+		// the path was found, so setting it cannot fail.
+		_ = unstructured.SetNestedField(redacted.Object, redactedValue, fieldPath...)
+	}
+	return redacted
+}
+
+// redactingEventHandler removes the values of redacted fields before the objects are recorded.
+type redactingEventHandler struct {
+	delegate resourceEventHandler
+	redactor redactor
+}
+
+func newRedactingEventHandler(delegate resourceEventHandler, redactor redactor) resourceEventHandler {
+	if len(redactor) == 0 {
+		return delegate
+	}
+	return &redactingEventHandler{
+		delegate: delegate,
+		redactor: redactor,
+	}
+}
+
+func (h *redactingEventHandler) OnAdd(gvr schema.GroupVersionResource, obj interface{}) {
+	h.delegate.OnAdd(gvr, h.redact(gvr, obj))
+}
+
+func (h *redactingEventHandler) OnUpdate(gvr schema.GroupVersionResource, oldObj, obj interface{}) {
+	h.delegate.OnUpdate(gvr, h.redact(gvr, oldObj), h.redact(gvr, obj))
+}
+
+func (h *redactingEventHandler) OnDelete(gvr schema.GroupVersionResource, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		tombstone.Obj = h.redact(gvr, tombstone.Obj)
+		h.delegate.OnDelete(gvr, tombstone)
+		return
+	}
+	h.delegate.OnDelete(gvr, h.redact(gvr, obj))
+}
+
+func (h *redactingEventHandler) redact(gvr schema.GroupVersionResource, obj interface{}) interface{} {
+	objUnstructured, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj
+	}
+	return h.redactor.redact(gvr, objUnstructured)
+}
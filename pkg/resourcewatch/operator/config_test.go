@@ -0,0 +1,246 @@
+package operator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestResolveResources(t *testing.T) {
+	watchable := []string{"get", "list", "watch"}
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{
+		Resources: []*metav1.APIResourceList{
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{
+					{Name: "pods", Namespaced: true, Verbs: watchable},
+					{Name: "pods/status", Namespaced: true, Verbs: watchable},
+					{Name: "bindings", Namespaced: true, Verbs: []string{"create"}},
+					{Name: "nodes", Verbs: watchable},
+				},
+			},
+			{
+				GroupVersion: "config.openshift.io/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "clusteroperators", Verbs: watchable},
+					{Name: "clusteroperators/status", Verbs: watchable},
+					{Name: "infrastructures", Verbs: watchable},
+				},
+			},
+			{
+				GroupVersion: "apps/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "deployments", Namespaced: true, Verbs: watchable},
+				},
+			},
+			{
+				GroupVersion: "apps/v1beta1",
+				APIResources: []metav1.APIResource{
+					{Name: "deployments", Namespaced: true, Verbs: watchable},
+				},
+			},
+		},
+	}}
+
+	resolved, unknown, err := resolveResources(discoveryClient, []string{
+		"pods",
+		"bindings",
+		"deployments.apps",
+		"deployments.v1beta1.apps",
+		"*.config.openshift.io",
+		"clusteroperators.config.openshift.io",
+		"clusteroperator.config.openshift.io",
+		"*.operator.openshift.io",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []watchedResource{
+		{gvr: schema.GroupVersionResource{Version: "v1", Resource: "pods"}, namespaced: true},
+		{gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, namespaced: true},
+		{gvr: schema.GroupVersionResource{Group: "apps", Version: "v1beta1", Resource: "deployments"}, namespaced: true},
+		{gvr: schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusteroperators"}},
+		{gvr: schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "infrastructures"}},
+	}
+	if !reflect.DeepEqual(expected, resolved) {
+		t.Errorf("expected\n%v\ngot\n%v", expected, resolved)
+	}
+	expectedUnknown := []string{"bindings", "clusteroperator.config.openshift.io", "*.operator.openshift.io"}
+	if !reflect.DeepEqual(expectedUnknown, unknown) {
+		t.Errorf("expected unknown %v, got %v", expectedUnknown, unknown)
+	}
+}
+
+type recordingEventHandler struct {
+	objects []interface{}
+}
+
+func (h *recordingEventHandler) OnAdd(_ schema.GroupVersionResource, obj interface{}) {
+	h.objects = append(h.objects, obj)
+}
+
+func (h *recordingEventHandler) OnUpdate(_ schema.GroupVersionResource, _, obj interface{}) {
+	h.objects = append(h.objects, obj)
+}
+
+func (h *recordingEventHandler) OnDelete(_ schema.GroupVersionResource, obj interface{}) {
+	h.objects = append(h.objects, obj)
+}
+
+func TestRedactingEventHandler(t *testing.T) {
+	secretsGVR := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "serving-cert", "namespace": "openshift-etcd"},
+		"data":     map[string]interface{}{"tls.crt": "Y2VydA==", "tls.key": "a2V5"},
+		"type":     "kubernetes.io/tls",
+	}}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"data": map[string]interface{}{"ca-bundle.crt": "bundle"},
+	}}
+
+	recorder := &recordingEventHandler{}
+	handler := newRedactingEventHandler(recorder, newRedactor(DefaultResourceWatchConfig().Redactions))
+	handler.OnAdd(secretsGVR, secret)
+	handler.OnDelete(secretsGVR, cache.DeletedFinalStateUnknown{Key: "openshift-etcd/serving-cert", Obj: secret})
+	handler.OnUpdate(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}, configMap, configMap)
+
+	expectedData := map[string]interface{}{"tls.crt": redactedValue, "tls.key": redactedValue}
+	if data := recorder.objects[0].(*unstructured.Unstructured).Object["data"]; !reflect.DeepEqual(expectedData, data) {
+		t.Errorf("expected the added secret to be redacted, got %v", data)
+	}
+	tombstone := recorder.objects[1].(cache.DeletedFinalStateUnknown)
+	if data := tombstone.Obj.(*unstructured.Unstructured).Object["data"]; !reflect.DeepEqual(expectedData, data) {
+		t.Errorf("expected the deleted secret to be redacted, got %v", data)
+	}
+	if recorder.objects[2] != configMap {
+		t.Errorf("expected configmaps to be recorded as they are")
+	}
+	if secret.Object["data"].(map[string]interface{})["tls.key"] != "a2V5" {
+		t.Errorf("the informer's copy of the secret must not be modified")
+	}
+}
+
+func TestSecretsAreRedactedWithAnyConfig(t *testing.T) {
+	secretsGVR := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	configMapsGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(`
+resources:
+- secrets
+- configmaps
+redactions:
+- resource: configmaps
+  fields:
+  - data
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := ReadResourceWatchConfig(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, redactions := range map[string][]Redaction{"without redactions": nil, "with redactions": config.Redactions} {
+		t.Run(name, func(t *testing.T) {
+			recorder := &recordingEventHandler{}
+			handler := newRedactingEventHandler(recorder, newRedactor(redactions))
+			handler.OnAdd(secretsGVR, &unstructured.Unstructured{Object: map[string]interface{}{
+				"data":       map[string]interface{}{"tls.key": "a2V5"},
+				"stringData": map[string]interface{}{"password": "secret"},
+			}})
+			handler.OnAdd(configMapsGVR, &unstructured.Unstructured{Object: map[string]interface{}{
+				"data": map[string]interface{}{"ca-bundle.crt": "bundle"},
+			}})
+
+			secret := recorder.objects[0].(*unstructured.Unstructured).Object
+			if data := secret["data"]; !reflect.DeepEqual(map[string]interface{}{"tls.key": redactedValue}, data) {
+				t.Errorf("expected the data of the secret to be redacted, got %v", data)
+			}
+			if stringData := secret["stringData"]; !reflect.DeepEqual(map[string]interface{}{"password": redactedValue}, stringData) {
+				t.Errorf("expected the stringData of the secret to be redacted, got %v", stringData)
+			}
+			expectedConfigMapData := map[string]interface{}{"ca-bundle.crt": "bundle"}
+			if len(redactions) > 0 {
+				expectedConfigMapData["ca-bundle.crt"] = redactedValue
+			}
+			if data := recorder.objects[1].(*unstructured.Unstructured).Object["data"]; !reflect.DeepEqual(expectedConfigMapData, data) {
+				t.Errorf("expected the data of the configmap to be %v, got %v", expectedConfigMapData, data)
+			}
+		})
+	}
+}
+
+func TestSecretLastAppliedConfigurationIsRedacted(t *testing.T) {
+	secretsGVR := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	// kubectl apply keeps the whole secret, data included, in the last-applied-configuration annotation.
+	appliedSecret := func(password string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      "admin-password",
+				"namespace": "openshift-config",
+				"annotations": map[string]interface{}{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"v1","data":{"password":"` + password + `"},"kind":"Secret","metadata":{"annotations":{},"name":"admin-password","namespace":"openshift-config"}}`,
+					"openshift.io/owning-component":                    "apiserver-auth",
+				},
+			},
+			"data": map[string]interface{}{"password": password},
+		}}
+	}
+
+	recorder := &recordingEventHandler{}
+	handler := newRedactingEventHandler(recorder, newRedactor(nil))
+	handler.OnAdd(secretsGVR, appliedSecret("aHVudGVyMg=="))
+	handler.OnUpdate(secretsGVR, appliedSecret("aHVudGVyMg=="), appliedSecret("Y29ycmVjdC1ob3JzZQ=="))
+
+	expectedAnnotations := map[string]string{
+		"kubectl.kubernetes.io/last-applied-configuration": redactedValue,
+		"openshift.io/owning-component":                    "apiserver-auth",
+	}
+	for i, obj := range recorder.objects {
+		secret := obj.(*unstructured.Unstructured)
+		if annotations := secret.GetAnnotations(); !reflect.DeepEqual(expectedAnnotations, annotations) {
+			t.Errorf("object %d: expected annotations %v, got %v", i, expectedAnnotations, annotations)
+		}
+		recorded, err := secret.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(recorded), "aHVudGVyMg==") || strings.Contains(string(recorded), "Y29ycmVjdC1ob3JzZQ==") {
+			t.Errorf("object %d: the secret data is recorded: %s", i, recorded)
+		}
+	}
+}
+
+func TestTweakListOptions(t *testing.T) {
+	config := &ResourceWatchConfig{
+		Namespaces:         []string{"openshift-etcd", "openshift-kube-apiserver", "kube-system"},
+		ExcludedNamespaces: []string{"kube-system", "openshift-must-gather"},
+		LabelSelector:      "app=etcd",
+	}
+
+	namespaced := &metav1.ListOptions{}
+	config.tweakNamespacedListOptions(namespaced)
+	if namespaced.LabelSelector != "app=etcd" || namespaced.FieldSelector != "metadata.namespace!=kube-system,metadata.namespace!=openshift-must-gather" {
+		t.Errorf("unexpected namespaced list options %#v", namespaced)
+	}
+	clusterScoped := &metav1.ListOptions{}
+	config.tweakClusterScopedListOptions(clusterScoped)
+	if clusterScoped.LabelSelector != "app=etcd" || len(clusterScoped.FieldSelector) > 0 {
+		t.Errorf("unexpected cluster scoped list options %#v", clusterScoped)
+	}
+	if namespaces := config.watchNamespaces(); !reflect.DeepEqual([]string{"openshift-etcd", "openshift-kube-apiserver"}, namespaces) {
+		t.Errorf("unexpected namespaces %v", namespaces)
+	}
+}
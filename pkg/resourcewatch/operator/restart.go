@@ -0,0 +1,155 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// replayTimeout bounds how long a restarted resourcewatch replays the changes it missed before it reconciles.
+	replayTimeout = time.Minute
+	// replayIdleTimeout is how long a replaying watch may not send anything before all the changes are assumed replayed.
+	replayIdleTimeout = 5 * time.Second
+)
+
+// watchScope is the namespace a resource is watched in, with the list options the informers use.
+type watchScope struct {
+	namespace          string
+	excludedNamespaces sets.String
+	labelSelector      labels.Selector
+	tweakListOptions   func(*metav1.ListOptions)
+}
+
+// contains returns whether a recorded object would be listed in this scope.
+func (s watchScope) contains(obj *unstructured.Unstructured) bool {
+	if len(s.namespace) > 0 && obj.GetNamespace() != s.namespace {
+		return false
+	}
+	if s.excludedNamespaces.Has(obj.GetNamespace()) {
+		return false
+	}
+	return s.labelSelector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// catchUp commits the changes made to a resource while resourcewatch was not running, before its informers start.
+// When a previous run stored the resourceVersion it observed last, the missed changes are replayed from a watch
+// starting at it.  That is only possible while the server still has them, so the recorded objects are then reconciled
+// with a fresh list: objects that were deleted, updated or added are committed by storage.RestartAuthor.
+func catchUp(ctx context.Context, dynamicClient dynamic.Interface, gitStorage *storage.GitStorage, redactor redactor, gvr schema.GroupVersionResource, scope watchScope) error {
+	client := dynamicClient.Resource(gvr).Namespace(scope.namespace)
+	listOptions := metav1.ListOptions{}
+	scope.tweakListOptions(&listOptions)
+	current, err := client.List(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+
+	allRecorded, err := gitStorage.RecordedObjects(gvr)
+	if err != nil {
+		return err
+	}
+	recorded := map[string]*unstructured.Unstructured{}
+	for filename, obj := range allRecorded {
+		if scope.contains(obj) {
+			recorded[filename] = obj
+		}
+	}
+
+	if resourceVersion := gitStorage.ResourceVersion(gvr); len(resourceVersion) > 0 && resourceVersion != current.GetResourceVersion() {
+		watchOptions := metav1.ListOptions{ResourceVersion: resourceVersion, AllowWatchBookmarks: true}
+		scope.tweakListOptions(&watchOptions)
+		replayCtx, cancel := context.WithTimeout(ctx, replayTimeout)
+		defer cancel()
+		watcher, err := client.Watch(replayCtx, watchOptions)
+		if err == nil {
+			err = replayEvents(replayCtx, watcher, gvr, current.GetResourceVersion(), recorded, func(oldObj, obj *unstructured.Unstructured, deleted bool) {
+				gitStorage.Record(gvr, oldObj, redactor.redact(gvr, obj), deleted, "")
+			})
+			watcher.Stop()
+		}
+		if err != nil {
+			// expired resourceVersions are expected after long outages, the reconcile still catches up.
+			klog.Warningf("Unable to replay the changes of %s since resourceVersion %s: %v", gvr, resourceVersion, err)
+		}
+	}
+
+	items := []*unstructured.Unstructured{}
+	for i := range current.Items {
+		items = append(items, redactor.redact(gvr, &current.Items[i]))
+	}
+	gitStorage.Reconcile(gvr, recorded, items)
+	gitStorage.ObserveResourceVersion(gvr, current.GetResourceVersion())
+	return gitStorage.SaveResourceVersions()
+}
+
+// replayEvents records the events of a watch until it reaches targetResourceVersion, the resourceVersion of the list
+// the reconcile compares with.  A watch sends the changes since its resourceVersion right away, so when it stays idle
+// they were all replayed.  lastKnown are the recorded objects by filename, they are updated with the replayed changes so
+// the reconcile does not commit them again.
+func replayEvents(ctx context.Context, watcher watch.Interface, gvr schema.GroupVersionResource, targetResourceVersion string, lastKnown map[string]*unstructured.Unstructured, record func(oldObj, obj *unstructured.Unstructured, deleted bool)) error {
+	// resourceVersions are opaque, but they are etcd revisions for every resource served by the kube-apiserver and its
+	// aggregated servers.  When they are not, replaying stops and the reconcile covers the changes.
+	target, err := strconv.ParseUint(targetResourceVersion, 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to compare resourceVersion %q: %w", targetResourceVersion, err)
+	}
+
+	idle := time.NewTimer(replayIdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("replay did not reach resourceVersion %s: %w", targetResourceVersion, ctx.Err())
+		case <-idle.C:
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch closed before reaching resourceVersion %s", targetResourceVersion)
+			}
+			if event.Type == watch.Error {
+				return apierrors.FromObject(event.Object)
+			}
+			obj, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				return fmt.Errorf("unexpected %T in the watch of %s", event.Object, gvr)
+			}
+
+			resourceVersion, err := strconv.ParseUint(obj.GetResourceVersion(), 10, 64)
+			if err != nil {
+				return fmt.Errorf("unable to compare resourceVersion %q: %w", obj.GetResourceVersion(), err)
+			}
+			if resourceVersion > target {
+				// newer than the list, the informers will observe it.
+				return nil
+			}
+
+			filename := storage.ResourceFilename(gvr, obj.GetNamespace(), obj.GetName())
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				record(lastKnown[filename], obj, false)
+				lastKnown[filename] = obj
+			case watch.Deleted:
+				record(nil, obj, true)
+				delete(lastKnown, filename)
+			}
+			if resourceVersion == target {
+				return nil
+			}
+			idle.Reset(replayIdleTimeout)
+		}
+	}
+}
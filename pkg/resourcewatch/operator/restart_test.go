@@ -0,0 +1,145 @@
+package operator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/origin/pkg/resourcewatch/storage"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func clusterOperator(name, resourceVersion string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("config.openshift.io/v1")
+	obj.SetKind("ClusterOperator")
+	obj.SetName(name)
+	obj.SetResourceVersion(resourceVersion)
+	return obj
+}
+
+func TestReplayEvents(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusteroperators"}
+	recordedEtcd := clusterOperator("etcd", "10")
+
+	type recorded struct {
+		name, resourceVersion string
+		hasOld, deleted       bool
+	}
+	tests := []struct {
+		name              string
+		events            []watch.Event
+		expectedRecorded  []recorded
+		expectedLastKnown []string
+		expectedErr       bool
+	}{
+		{
+			name: "stops at the list resourceVersion",
+			events: []watch.Event{
+				{Type: watch.Modified, Object: clusterOperator("etcd", "12")},
+				{Type: watch.Added, Object: clusterOperator("dns", "13")},
+				{Type: watch.Deleted, Object: clusterOperator("etcd", "20")},
+				{Type: watch.Modified, Object: clusterOperator("dns", "21")},
+			},
+			expectedRecorded: []recorded{
+				{name: "etcd", resourceVersion: "12", hasOld: true},
+				{name: "dns", resourceVersion: "13"},
+				{name: "etcd", resourceVersion: "20", deleted: true},
+			},
+			expectedLastKnown: []string{"dns@13"},
+		},
+		{
+			name: "changes newer than the list are left to the informers",
+			events: []watch.Event{
+				{Type: watch.Modified, Object: clusterOperator("etcd", "12")},
+				{Type: watch.Modified, Object: clusterOperator("etcd", "25")},
+			},
+			expectedRecorded: []recorded{
+				{name: "etcd", resourceVersion: "12", hasOld: true},
+			},
+			expectedLastKnown: []string{"etcd@12"},
+		},
+		{
+			name: "expired resourceVersion",
+			events: []watch.Event{
+				{Type: watch.Error, Object: &apierrors.NewResourceExpired("too old resource version").ErrStatus},
+			},
+			expectedLastKnown: []string{"etcd@10"},
+			expectedErr:       true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			watcher := watch.NewFakeWithChanSize(len(test.events), false)
+			for _, event := range test.events {
+				watcher.Action(event.Type, event.Object)
+			}
+			lastKnown := map[string]*unstructured.Unstructured{
+				storage.ResourceFilename(gvr, "", "etcd"): recordedEtcd,
+			}
+
+			actualRecorded := []recorded{}
+			err := replayEvents(context.Background(), watcher, gvr, "20", lastKnown, func(oldObj, obj *unstructured.Unstructured, deleted bool) {
+				actualRecorded = append(actualRecorded, recorded{name: obj.GetName(), resourceVersion: obj.GetResourceVersion(), hasOld: oldObj != nil, deleted: deleted})
+			})
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if test.expectedErr && !apierrors.IsResourceExpired(err) {
+				t.Errorf("expected the expiry to be returned, got %v", err)
+			}
+			if len(test.expectedRecorded) == 0 {
+				test.expectedRecorded = []recorded{}
+			}
+			if !reflect.DeepEqual(test.expectedRecorded, actualRecorded) {
+				t.Errorf("expected recorded\n%v\ngot\n%v", test.expectedRecorded, actualRecorded)
+			}
+			actualLastKnown := []string{}
+			for _, obj := range lastKnown {
+				actualLastKnown = append(actualLastKnown, obj.GetName()+"@"+obj.GetResourceVersion())
+			}
+			if !reflect.DeepEqual(test.expectedLastKnown, actualLastKnown) {
+				t.Errorf("expected last known %v, got %v", test.expectedLastKnown, actualLastKnown)
+			}
+		})
+	}
+}
+
+func TestWatchScopeContains(t *testing.T) {
+	labelSelector, err := labels.Parse("app=etcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scope := watchScope{
+		namespace:          metav1.NamespaceAll,
+		excludedNamespaces: sets.NewString("openshift-must-gather"),
+		labelSelector:      labelSelector,
+	}
+
+	pod := func(namespace string, labels map[string]string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetNamespace(namespace)
+		obj.SetLabels(labels)
+		return obj
+	}
+	if !scope.contains(pod("openshift-etcd", map[string]string{"app": "etcd"})) {
+		t.Errorf("expected matching pods to be in scope")
+	}
+	if scope.contains(pod("openshift-must-gather", map[string]string{"app": "etcd"})) {
+		t.Errorf("expected excluded namespaces to be out of scope")
+	}
+	if scope.contains(pod("openshift-etcd", nil)) {
+		t.Errorf("expected pods that do not match the selector to be out of scope")
+	}
+	scope.namespace = "openshift-kube-apiserver"
+	if scope.contains(pod("openshift-etcd", map[string]string{"app": "etcd"})) {
+		t.Errorf("expected other namespaces to be out of scope")
+	}
+}
@@ -2,24 +2,32 @@ package operator
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
-
-	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
+	"time"
 
 	"github.com/openshift/origin/pkg/resourcewatch/controller/configmonitor"
 	"github.com/openshift/origin/pkg/resourcewatch/storage"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 )
 
-// this doesn't appear to handle restarts cleanly.  To do so it would need to compare the resource version that it is applying
-// to the resource version present and it would need to handle unobserved deletions properly.  both are possible, neither is easy.
-func RunResourceWatch() error {
+// RunResourceWatch commits every change of the configured resources to the git repository at repositoryPath.
+// When the repository was written by a previous run, the changes made while resourcewatch was down are caught up with
+// first, so restarts neither commit the same change twice nor miss deletions.
+func RunResourceWatch(kubeConfig *rest.Config, repositoryPath string, config *ResourceWatchConfig) error {
 	ctx, cancelFn := context.WithCancel(context.Background())
 	defer cancelFn()
 	abortCh := make(chan os.Signal, 2)
@@ -38,21 +46,27 @@ func RunResourceWatch() error {
 	}()
 	signal.Notify(abortCh, syscall.SIGINT, syscall.SIGTERM)
 
-	kubeConfig, err := clusterinfo.GetMonitorRESTConfig()
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
 	if err != nil {
-		klog.Errorf("Failed to get kubeconfig with error %v", err)
+		klog.Errorf("Failed to create dynamic client with error %v", err)
 		return err
 	}
-
-	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(kubeConfig)
 	if err != nil {
-		klog.Errorf("Failed to create dynamic client with error %v", err)
+		klog.Errorf("Failed to create discovery client with error %v", err)
 		return err
 	}
 
-	repositoryPath := "/repository"
-	if repositoryPathEnv := os.Getenv("REPOSITORY_PATH"); len(repositoryPathEnv) > 0 {
-		repositoryPath = repositoryPathEnv
+	resourcesToWatch, unknownResources, err := resolveResources(discoveryClient, config.Resources)
+	if err != nil {
+		klog.Errorf("Failed to discover resources with error %v", err)
+		return err
+	}
+	if len(unknownResources) > 0 {
+		klog.Errorf("Not watching resources that are not served by the cluster, check them for typos: %v", unknownResources)
+	}
+	if len(resourcesToWatch) == 0 {
+		return fmt.Errorf("none of the resources to watch are served by the cluster")
 	}
 
 	gitStorage, err := storage.NewGitStorage(repositoryPath)
@@ -60,112 +74,73 @@ func RunResourceWatch() error {
 		klog.Errorf("Failed to create git storage with error %v", err)
 		return err
 	}
-
-	dynamicInformer := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
-
-	resourcesToWatch := []schema.GroupVersionResource{
-		configResource("apiservers"),
-		configResource("authentications"),
-		configResource("builds"),
-		configResource("clusteroperators"),
-		configResource("clusterversions"),
-		configResource("consoles"),
-		configResource("dnses"),
-		configResource("featuregates"),
-		configResource("imagecontentpolicies"),
-		configResource("images"),
-		configResource("infrastructures"),
-		configResource("ingresses"),
-		configResource("networks"),
-		configResource("nodes"),
-		configResource("oauths"),
-		configResource("operatorhubs"),
-		configResource("projects"),
-		configResource("proxies"),
-		configResource("schedulers"),
-		operatorResource("authentications"),
-		operatorResource("cloudcredentials"),
-		operatorResource("clustercsidrivers"),
-		operatorResource("configs"),
-		operatorResource("consoles"),
-		operatorResource("csisnapshotcontrollers"),
-		operatorResource("dnses"),
-		operatorResource("etcds"),
-		operatorResource("imagecontentsourcepolicies"),
-		operatorResource("insightsoperators"),
-		operatorResource("kubeapiservers"),
-		operatorResource("kubecontrollermanagers"),
-		operatorResource("kubeschedulers"),
-		operatorResource("kubestorageversionmigrators"),
-		operatorResource("networks"),
-		operatorResource("openshiftapiservers"),
-		operatorResource("openshiftcontrollermanagers"),
-		operatorResource("servicecas"),
-		operatorResource("storages"),
-		appResource("deployments"),
-		appResource("daemonsets"),
-		appResource("statefulsets"),
-		appResource("replicasets"),
-		resource("events.k8s.io", "v1", "events"),
-		resource("policy", "v1", "poddisruptionbudgets"),
-		coreResource("pods"),
-		coreResource("nodes"),
-		coreResource("replicationcontrollers"),
-		coreResource("services"),
-		coreResource("serviceaccounts"),
+	redactor := newRedactor(config.Redactions)
+	eventHandler := newRedactingEventHandler(gitStorage, redactor)
+
+	// cluster scoped resources are watched once, namespaced resources once per watched namespace.
+	clusterScoped := []schema.GroupVersionResource{}
+	namespaced := []schema.GroupVersionResource{}
+	for _, resource := range resourcesToWatch {
+		if resource.namespaced {
+			namespaced = append(namespaced, resource.gvr)
+		} else {
+			clusterScoped = append(clusterScoped, resource.gvr)
+		}
+	}
+	labelSelector, err := labels.Parse(config.LabelSelector)
+	if err != nil {
+		return err
+	}
+	dynamicInformers := []dynamicinformer.DynamicSharedInformerFactory{}
+	catchUpWaitGroup := sync.WaitGroup{}
+	watchResources := func(resources []schema.GroupVersionResource, scope watchScope) {
+		for _, resource := range resources {
+			catchUpWaitGroup.Add(1)
+			go func(resource schema.GroupVersionResource) {
+				defer catchUpWaitGroup.Done()
+				if err := catchUp(ctx, dynamicClient, gitStorage, redactor, resource, scope); err != nil {
+					klog.Errorf("Failed to catch up with the changes of %s in %q: %v", resource, scope.namespace, err)
+				}
+			}(resource)
+		}
+		dynamicInformer := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, scope.namespace, scope.tweakListOptions)
+		configmonitor.WireResourceInformersToGitRepo(dynamicInformer, eventHandler, resources)
+		dynamicInformers = append(dynamicInformers, dynamicInformer)
+	}
+	if len(namespaced) > 0 {
+		for _, namespace := range config.watchNamespaces() {
+			watchResources(namespaced, watchScope{
+				namespace:          namespace,
+				excludedNamespaces: sets.NewString(config.ExcludedNamespaces...),
+				labelSelector:      labelSelector,
+				tweakListOptions:   config.tweakNamespacedListOptions,
+			})
+		}
+	}
+	if len(clusterScoped) > 0 {
+		watchResources(clusterScoped, watchScope{
+			namespace:          metav1.NamespaceAll,
+			excludedNamespaces: sets.NewString(),
+			labelSelector:      labelSelector,
+			tweakListOptions:   config.tweakClusterScopedListOptions,
+		})
 	}
 
-	configmonitor.WireResourceInformersToGitRepo(
-		dynamicInformer,
-		gitStorage,
-		resourcesToWatch,
-	)
+	// the changes missed while resourcewatch was down are committed before the informers record the current state.
+	catchUpWaitGroup.Wait()
+	go wait.Until(func() {
+		if err := gitStorage.SaveResourceVersions(); err != nil {
+			klog.Errorf("Failed to save the observed resourceVersions: %v", err)
+		}
+	}, 10*time.Second, ctx.Done())
 
-	dynamicInformer.Start(ctx.Done())
+	for _, dynamicInformer := range dynamicInformers {
+		dynamicInformer.Start(ctx.Done())
+	}
 
 	klog.Infof("Started all informers")
 
 	<-ctx.Done()
 
-	return nil
-}
-
-func configResource(resource string) schema.GroupVersionResource {
-	return schema.GroupVersionResource{
-		Group:    "config.openshift.io",
-		Version:  "v1",
-		Resource: resource,
-	}
-}
-
-func operatorResource(resource string) schema.GroupVersionResource {
-	return schema.GroupVersionResource{
-		Group:    "operator.openshift.io",
-		Version:  "v1",
-		Resource: resource,
-	}
-}
-
-func coreResource(resource string) schema.GroupVersionResource {
-	return schema.GroupVersionResource{
-		Group:    "",
-		Version:  "v1",
-		Resource: resource,
-	}
-}
-
-func resource(group, version, resource string) schema.GroupVersionResource {
-	return schema.GroupVersionResource{
-		Group:    group,
-		Version:  version,
-		Resource: resource,
-	}
-}
-
-func appResource(resource string) schema.GroupVersionResource {
-	return schema.GroupVersionResource{
-		Group:    "apps",
-		Version:  "v1",
-		Resource: resource,
-	}
+	return gitStorage.SaveResourceVersions()
 }
@@ -0,0 +1,318 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/managedfields"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// FieldChange is a change of one field of a resource between two recorded states.
+type FieldChange struct {
+	Commit string
+	Time   time.Time
+	// Path is the field path, like .status.conditions[type=Available].status.
+	Path string
+	// OldValue and NewValue are JSON, empty when the field was added or removed.
+	OldValue string
+	NewValue string
+	// Managers own the field after the change.  When nobody does, like for removed fields, they are the managers
+	// GitStorage guessed for the whole change.
+	Managers []string
+}
+
+// Flap is a field that repeatedly returned to a value it had before, changed by competing managers.
+type Flap struct {
+	Path     string
+	Managers []string
+	// Values are the distinct values the field alternated between.
+	Values  []string
+	Changes int
+	From    time.Time
+	To      time.Time
+}
+
+// ResourceBlame is the field level history of a resource.
+type ResourceBlame struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Changes   []FieldChange
+	Flaps     []Flap
+}
+
+// fields that change with every write and say nothing about who changed what.
+var ignoredFields = sets.NewString(".metadata.managedFields", ".metadata.resourceVersion")
+
+// listKeys are the fields associative lists are commonly keyed by, like conditions by type and containers by name.
+var listKeys = []string{"type", "name"}
+
+// BlameResource computes the field changes between consecutive states of a resource and finds the fields that flap.
+// A field flaps when it returns to a previous value at least minReversions times and more than one manager changed it.
+func BlameResource(history []ObservedState, minReversions int) (*ResourceBlame, error) {
+	ret := &ResourceBlame{}
+	var previous *unstructured.Unstructured
+	for _, state := range history {
+		if state.Object != nil {
+			ret.Kind = state.Object.GetKind()
+			ret.Namespace = state.Object.GetNamespace()
+			ret.Name = state.Object.GetName()
+		}
+
+		var managers managedfields.ManagedInterface
+		if state.Object != nil {
+			var err error
+			if managers, err = managedfields.DecodeManagedFields(state.Object.GetManagedFields()); err != nil {
+				return nil, fmt.Errorf("unable to decode managed fields in %s: %w", state.Commit, err)
+			}
+		}
+
+		for _, change := range diffFields(objectContent(previous), objectContent(state.Object)) {
+			fieldChange := FieldChange{
+				Commit:   state.Commit,
+				Time:     state.Time,
+				Path:     change.path.String(),
+				OldValue: change.oldValue,
+				NewValue: change.newValue,
+			}
+			if managers != nil {
+				fieldChange.Managers = fieldOwners(managers, change.path)
+			}
+			if len(fieldChange.Managers) == 0 {
+				fieldChange.Managers = strings.Split(state.Author, " AND ")
+			}
+			ret.Changes = append(ret.Changes, fieldChange)
+		}
+		previous = state.Object
+	}
+
+	ret.Flaps = findFlaps(ret.Changes, minReversions)
+	return ret, nil
+}
+
+func objectContent(obj *unstructured.Unstructured) interface{} {
+	if obj == nil {
+		return nil
+	}
+	return obj.Object
+}
+
+// fieldOwners returns the managers that own the field or the atomic field that contains it.
+func fieldOwners(managers managedfields.ManagedInterface, path fieldpath.Path) []string {
+	owners := sets.NewString()
+	for manager, managerSet := range managers.Fields() {
+		for i := len(path); i > 0; i-- {
+			if managerSet.Set().Has(path[:i]) {
+				owners.Insert(managerName(manager))
+				break
+			}
+		}
+	}
+	return owners.List()
+}
+
+// managerName is the name of the manager.  Sometimes the entire manager json is listed, likely because subresources
+// are tracked as a key.
+func managerName(manager string) string {
+	currManagerAsJSON := &metav1.ManagedFieldsEntry{}
+	if err := json.Unmarshal([]byte(manager), currManagerAsJSON); err != nil {
+		return manager
+	}
+	return currManagerAsJSON.Manager
+}
+
+type fieldDiff struct {
+	path     fieldpath.Path
+	oldValue string
+	newValue string
+}
+
+// diffFields returns the leaf fields that differ between two objects.  Lists of maps that all have a unique type or
+// name are compared element by element, other lists are compared as a whole.
+func diffFields(oldContent, newContent interface{}) []fieldDiff {
+	ret := []fieldDiff{}
+	diffValues(fieldpath.Path{}, oldContent, newContent, &ret)
+	return ret
+}
+
+func diffValues(path fieldpath.Path, oldContent, newContent interface{}, ret *[]fieldDiff) {
+	if ignoredFields.Has(path.String()) {
+		return
+	}
+	oldMap, oldIsMap := oldContent.(map[string]interface{})
+	newMap, newIsMap := newContent.(map[string]interface{})
+	if (oldIsMap || oldContent == nil) && (newIsMap || newContent == nil) && (oldIsMap || newIsMap) {
+		keys := sets.NewString()
+		for key := range oldMap {
+			keys.Insert(key)
+		}
+		for key := range newMap {
+			keys.Insert(key)
+		}
+		for _, key := range keys.List() {
+			key := key
+			diffValues(appendPath(path, fieldpath.PathElement{FieldName: &key}), oldMap[key], newMap[key], ret)
+		}
+		return
+	}
+
+	oldList, oldIsList := oldContent.([]interface{})
+	newList, newIsList := newContent.([]interface{})
+	if (oldIsList || oldContent == nil) && (newIsList || newContent == nil) && (oldIsList || newIsList) {
+		if listKey, ok := associativeListKey(oldList, newList); ok {
+			oldElements := elementsByKey(oldList, listKey)
+			newElements := elementsByKey(newList, listKey)
+			keys := sets.NewString()
+			for key := range oldElements {
+				keys.Insert(key)
+			}
+			for key := range newElements {
+				keys.Insert(key)
+			}
+			for _, key := range keys.List() {
+				element := fieldpath.PathElement{Key: fieldpath.KeyByFields(listKey, key)}
+				diffValues(appendPath(path, element), oldElements[key], newElements[key], ret)
+			}
+			return
+		}
+	}
+
+	oldValue := jsonValue(oldContent)
+	newValue := jsonValue(newContent)
+	if oldValue != newValue {
+		*ret = append(*ret, fieldDiff{path: path, oldValue: oldValue, newValue: newValue})
+	}
+}
+
+func appendPath(path fieldpath.Path, element fieldpath.PathElement) fieldpath.Path {
+	return append(path.Copy(), element)
+}
+
+// associativeListKey returns the field every element of both lists is uniquely identified by.
+func associativeListKey(oldList, newList []interface{}) (string, bool) {
+	if len(oldList)+len(newList) == 0 {
+		return "", false
+	}
+	for _, listKey := range listKeys {
+		unique := true
+		for _, list := range [][]interface{}{oldList, newList} {
+			seen := sets.NewString()
+			for _, element := range list {
+				elementMap, ok := element.(map[string]interface{})
+				if !ok {
+					return "", false
+				}
+				key, ok := elementMap[listKey].(string)
+				if !ok || seen.Has(key) {
+					unique = false
+					break
+				}
+				seen.Insert(key)
+			}
+		}
+		if unique {
+			return listKey, true
+		}
+	}
+	return "", false
+}
+
+func elementsByKey(list []interface{}, listKey string) map[string]interface{} {
+	ret := map[string]interface{}{}
+	for _, element := range list {
+		ret[element.(map[string]interface{})[listKey].(string)] = element
+	}
+	return ret
+}
+
+func jsonValue(content interface{}) string {
+	if content == nil {
+		return ""
+	}
+	valueJSON, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Sprintf("%v", content)
+	}
+	return string(valueJSON)
+}
+
+func findFlaps(changes []FieldChange, minReversions int) []Flap {
+	changesByPath := map[string][]FieldChange{}
+	for _, change := range changes {
+		changesByPath[change.Path] = append(changesByPath[change.Path], change)
+	}
+
+	ret := []Flap{}
+	for path, pathChanges := range changesByPath {
+		seenValues := sets.NewString(pathChanges[0].OldValue)
+		managers := sets.NewString()
+		reversions := 0
+		for _, change := range pathChanges {
+			if seenValues.Has(change.NewValue) {
+				reversions++
+			}
+			seenValues.Insert(change.NewValue)
+			managers.Insert(change.Managers...)
+		}
+		if reversions < minReversions || managers.Len() < 2 {
+			continue
+		}
+		ret = append(ret, Flap{
+			Path:     path,
+			Managers: managers.List(),
+			Values:   seenValues.List(),
+			Changes:  len(pathChanges),
+			From:     pathChanges[0].Time,
+			To:       pathChanges[len(pathChanges)-1].Time,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Path < ret[j].Path })
+	return ret
+}
+
+// Intervals returns an instant for every field change and an interval for every flapping field, so they can be
+// overlaid on the timeline of a job run.
+func (b *ResourceBlame) Intervals() monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	for _, change := range b.Changes {
+		ret = append(ret, monitorapi.NewInterval(monitorapi.SourceResourceWatch, monitorapi.Info).
+			Locator(monitorapi.NewLocator().ObjectFromNames(b.Kind, b.Namespace, b.Name)).
+			Message(monitorapi.NewMessage().
+				Reason(monitorapi.FieldChangedReason).
+				WithAnnotation(monitorapi.AnnotationField, change.Path).
+				WithAnnotation(monitorapi.AnnotationManagers, strings.Join(change.Managers, ",")).
+				HumanMessagef("changed from %s to %s", displayValue(change.OldValue), displayValue(change.NewValue))).
+			Build(change.Time, change.Time),
+		)
+	}
+	for _, flap := range b.Flaps {
+		ret = append(ret, monitorapi.NewInterval(monitorapi.SourceResourceWatch, monitorapi.Warning).
+			Locator(monitorapi.NewLocator().ObjectFromNames(b.Kind, b.Namespace, b.Name)).
+			Message(monitorapi.NewMessage().
+				Reason(monitorapi.FieldFlappingReason).
+				WithAnnotation(monitorapi.AnnotationField, flap.Path).
+				WithAnnotation(monitorapi.AnnotationManagers, strings.Join(flap.Managers, ",")).
+				WithAnnotation(monitorapi.AnnotationCount, fmt.Sprintf("%d", flap.Changes)).
+				HumanMessagef("changed %d times between %s", flap.Changes, strings.Join(flap.Values, ", "))).
+			Display().
+			Build(flap.From, flap.To),
+		)
+	}
+	return ret
+}
+
+func displayValue(jsonValue string) string {
+	if len(jsonValue) == 0 {
+		return "<unset>"
+	}
+	return jsonValue
+}
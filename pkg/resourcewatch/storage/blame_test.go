@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// operatorState is a deployment whose replicas are owned by manager and its Available condition by the operator.
+func operatorState(manager string, replicas int64, available string) *unstructured.Unstructured {
+	objJSON, err := yaml.YAMLToJSON([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: console
+  namespace: openshift-console
+  managedFields:
+  - manager: ` + manager + `
+    operation: Update
+    apiVersion: apps/v1
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:spec:
+        f:replicas: {}
+  - manager: console-operator
+    operation: Update
+    apiVersion: apps/v1
+    fieldsType: FieldsV1
+    subresource: status
+    fieldsV1:
+      f:status:
+        f:conditions:
+          k:{"type":"Available"}:
+            .: {}
+            f:status: {}
+            f:type: {}
+spec:
+  replicas: 2
+status:
+  conditions:
+  - type: Available
+    status: "` + available + `"
+  - type: Progressing
+    status: "False"
+`))
+	if err != nil {
+		panic(err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(objJSON); err != nil {
+		panic(err)
+	}
+	if err := unstructured.SetNestedField(obj.Object, replicas, "spec", "replicas"); err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+func TestBlameResource(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := []ObservedState{
+		{Commit: "1", Time: start, Author: "kube-controller-manager", Object: operatorState("console-operator", 2, "True")},
+		{Commit: "2", Time: start.Add(time.Minute), Author: "hpa", Object: operatorState("hpa", 3, "True")},
+		{Commit: "3", Time: start.Add(2 * time.Minute), Author: "console-operator", Object: operatorState("console-operator", 2, "False")},
+		{Commit: "4", Time: start.Add(3 * time.Minute), Author: "hpa", Object: operatorState("hpa", 3, "False")},
+		{Commit: "5", Time: start.Add(4 * time.Minute), Author: "console-operator", Object: operatorState("console-operator", 2, "False")},
+		{Commit: "6", Time: start.Add(5 * time.Minute), Author: "unknown"},
+	}
+
+	blame, err := BlameResource(history, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type change struct {
+		Commit, Path, OldValue, NewValue string
+		Managers                         []string
+	}
+	actual := []change{}
+	for _, curr := range blame.Changes {
+		if curr.Commit == "1" || curr.Commit == "6" {
+			// every field is added and removed, only check their count.
+			continue
+		}
+		actual = append(actual, change{curr.Commit, curr.Path, curr.OldValue, curr.NewValue, curr.Managers})
+	}
+	expected := []change{
+		{"2", ".spec.replicas", "2", "3", []string{"hpa"}},
+		{"3", ".spec.replicas", "3", "2", []string{"console-operator"}},
+		{"3", `.status.conditions[type="Available"].status`, `"True"`, `"False"`, []string{"console-operator"}},
+		{"4", ".spec.replicas", "2", "3", []string{"hpa"}},
+		{"5", ".spec.replicas", "3", "2", []string{"console-operator"}},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected changes\n%v\ngot\n%v", expected, actual)
+	}
+	deleted := 0
+	for _, curr := range blame.Changes {
+		if curr.Commit == "6" {
+			deleted++
+			if !reflect.DeepEqual(curr.Managers, []string{"unknown"}) {
+				t.Errorf("expected the removal of %s to be blamed on the author, got %v", curr.Path, curr.Managers)
+			}
+		}
+	}
+	if deleted == 0 {
+		t.Errorf("expected the deletion to remove fields")
+	}
+
+	if len(blame.Flaps) != 1 {
+		t.Fatalf("expected only .spec.replicas to flap, got %#v", blame.Flaps)
+	}
+	flap := blame.Flaps[0]
+	if flap.Path != ".spec.replicas" || flap.Changes != 6 || !reflect.DeepEqual(flap.Managers, []string{"console-operator", "hpa", "unknown"}) {
+		t.Errorf("unexpected flap %#v", flap)
+	}
+
+	intervals := blame.Intervals()
+	last := intervals[len(intervals)-1]
+	if last.Message.Reason != monitorapi.FieldFlappingReason || last.Locator.Keys["deployment"] != "console" ||
+		last.Locator.Keys[monitorapi.LocatorNamespaceKey] != "openshift-console" || !last.From.Equal(start) {
+		t.Errorf("unexpected flapping interval %v", last)
+	}
+}
+
+func TestReadResourceHistory(t *testing.T) {
+	repositoryPath := t.TempDir()
+	repo, err := git.PlainInit(repositoryPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	filename := ResourceFilename(gvr, "openshift-console", "console")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(i int, author string) {
+		signature := &object.Signature{Name: author, Email: "ci-monitor@openshift.io", When: start.Add(time.Duration(i) * time.Minute)}
+		if _, err := worktree.Commit(author, &git.CommitOptions{Author: signature, Committer: signature}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, replicas := range []int64{2, 3} {
+		_, content, err := decodeUnstructuredObject(gvr, operatorState("hpa", replicas, "True"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(repositoryPath, filepath.Dir(filename)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repositoryPath, filename), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(filename); err != nil {
+			t.Fatal(err)
+		}
+		commit(i, "hpa")
+	}
+	// an unrelated resource changing must not show up in the history.
+	if err := os.WriteFile(filepath.Join(repositoryPath, "unrelated.yaml"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("unrelated.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	commit(2, "other")
+	if _, err := worktree.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+	commit(3, "unknown")
+
+	history, err := ReadResourceHistory(repositoryPath, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected 3 states, got %d", len(history))
+	}
+	replicas, _, _ := unstructured.NestedInt64(history[1].Object.Object, "spec", "replicas")
+	if history[0].Object == nil || replicas != 3 || history[2].Object != nil || history[2].Author != "unknown" || !history[2].Time.Equal(start.Add(3*time.Minute)) {
+		t.Errorf("unexpected history %#v", history)
+	}
+}
+
+func TestReadResourceHistoryWithinASecond(t *testing.T) {
+	repositoryPath := t.TempDir()
+	repo, err := git.PlainInit(repositoryPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// git records the time of commits in seconds, a burst of changes is committed with the same time.
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	filename := ResourceFilename(gvr, "openshift-console", "console")
+	signature := &object.Signature{Name: "hpa", Email: "ci-monitor@openshift.io", When: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := os.MkdirAll(filepath.Join(repositoryPath, filepath.Dir(filename)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	expected := []int64{}
+	for replicas := int64(1); replicas <= 10; replicas++ {
+		_, content, err := decodeUnstructuredObject(gvr, operatorState("hpa", replicas, "True"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repositoryPath, filename), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(filename); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Commit("hpa", &git.CommitOptions{Author: signature, Committer: signature}); err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, replicas)
+	}
+
+	history, err := ReadResourceHistory(repositoryPath, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := []int64{}
+	for _, state := range history {
+		replicas, _, _ := unstructured.NestedInt64(state.Object.Object, "spec", "replicas")
+		actual = append(actual, replicas)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected the states in the order they were committed %v, got %v", expected, actual)
+	}
+}
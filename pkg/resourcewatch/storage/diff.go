@@ -1,11 +1,9 @@
 package storage
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/api/meta"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/managedfields"
@@ -44,13 +42,7 @@ func whichUsersOwnModifiedFields(obj *unstructured.Unstructured, comparison type
 	for manager, managerSet := range managers.Fields() {
 		setByThisManager := managerSet.Set().Intersection(comparison.Modified.Union(comparison.Added).Union(comparison.Removed))
 		if !setByThisManager.Empty() {
-			// sometimes I'm seeing the entire manager json listed.  My guess is for subresources its tracked as a key.
-			currManagerAsJSON := &metav1.ManagedFieldsEntry{}
-			if err := json.Unmarshal([]byte(manager), currManagerAsJSON); err != nil {
-				users.Insert(manager)
-			} else {
-				users.Insert(currManagerAsJSON.Manager)
-			}
+			users.Insert(managerName(manager))
 			continue
 		}
 	}
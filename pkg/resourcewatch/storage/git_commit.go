@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/index"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"k8s.io/kube-openapi/pkg/util/sets"
+)
+
+// defaultCommitBatchWindow is how long the changes of a resource are collected before they are committed.  Operators
+// often update the same resource several times in a row, those updates end up in a single commit.
+const defaultCommitBatchWindow = time.Second
+
+const commitEmail = "ci-monitor@openshift.io"
+
+// unknownAuthors are the authors of the changes GitStorage cannot attribute to a manager.
+var unknownAuthors = sets.NewString("unknown", "added-unknown", "modified-unknown")
+
+// pendingChange is the latest state of a file observed since the last commit of the file.
+type pendingChange struct {
+	gvr             schema.GroupVersionResource
+	resourceVersion string
+	path            string
+	ocCommand       string
+	// content is nil when the resource was deleted.
+	content []byte
+	authors sets.String
+	// observed is when the last change was observed, it is used as the time of the commit.
+	observed time.Time
+}
+
+// enqueue records a change of the file at path, to be committed when the batch window of the first pending change
+// ends.  Later changes of the same file replace its content and add their author.
+func (s *GitStorage) enqueue(gvr schema.GroupVersionResource, resourceVersion, path, ocCommand string, content []byte, author string) {
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+
+	if len(s.pending) == 0 {
+		time.AfterFunc(s.commitBatchWindow, s.Flush)
+	}
+	change, ok := s.pending[path]
+	if !ok {
+		change = &pendingChange{gvr: gvr, path: path, authors: sets.NewString()}
+		s.pending[path] = change
+	}
+	change.resourceVersion = resourceVersion
+	change.ocCommand = ocCommand
+	change.content = content
+	change.authors.Insert(strings.Split(author, " AND ")...)
+	change.observed = time.Now()
+}
+
+// Flush commits every pending change, one commit per file.  The resourceVersions of the changes are observed once they
+// are committed.
+func (s *GitStorage) Flush() {
+	// the batch is taken with the repository locked, so that concurrent flushes commit their batches in the order they
+	// took them and an older state of a file is never committed on top of a newer one.
+	s.Lock()
+	defer s.Unlock()
+
+	s.pendingLock.Lock()
+	pending := s.pending
+	s.pending = map[string]*pendingChange{}
+	s.pendingLock.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	changes := []*pendingChange{}
+	for _, change := range pending {
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].observed.Before(changes[j].observed)
+	})
+
+	committed := []*pendingChange{}
+	for _, change := range changes {
+		ok, err := s.commit(change)
+		if err != nil {
+			klog.Errorf("Failed to commit %s: %v", change.path, err)
+			continue
+		}
+		s.resourceVersions.observe(change.gvr, change.resourceVersion)
+		if ok {
+			committed = append(committed, change)
+		}
+	}
+	if err := s.updateIndex(committed); err != nil {
+		// the commits are recorded, only `git status` in the repository is affected.
+		klog.Warningf("Failed to update the index of %s: %v", s.path, err)
+	}
+}
+
+// commit commits the change on top of HEAD without going through the worktree or the index, so files written while
+// committing cannot fail the commit.  It returns false when the change leaves the file as it is in HEAD.
+func (s *GitStorage) commit(change *pendingChange) (bool, error) {
+	headRef, err := s.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return false, err
+	}
+	branch := headRef.Name()
+	if headRef.Type() == plumbing.SymbolicReference {
+		branch = headRef.Target()
+	}
+
+	parents := []plumbing.Hash{}
+	parentTree := plumbing.ZeroHash
+	switch branchRef, err := s.repo.Storer.Reference(branch); {
+	case err == plumbing.ErrReferenceNotFound:
+		// the first commit of the repository.
+	case err != nil:
+		return false, err
+	default:
+		parent, err := object.GetCommit(s.repo.Storer, branchRef.Hash())
+		if err != nil {
+			return false, err
+		}
+		parents = append(parents, parent.Hash)
+		parentTree = parent.TreeHash
+	}
+
+	blobHash := plumbing.ZeroHash
+	if change.content != nil {
+		if blobHash, err = s.storeBlob(change.content); err != nil {
+			return false, err
+		}
+	}
+	treeHash, previousHash, err := s.updateTree(parentTree, strings.Split(filepath.ToSlash(change.path), "/"), blobHash)
+	if err != nil {
+		return false, err
+	}
+
+	var message string
+	switch {
+	case previousHash == blobHash:
+		return false, nil
+	case previousHash.IsZero():
+		message = fmt.Sprintf("added %s", change.ocCommand)
+	case blobHash.IsZero():
+		message = fmt.Sprintf("removed %s", change.ocCommand)
+	default:
+		message = fmt.Sprintf("modifed %s", change.ocCommand)
+	}
+
+	// the managers of a batch are known when any of its changes were attributed.
+	authors := change.authors.Difference(unknownAuthors)
+	if len(authors) == 0 {
+		authors = change.authors
+	}
+	commit := &object.Commit{
+		Author:       object.Signature{Name: strings.Join(authors.List(), " AND "), Email: commitEmail, When: change.observed},
+		Committer:    object.Signature{Name: "ci-monitor", Email: commitEmail, When: change.observed},
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+	}
+	commitObject := s.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(commitObject); err != nil {
+		return false, err
+	}
+	commitHash, err := s.repo.Storer.SetEncodedObject(commitObject)
+	if err != nil {
+		return false, err
+	}
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(branch, commitHash)); err != nil {
+		return false, err
+	}
+
+	klog.Infof("%s -- %s %s", change.path, commit.Author.Name, message)
+	return true, nil
+}
+
+func (s *GitStorage) storeBlob(content []byte) (plumbing.Hash, error) {
+	blob := s.repo.Storer.NewEncodedObject()
+	blob.SetType(plumbing.BlobObject)
+	writer, err := blob.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := writer.Write(content); err != nil {
+		writer.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := writer.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.repo.Storer.SetEncodedObject(blob)
+}
+
+// updateTree stores the tree treeHash with the file at path set to blobHash, or removed when blobHash is zero, and the
+// trees on the way to it.  It returns the hash of the new tree, zero when it is empty, and the previous hash of the
+// file, zero when it did not exist.
+func (s *GitStorage) updateTree(treeHash plumbing.Hash, path []string, blobHash plumbing.Hash) (plumbing.Hash, plumbing.Hash, error) {
+	tree := &object.Tree{}
+	if !treeHash.IsZero() {
+		var err error
+		if tree, err = object.GetTree(s.repo.Storer, treeHash); err != nil {
+			return plumbing.ZeroHash, plumbing.ZeroHash, err
+		}
+	}
+
+	entries := []object.TreeEntry{}
+	current := -1
+	for _, entry := range tree.Entries {
+		if entry.Name == path[0] {
+			current = len(entries)
+		}
+		entries = append(entries, entry)
+	}
+
+	previousHash := plumbing.ZeroHash
+	entryHash := blobHash
+	entryMode := filemode.Regular
+	if len(path) == 1 {
+		if current >= 0 {
+			previousHash = entries[current].Hash
+		}
+	} else {
+		subtreeHash := plumbing.ZeroHash
+		if current >= 0 && entries[current].Mode == filemode.Dir {
+			subtreeHash = entries[current].Hash
+		}
+		var err error
+		if entryHash, previousHash, err = s.updateTree(subtreeHash, path[1:], blobHash); err != nil {
+			return plumbing.ZeroHash, plumbing.ZeroHash, err
+		}
+		entryMode = filemode.Dir
+	}
+	if previousHash == blobHash {
+		return treeHash, previousHash, nil
+	}
+
+	switch {
+	case entryHash.IsZero() && current >= 0:
+		entries = append(entries[:current], entries[current+1:]...)
+	case entryHash.IsZero():
+	case current >= 0:
+		entries[current] = object.TreeEntry{Name: path[0], Mode: entryMode, Hash: entryHash}
+	default:
+		entries = append(entries, object.TreeEntry{Name: path[0], Mode: entryMode, Hash: entryHash})
+	}
+	if len(entries) == 0 {
+		return plumbing.ZeroHash, previousHash, nil
+	}
+
+	// git orders the entries of a tree by name, as if the names of trees ended with a slash.
+	sortName := func(entry object.TreeEntry) string {
+		if entry.Mode == filemode.Dir {
+			return entry.Name + "/"
+		}
+		return entry.Name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortName(entries[i]) < sortName(entries[j])
+	})
+
+	treeObject := s.repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(treeObject); err != nil {
+		return plumbing.ZeroHash, plumbing.ZeroHash, err
+	}
+	newTreeHash, err := s.repo.Storer.SetEncodedObject(treeObject)
+	if err != nil {
+		return plumbing.ZeroHash, plumbing.ZeroHash, err
+	}
+	return newTreeHash, previousHash, nil
+}
+
+// updateIndex stages the committed files, so that the worktree, the index and HEAD agree when the repository is
+// inspected with git.
+func (s *GitStorage) updateIndex(committed []*pendingChange) error {
+	if len(committed) == 0 {
+		return nil
+	}
+	idx, err := s.repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	for _, change := range committed {
+		path := filepath.ToSlash(change.path)
+		if change.content == nil {
+			if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+				return err
+			}
+			continue
+		}
+
+		entry, err := idx.Entry(path)
+		if err == index.ErrEntryNotFound {
+			entry = idx.Add(path)
+		} else if err != nil {
+			return err
+		}
+		entry.Hash = plumbing.ComputeHash(plumbing.BlobObject, change.content)
+		entry.Mode = filemode.Regular
+		entry.Size = uint32(len(change.content))
+		// leaving the stat data of a file written since the commit zero makes git compare its content.
+		entry.ModifiedAt = time.Time{}
+		fullPath := filepath.Join(s.path, change.path)
+		if info, err := os.Stat(fullPath); err == nil {
+			if content, err := os.ReadFile(fullPath); err == nil && bytes.Equal(content, change.content) {
+				entry.ModifiedAt = info.ModTime()
+			}
+		}
+	}
+	return s.repo.Storer.SetIndex(idx)
+}
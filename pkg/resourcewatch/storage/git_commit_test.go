@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// commitLog returns the "author subject" of every commit of the repository, newest first.
+func commitLog(t testing.TB, repositoryPath string) []string {
+	repo, err := git.PlainOpen(repositoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret := []string{}
+	if err := commits.ForEach(func(commit *object.Commit) error {
+		ret = append(ret, commit.Author.Name+" "+commit.Message)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ret
+}
+
+func TestBatchedCommits(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	repositoryPath := t.TempDir()
+	gitStorage, err := NewGitStorage(repositoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitStorage.commitBatchWindow = time.Hour
+
+	console := operatorState("console-operator", 2, "True")
+	console.SetResourceVersion("1")
+	gitStorage.Record(gvr, nil, console, false, "")
+
+	// a burst of changes to the same deployment.
+	scaled := operatorState("kube-controller-manager", 3, "True")
+	scaled.SetResourceVersion("2")
+	gitStorage.handle(gvr, console, scaled, false, "")
+	unavailable := operatorState("kube-controller-manager", 3, "False")
+	unavailable.SetResourceVersion("3")
+	gitStorage.handle(gvr, scaled, unavailable, false, "")
+	downloads := operatorState("console-operator", 1, "True")
+	downloads.SetName("downloads")
+	downloads.SetResourceVersion("4")
+	gitStorage.handle(gvr, nil, downloads, false, "")
+	gitStorage.handle(gvr, nil, downloads, true, "")
+	if actual := gitStorage.ResourceVersion(gvr); actual != "1" {
+		t.Errorf("expected the resourceVersions of uncommitted changes to not be observed, got %q", actual)
+	}
+	gitStorage.Flush()
+
+	expected := []string{
+		"kube-controller-manager modifed deployments.apps/console -n openshift-console",
+		"console-operator added deployments.apps/console -n openshift-console",
+	}
+	if actual := commitLog(t, repositoryPath); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected commits\n%v\ngot\n%v", expected, actual)
+	}
+	if actual := gitStorage.ResourceVersion(gvr); actual != "4" {
+		t.Errorf("expected the last committed resourceVersion to be observed, got %q", actual)
+	}
+
+	history, err := ReadResourceHistory(repositoryPath, ResourceFilename(gvr, "openshift-console", "console"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := unstructured.NestedSlice(history[1].Object.Object, "status", "conditions"); !reflect.DeepEqual(unavailable.Object["status"].(map[string]interface{})["conditions"], status) {
+		t.Errorf("expected the last state of the burst to be committed, got %v", status)
+	}
+
+	repo, err := git.PlainOpen(repositoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.IsClean() {
+		t.Errorf("expected the worktree and the index to match the commits, got\n%v", status)
+	}
+	if _, err := exec.LookPath("git"); err == nil {
+		if output, err := exec.Command("git", "-C", repositoryPath, "fsck", "--strict").CombinedOutput(); err != nil {
+			t.Errorf("the repository is corrupted: %v\n%s", err, output)
+		}
+	}
+}
+
+func TestConcurrentFlushes(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	repositoryPath := t.TempDir()
+	gitStorage, err := NewGitStorage(repositoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitStorage.commitBatchWindow = time.Hour
+
+	// a flush must not take the batch while another one is committing, or it may commit it before the older batch.
+	first := operatorState("console-operator", 1, "True")
+	first.SetResourceVersion("1")
+	gitStorage.Lock()
+	gitStorage.handle(gvr, nil, first, false, "")
+	flushed := make(chan struct{})
+	go func() {
+		defer close(flushed)
+		gitStorage.Flush()
+	}()
+	time.Sleep(100 * time.Millisecond)
+	gitStorage.pendingLock.Lock()
+	pending := len(gitStorage.pending)
+	gitStorage.pendingLock.Unlock()
+	gitStorage.Unlock()
+	<-flushed
+	if pending != 1 {
+		t.Errorf("expected the batch to wait for the commit in progress")
+	}
+
+	// the batch timer, SaveResourceVersions and the catch up of Record flush concurrently with the changes.
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					gitStorage.Flush()
+				}
+			}
+		}()
+	}
+	const changes = 200
+	previous := first
+	for i := 2; i <= changes; i++ {
+		current := operatorState("console-operator", int64(i), "True")
+		current.SetResourceVersion(strconv.Itoa(i))
+		gitStorage.handle(gvr, previous, current, false, "")
+		previous = current
+	}
+	close(stop)
+	wg.Wait()
+	gitStorage.Flush()
+
+	history, err := ReadResourceHistory(repositoryPath, ResourceFilename(gvr, "openshift-console", "console"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := 0
+	for _, state := range history {
+		resourceVersion, err := strconv.Atoi(state.Object.GetResourceVersion())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resourceVersion <= last {
+			t.Fatalf("expected the changes to be committed in order, resourceVersion %d was committed after %d", resourceVersion, last)
+		}
+		last = resourceVersion
+	}
+	if last != changes {
+		t.Errorf("expected the last change to be committed last, got resourceVersion %d", last)
+	}
+}
+
+// eventStorm returns count changes spread over a few hundred deployments, as seen during upgrades.
+func eventStorm(count int) []*unstructured.Unstructured {
+	ret := []*unstructured.Unstructured{}
+	for i := 0; i < count; i++ {
+		obj := operatorState("kube-controller-manager", int64(i), strconv.FormatBool(i%2 == 0))
+		obj.SetName(fmt.Sprintf("deployment-%d", i%200))
+		obj.SetNamespace(fmt.Sprintf("namespace-%d", i%20))
+		obj.SetResourceVersion(strconv.Itoa(i))
+		ret = append(ret, obj)
+	}
+	return ret
+}
+
+// BenchmarkEventStorm compares committing every change with `git add && git commit`, like GitStorage used to, with
+// batching them through the object API.
+func BenchmarkEventStorm(b *testing.B) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	events := eventStorm(1000)
+
+	b.Run("git-cli", func(b *testing.B) {
+		if _, err := exec.LookPath("git"); err != nil {
+			b.Skip("git is required to commit")
+		}
+		b.Setenv("GIT_COMMITTER_NAME", "ci-monitor")
+		b.Setenv("GIT_COMMITTER_EMAIL", commitEmail)
+		commits := 0
+		for i := 0; i < b.N; i++ {
+			repositoryPath := b.TempDir()
+			if _, err := git.PlainInit(repositoryPath, false); err != nil {
+				b.Fatal(err)
+			}
+			for _, obj := range events {
+				filename, content, err := decodeUnstructuredObject(gvr, obj)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := os.MkdirAll(filepath.Join(repositoryPath, filepath.Dir(filename)), os.ModePerm); err != nil {
+					b.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(repositoryPath, filename), content, 0644); err != nil {
+					b.Fatal(err)
+				}
+				command := fmt.Sprintf(`git add %q && git commit --author=%q -m %q`, filename, "kube-controller-manager <"+commitEmail+">", "modifed "+filename)
+				osCommand := exec.Command("bash", "-e", "-c", command)
+				osCommand.Dir = repositoryPath
+				if output, err := osCommand.CombinedOutput(); err != nil {
+					b.Fatalf("%v: %s", err, output)
+				}
+			}
+			commits += len(commitLog(b, repositoryPath))
+		}
+		b.ReportMetric(float64(commits)/float64(b.N), "commits/op")
+	})
+
+	b.Run("object-api-batched", func(b *testing.B) {
+		commits := 0
+		for i := 0; i < b.N; i++ {
+			repositoryPath := b.TempDir()
+			gitStorage, err := NewGitStorage(repositoryPath)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, obj := range events {
+				gitStorage.handle(gvr, nil, obj, false, "kube-controller-manager")
+			}
+			gitStorage.Flush()
+			commits += len(commitLog(b, repositoryPath))
+		}
+		b.ReportMetric(float64(commits)/float64(b.N), "commits/op")
+	})
+}
@@ -3,7 +3,6 @@ package storage
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -61,23 +60,20 @@ type GitStorage struct {
 	path string
 
 	currentlyRecording workingSet
+	resourceVersions   *resourceVersions
+
+	// changes are committed per file once they stopped arriving for commitBatchWindow.
+	commitBatchWindow time.Duration
+	pending           map[string]*pendingChange
+	pendingLock       sync.Mutex
 
 	// Writing to Git repository must be synced otherwise Git will freak out
 	sync.Mutex
 }
 
-type gitOperation int
-
-const (
-	gitOpAdded gitOperation = iota
-	gitOpModified
-	gitOpDeleted
-	gitOpError
-)
-
 // NewGitStorage returns the resource event handler capable of storing changes observed on resource
-// into a Git repository. Each change is stored as separate commit which means a full history of the
-// resource lifecycle is preserved.
+// into a Git repository. Changes are stored as separate commits which means a full history of the
+// resource lifecycle is preserved, only the changes of a resource made within a second are committed together.
 func NewGitStorage(path string) (*GitStorage, error) {
 	// If the repo does not exists, do git init
 	if _, err := os.Stat(filepath.Join(path, ".git")); os.IsNotExist(err) {
@@ -90,33 +86,29 @@ func NewGitStorage(path string) (*GitStorage, error) {
 	if err != nil {
 		return nil, err
 	}
-	storage := &GitStorage{path: path, repo: repo}
+	resourceVersions, err := loadResourceVersions(path)
+	if err != nil {
+		return nil, err
+	}
+	storage := &GitStorage{
+		path:              path,
+		repo:              repo,
+		resourceVersions:  resourceVersions,
+		commitBatchWindow: defaultCommitBatchWindow,
+		pending:           map[string]*pendingChange{},
+	}
 	storage.currentlyRecording.currentlyWorking = sets.String{}
 
 	return storage, nil
 }
 
-// handle handles different operations on git
-func (s *GitStorage) handle(gvr schema.GroupVersionResource, oldObj, obj *unstructured.Unstructured, delete bool) {
+// handle writes a change to the worktree and queues its commit.  When author is empty, it is guessed from the fields
+// that changed.
+func (s *GitStorage) handle(gvr schema.GroupVersionResource, oldObj, obj *unstructured.Unstructured, delete bool, author string) {
 	// notifications for resources come in a single threaded stream per-resource.
 	// this means there will never be contention on a single file.
-	// we will lock just before the commit itself.
-
-	// Allowing files to be written while a commit is in progress leads to commit failures due to unstaged changes
-	// moving the lock to the top of the handler to make the action atomic.
-	// E0706 02:06:26.489945    2444 git_store.go:338] Ran git add "namespaces/openshift-cloud-credential-operator/core/services/cco-metrics.yaml" && git commit --author="modified-unknown <ci-monitor@openshift.io>" -m "modifed services/cco-metrics -n openshift-cloud-credential-operator"
-	// On branch master
-	// Changes not staged for commit:
-	//  (use "git add <file>..." to update what will be committed)
-	//  (use "git restore <file>..." to discard changes in working directory)
-	//	modified:   namespaces/openshift-apiserver/core/pods/apiserver-856c47d994-47cf7.yaml
-	//
-	// Untracked files:
-	//  (use "git add <file>..." to include in what will be committed)
-	//	namespaces/openshift-etcd/core/pods/etcd-ci-op-g2xjpfr4-ed5cd-gqjcq-master-0.yaml
-
-	s.Lock()
-	defer s.Unlock()
+	// commits are built from the content of the changes rather than from the worktree, so writing other files while
+	// committing does not fail the commits with unstaged changes like running `git add && git commit` did.
 
 	filePath, content, err := decodeUnstructuredObject(gvr, obj)
 	if err != nil {
@@ -137,62 +129,32 @@ func (s *GitStorage) handle(gvr schema.GroupVersionResource, oldObj, obj *unstru
 	}
 
 	if delete {
-		klog.Infof("Calling commitRemove for %s", filePath)
-		// ignore error, we've already reported and we're not doing anything else.
-		pollErr := wait.PollImmediate(1*time.Second, 15*time.Second, func() (bool, error) {
-			if err := s.commitRemove(filePath, "unknown", ocCommand); err != nil {
-				klog.Error(err)
-				return false, nil
-			}
-			return true, nil
-		})
-
-		if pollErr != nil {
-			klog.Errorf("PollWait Error: %v", pollErr)
+		deletingUser := "unknown"
+		if len(author) > 0 {
+			deletingUser = author
 		}
-
+		if err := os.Remove(filepath.Join(s.path, filePath)); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("Removing file failed %q: %v", filePath, err)
+			return
+		}
+		s.enqueue(gvr, obj.GetResourceVersion(), filePath, ocCommand, nil, deletingUser)
 		return
 	}
 
-	klog.Infof("Calling write for %s", filePath)
-	operation, err := s.write(filePath, content)
-	if err != nil {
+	if err := s.write(filePath, content); err != nil {
 		klog.Warningf("Writing file content failed %q: %v", filePath, err)
 		return
 	}
 
-	modifyingUser, err := guessAtModifyingUsers(oldObj, obj)
-	if err != nil {
-		klog.Warningf("Guessing users failed %q: %v", filePath, err)
-		modifyingUser = err.Error()
-	}
-
-	// ignore error, we've already reported and we're not doing anything else.
-	pollErr := wait.PollImmediate(1*time.Second, 15*time.Second, func() (bool, error) {
-		switch {
-		case operation == gitOpAdded:
-			klog.Infof("Calling commitAdd for %s", filePath)
-			if err := s.commitAdd(filePath, modifyingUser, ocCommand); err != nil {
-				klog.Error(err)
-				return false, nil
-			}
-		case operation == gitOpModified:
-			klog.Infof("Calling commitModify for %s", filePath)
-			if err := s.commitModify(filePath, modifyingUser, ocCommand); err != nil {
-				klog.Error(err)
-				return false, nil
-			}
-		default:
-			klog.Errorf("unhandled case for %s", filePath)
-
-			return true, nil
+	modifyingUser := author
+	if len(modifyingUser) == 0 {
+		modifyingUser, err = guessAtModifyingUsers(oldObj, obj)
+		if err != nil {
+			klog.Warningf("Guessing users failed %q: %v", filePath, err)
+			modifyingUser = err.Error()
 		}
-		return true, nil
-	})
-
-	if pollErr != nil {
-		klog.Errorf("PollWait Error: %v", pollErr)
 	}
+	s.enqueue(gvr, obj.GetResourceVersion(), filePath, ocCommand, content, modifyingUser)
 }
 
 func (s *GitStorage) OnAdd(gvr schema.GroupVersionResource, obj interface{}) {
@@ -209,7 +171,7 @@ func (s *GitStorage) OnAdd(gvr schema.GroupVersionResource, obj interface{}) {
 	// start new go func to allow parallel processing where possible and to avoid blocking all progress on retries.
 	go func() {
 		defer s.currentlyRecording.release(key)
-		s.handle(gvr, nil, objUnstructured, false)
+		s.handle(gvr, nil, objUnstructured, false, "")
 	}()
 }
 
@@ -228,7 +190,7 @@ func (s *GitStorage) OnUpdate(gvr schema.GroupVersionResource, oldObj, obj inter
 	// start new go func to allow parallel processing where possible and to avoid blocking all progress on retries.
 	go func() {
 		defer s.currentlyRecording.release(key)
-		s.handle(gvr, oldObjUnstructured, objUnstructured, false)
+		s.handle(gvr, oldObjUnstructured, objUnstructured, false, "")
 	}()
 }
 
@@ -258,7 +220,7 @@ func (s *GitStorage) OnDelete(gvr schema.GroupVersionResource, obj interface{})
 	// start new go func to allow parallel processing where possible and to avoid blocking all progress on retries.
 	go func() {
 		defer s.currentlyRecording.release(key)
-		s.handle(gvr, nil, objUnstructured, true)
+		s.handle(gvr, nil, objUnstructured, true, "")
 	}()
 }
 
@@ -322,113 +284,11 @@ func resourceFilename(gvr schema.GroupVersionResource, namespace, name string) s
 	return filepath.Join("namespaces", namespace, groupStr, gvr.Resource, name+".yaml")
 }
 
-func (s *GitStorage) commitAdd(path, author, ocCommand string) error {
-	authorString := fmt.Sprintf("%s <ci-monitor@openshift.io>", author)
-	commitMessage := fmt.Sprintf("added %s", ocCommand)
-	command := fmt.Sprintf(`git add %q && git commit --author=%q -m %q`, path, authorString, commitMessage)
-
-	osCommand := exec.Command("bash", "-e", "-c", command)
-	osCommand.Dir = s.path
-	output, err := osCommand.CombinedOutput()
-	if err != nil {
-		klog.Errorf("Ran %v\n%v\n\n", command, string(output))
-		// sometimes git can leave behind an index.lock.  This process should be the only one working in this git repo
-		// so simply remove the lock file.
-		if _, statErr := os.Stat(filepath.Join(s.path, ".git/index.lock")); statErr == nil {
-			if deleteErr := os.Remove(filepath.Join(s.path, ".git/index.lock")); deleteErr != nil {
-				klog.Errorf("Error removing .git/index.lock: %v", deleteErr)
-			}
-		}
-		return err
-	}
-
-	klog.Infof("Add: %v -- %v added %v", path, author, ocCommand)
-	return nil
-}
-
-func (s *GitStorage) commitModify(path, author, ocCommand string) error {
-	authorString := fmt.Sprintf("%s <ci-monitor@openshift.io>", author)
-	commitMessage := fmt.Sprintf("modifed %s", ocCommand)
-	command := fmt.Sprintf(`git add %q && git commit --author=%q -m %q`, path, authorString, commitMessage)
-
-	osCommand := exec.Command("bash", "-e", "-c", command)
-	osCommand.Dir = s.path
-	output, err := osCommand.CombinedOutput()
-	if err != nil {
-		klog.Errorf("Ran %v\n%v\n\n", command, string(output))
-		// sometimes git can leave behind an index.lock.  This process should be the only one working in this git repo
-		// so simply remove the lock file.
-		if _, statErr := os.Stat(filepath.Join(s.path, ".git/index.lock")); statErr == nil {
-			if deleteErr := os.Remove(filepath.Join(s.path, ".git/index.lock")); deleteErr != nil {
-				klog.Errorf("Error removing .git/index.lock: %v", deleteErr)
-			}
-		}
-		// if nothing changed in the modify don't keep trying over and over
-		if strings.Contains(string(output), "nothing to commit") {
-			klog.Info("Exiting commitModify as nothing to commit")
-			return nil
-		}
-
-		return err
-	}
-
-	if output != nil {
-		klog.Infof("Ran %v\n%v\n\n", command, string(output))
-	}
-
-	klog.Infof("Modified: %v -- %v updated %v", path, author, ocCommand)
-	return nil
-}
-
-func (s *GitStorage) commitRemove(path, author, ocCommand string) error {
-	authorString := fmt.Sprintf("%s <ci-monitor@openshift.io>", author)
-	commitMessage := fmt.Sprintf("removed %s", ocCommand)
-	command := fmt.Sprintf(`rm %q && git rm %q && git commit --author=%q -m %q`, path, path, authorString, commitMessage)
-
-	osCommand := exec.Command("bash", "-e", "-c", command)
-	osCommand.Dir = s.path
-	output, err := osCommand.CombinedOutput()
-	if err != nil {
-		klog.Errorf("Ran %v\n%v\n\n", command, string(output))
-		// sometimes git can leave behind an index.lock.  This process should be the only one working in this git repo
-		// so simply remove the lock file.
-		if _, statErr := os.Stat(filepath.Join(s.path, ".git/index.lock")); statErr == nil {
-			if deleteErr := os.Remove(filepath.Join(s.path, ".git/index.lock")); deleteErr != nil {
-				klog.Errorf("Error removing .git/index.lock: %v", deleteErr)
-			}
-		}
-		return err
-	}
-
-	klog.Infof("Removed: %v -- %v deleted %v", path, author, ocCommand)
-	return nil
-}
-
 // write handle writing the content into git repository
-func (s *GitStorage) write(name string, content []byte) (gitOperation, error) {
+func (s *GitStorage) write(name string, content []byte) error {
 	fullPath := filepath.Join(s.path, name)
-
-	fileMode := os.FileMode(0644)
-
-	// If the file does not exist, create it and report it as new file
-	// This will get reflected in the commit message
-	if _, err := os.Lstat(fullPath); err != nil {
-		if !os.IsNotExist(err) {
-			return gitOpError, err
-		}
-
-		if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
-			return gitOpError, err
-		}
-		if err := os.WriteFile(fullPath, content, fileMode); err != nil {
-			return gitOpError, err
-		}
-		return gitOpAdded, nil
-	}
-
-	// If the file exists, updated its content and report modified
-	if err := os.WriteFile(fullPath, content, fileMode); err != nil {
-		return gitOpError, err
+	if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
+		return err
 	}
-	return gitOpModified, nil
+	return os.WriteFile(fullPath, content, os.FileMode(0644))
 }
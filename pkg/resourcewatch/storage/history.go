@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// ObservedState is the state of a resource recorded by one commit of the resourcewatch repository.
+type ObservedState struct {
+	Commit string
+	// Time is when the change was committed, shortly after it was observed.
+	Time time.Time
+	// Author is the guess of GitStorage at the managers that made the change.
+	Author string
+	// Object is nil when the resource was deleted.
+	Object *unstructured.Unstructured
+}
+
+// ResourceFilename is where GitStorage records a resource in the repository.
+func ResourceFilename(gvr schema.GroupVersionResource, namespace, name string) string {
+	return resourceFilename(gvr, namespace, name)
+}
+
+// ReadResourceHistory returns every state of the resource recorded in the resourcewatch repository at repositoryPath,
+// oldest first.
+func ReadResourceHistory(repositoryPath, filename string) ([]ObservedState, error) {
+	repo, err := git.PlainOpen(repositoryPath)
+	if err != nil {
+		return nil, err
+	}
+	// the log follows the parents of the commits, git only records their time in seconds and the changes of a burst are
+	// committed within the same second.
+	commits, err := repo.Log(&git.LogOptions{FileName: &filename})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []ObservedState{}
+	err = commits.ForEach(func(commit *object.Commit) error {
+		state := ObservedState{
+			Commit: commit.Hash.String(),
+			Time:   commit.Committer.When,
+			Author: commit.Author.Name,
+		}
+		file, err := commit.File(filename)
+		switch {
+		case errors.Is(err, object.ErrFileNotFound):
+			ret = append(ret, state)
+			return nil
+		case err != nil:
+			return err
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		obj, err := decodeRecordedObject([]byte(content))
+		if err != nil {
+			return fmt.Errorf("unable to decode %s in %s: %w", filename, state.Commit, err)
+		}
+		state.Object = obj
+		ret = append(ret, state)
+		return nil
+	})
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("%s was never recorded in %s", filename, repositoryPath)
+	}
+
+	// the log is newest first.
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret, nil
+}
+
+// decodeRecordedObject decodes a resource written by GitStorage.  Going through JSON keeps integers int64 like they are
+// in the objects of the informers.
+func decodeRecordedObject(content []byte) (*unstructured.Unstructured, error) {
+	objJSON, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(objJSON); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
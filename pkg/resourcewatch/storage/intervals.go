@@ -0,0 +1,314 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceChange is the change of a resource recorded by one commit of the resourcewatch repository.
+type ResourceChange struct {
+	// ObservedState is the state after the change, its Object is nil when the resource was deleted.
+	ObservedState
+	// Previous is the state before the change, nil when the resource was created.
+	Previous *unstructured.Unstructured
+
+	Filename      string
+	GroupResource schema.GroupResource
+	Namespace     string
+	Name          string
+}
+
+// ReadRepositoryHistory returns every change recorded in the resourcewatch repository at repositoryPath that was
+// committed between from and to, oldest first.  Zero times do not bound the history.
+func ReadRepositoryHistory(repositoryPath string, from, to time.Time) ([]ResourceChange, error) {
+	repo, err := git.PlainOpen(repositoryPath)
+	if err != nil {
+		return nil, err
+	}
+	// GitStorage commits one change after the other, following the parents keeps the changes committed within the
+	// same second in order.
+	commits, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []ResourceChange{}
+	err = commits.ForEach(func(commit *object.Commit) error {
+		when := commit.Committer.When
+		if !from.IsZero() && when.Before(from) {
+			return storer.ErrStop
+		}
+		if !to.IsZero() && when.After(to) {
+			return nil
+		}
+		changes, err := commitChanges(commit)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", commit.Hash, err)
+		}
+		// the log is newest first, the changes are reversed with the commits below.
+		for i := len(changes) - 1; i >= 0; i-- {
+			ret = append(ret, changes[i])
+		}
+		return nil
+	})
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	for i, j := 0, len(ret)-1; i < j; i, j = i+1, j-1 {
+		ret[i], ret[j] = ret[j], ret[i]
+	}
+	return ret, nil
+}
+
+// commitChanges returns the changes of the resources made by a commit.  GitStorage commits one resource at a time, but
+// any commit of the repository is read.
+func commitChanges(commit *object.Commit) ([]ResourceChange, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []ResourceChange{}
+	for _, change := range changes {
+		filename := change.To.Name
+		if len(filename) == 0 {
+			filename = change.From.Name
+		}
+		groupResource, namespace, name, ok := parseResourceFilename(filename)
+		if !ok {
+			continue
+		}
+		fromFile, toFile, err := change.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		resourceChange := ResourceChange{
+			ObservedState: ObservedState{
+				Commit: commit.Hash.String(),
+				Time:   commit.Committer.When,
+				Author: commit.Author.Name,
+			},
+			Filename:      filename,
+			GroupResource: groupResource,
+			Namespace:     namespace,
+			Name:          name,
+		}
+		if resourceChange.Previous, err = decodeFile(fromFile); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", filename, err)
+		}
+		if resourceChange.Object, err = decodeFile(toFile); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", filename, err)
+		}
+		ret = append(ret, resourceChange)
+	}
+	return ret, nil
+}
+
+func decodeFile(file *object.File) (*unstructured.Unstructured, error) {
+	if file == nil {
+		return nil, nil
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecordedObject([]byte(content))
+}
+
+// parseResourceFilename is the reverse of resourceFilename.
+func parseResourceFilename(filename string) (schema.GroupResource, string, string, bool) {
+	parts := strings.Split(filepath.ToSlash(filename), "/")
+	namespace := ""
+	switch {
+	case len(parts) == 4 && parts[0] == "cluster-scoped-resources":
+		parts = parts[1:]
+	case len(parts) == 5 && parts[0] == "namespaces":
+		namespace = parts[1]
+		parts = parts[2:]
+	default:
+		return schema.GroupResource{}, "", "", false
+	}
+	if !strings.HasSuffix(parts[2], ".yaml") {
+		return schema.GroupResource{}, "", "", false
+	}
+	groupResource := schema.GroupResource{Group: parts[0], Resource: parts[1]}
+	if groupResource.Group == "core" {
+		groupResource.Group = ""
+	}
+	return groupResource, namespace, strings.TrimSuffix(parts[2], ".yaml"), true
+}
+
+var (
+	clusterOperatorsResource = schema.GroupResource{Group: "config.openshift.io", Resource: "clusteroperators"}
+	nodesResource            = schema.GroupResource{Resource: "nodes"}
+)
+
+// Intervals returns an instant for the creation, update or deletion of the resource, for the bump of its generation
+// and for the transitions of the conditions of ClusterOperators and Nodes, so the change can be overlaid on the
+// timeline of a job run.
+func (c *ResourceChange) Intervals() monitorapi.Intervals {
+	locator := c.locator()
+	managers := strings.Join(strings.Split(c.Author, " AND "), ",")
+	newInterval := func(level monitorapi.IntervalLevel, message *monitorapi.MessageBuilder) *monitorapi.IntervalBuilder {
+		return monitorapi.NewInterval(monitorapi.SourceResourceWatch, level).Locator(locator).Message(message)
+	}
+
+	ret := monitorapi.Intervals{}
+	switch {
+	case c.Previous == nil:
+		ret = append(ret, newInterval(monitorapi.Info, monitorapi.NewMessage().
+			Reason(monitorapi.ResourceCreatedReason).
+			WithAnnotation(monitorapi.AnnotationManagers, managers).
+			HumanMessage("created")).
+			Build(c.Time, c.Time))
+	case c.Object == nil:
+		ret = append(ret, newInterval(monitorapi.Warning, monitorapi.NewMessage().
+			Reason(monitorapi.ResourceDeletedReason).
+			WithAnnotation(monitorapi.AnnotationManagers, managers).
+			HumanMessage("deleted")).
+			Display().
+			Build(c.Time, c.Time))
+		return ret
+	default:
+		ret = append(ret, newInterval(monitorapi.Info, monitorapi.NewMessage().
+			Reason(monitorapi.ResourceUpdatedReason).
+			WithAnnotation(monitorapi.AnnotationManagers, managers).
+			HumanMessage("updated")).
+			Build(c.Time, c.Time))
+		if previous, current := c.Previous.GetGeneration(), c.Object.GetGeneration(); previous != current {
+			ret = append(ret, newInterval(monitorapi.Info, monitorapi.NewMessage().
+				Reason(monitorapi.GenerationChangedReason).
+				WithAnnotation(monitorapi.AnnotationManagers, managers).
+				HumanMessagef("generation changed from %d to %d", previous, current)).
+				Display().
+				Build(c.Time, c.Time))
+		}
+	}
+
+	if c.GroupResource != clusterOperatorsResource && c.GroupResource != nodesResource {
+		return ret
+	}
+	previousConditions := map[string]map[string]interface{}{}
+	if c.Previous != nil {
+		for _, condition := range conditions(c.Previous) {
+			conditionType, _, _ := unstructured.NestedString(condition, "type")
+			previousConditions[conditionType] = condition
+		}
+	}
+	for _, condition := range conditions(c.Object) {
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		// like the monitor, the first state of every condition is reported.
+		if previousCondition, ok := previousConditions[conditionType]; ok {
+			if previousStatus, _, _ := unstructured.NestedString(previousCondition, "status"); previousStatus == status {
+				continue
+			}
+		}
+
+		conditionMessage := monitorapi.NewMessage().
+			WithAnnotation(monitorapi.AnnotationCondition, conditionType).
+			WithAnnotation(monitorapi.AnnotationStatus, status).
+			HumanMessage(message)
+		if len(reason) > 0 {
+			conditionMessage = conditionMessage.Reason(monitorapi.IntervalReason(reason))
+		}
+		ret = append(ret, newInterval(c.conditionLevel(conditionType, status), conditionMessage).
+			Display().
+			Build(c.Time, c.Time))
+	}
+	return ret
+}
+
+func (c *ResourceChange) locator() monitorapi.Locator {
+	switch c.GroupResource {
+	case clusterOperatorsResource:
+		return monitorapi.NewLocator().ClusterOperator(c.Name)
+	case nodesResource:
+		return monitorapi.NewLocator().NodeFromName(c.Name)
+	}
+	kind := c.GroupResource.Resource
+	for _, obj := range []*unstructured.Unstructured{c.Object, c.Previous} {
+		if obj != nil && len(obj.GetKind()) > 0 {
+			kind = obj.GetKind()
+			break
+		}
+	}
+	return monitorapi.NewLocator().ObjectFromNames(kind, c.Namespace, c.Name)
+}
+
+// conditionLevel matches the levels of the ClusterOperator monitor, and makes Nodes that are not ready or under
+// pressure warnings.
+func (c *ResourceChange) conditionLevel(conditionType, status string) monitorapi.IntervalLevel {
+	if c.GroupResource == clusterOperatorsResource {
+		switch {
+		case conditionType == "Degraded" && status == "True",
+			conditionType == "Available" && status == "False",
+			conditionType == "Failing" && status == "True":
+			return monitorapi.Error
+		}
+		return monitorapi.Warning
+	}
+	switch {
+	case conditionType == "Ready" && status != "True":
+		return monitorapi.Warning
+	case conditionType != "Ready" && status == "True":
+		return monitorapi.Warning
+	}
+	return monitorapi.Info
+}
+
+func conditions(obj *unstructured.Unstructured) []map[string]interface{} {
+	ret := []map[string]interface{}{}
+	if obj == nil {
+		return ret
+	}
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range items {
+		if condition, ok := item.(map[string]interface{}); ok {
+			ret = append(ret, condition)
+		}
+	}
+	return ret
+}
+
+// RecordHistory records the changes in a monitor: their intervals, and the resources as the monitor tracks them, so
+// the recorded state carries the update and recreation counts of resources observed during a job run.  Deletions are
+// not tracked by the monitor, the last state of a deleted resource is kept.
+func RecordHistory(changes []ResourceChange, recorder monitorapi.RecorderWriter) {
+	for i := range changes {
+		change := &changes[i]
+		recorder.AddIntervals(change.Intervals()...)
+		if change.Object != nil {
+			recorder.RecordResource(change.GroupResource.String(), change.Object)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func clusterOperatorState(generation int64, available string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": available, "reason": "AsExpected", "message": "etcd is " + available},
+				map[string]interface{}{"type": "Degraded", "status": "False"},
+			},
+		},
+	}}
+	obj.SetAPIVersion("config.openshift.io/v1")
+	obj.SetKind("ClusterOperator")
+	obj.SetName("etcd")
+	obj.SetUID("uid")
+	obj.SetGeneration(generation)
+	return obj
+}
+
+func TestRecordHistory(t *testing.T) {
+	clusterOperators := schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusteroperators"}
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	repositoryPath := t.TempDir()
+	gitStorage, err := NewGitStorage(repositoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitStorage.Record(clusterOperators, nil, clusterOperatorState(1, "True"), false, "cluster-version-operator")
+	gitStorage.Record(clusterOperators, clusterOperatorState(1, "True"), clusterOperatorState(2, "False"), false, "etcd-operator")
+	console := operatorState("console-operator", 2, "True")
+	gitStorage.Record(deployments, nil, console, false, "")
+	gitStorage.Record(deployments, nil, console, true, "")
+
+	changes, err := ReadRepositoryHistory(repositoryPath, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 4 {
+		t.Fatalf("expected every commit to be a change, got %d", len(changes))
+	}
+	if changes[1].Previous.GetGeneration() != 1 || changes[1].Object.GetGeneration() != 2 {
+		t.Errorf("expected the update of the ClusterOperator to be second, got %v", changes[1].Filename)
+	}
+
+	actual := []string{}
+	for _, change := range changes {
+		for _, interval := range change.Intervals() {
+			actual = append(actual, interval.Level.String()+" "+interval.Locator.OldLocator()+" "+interval.Message.OldMessage())
+		}
+	}
+	expected := []string{
+		"Info clusteroperator/etcd managers/cluster-version-operator reason/ResourceCreated created",
+		"Warning clusteroperator/etcd condition/Available reason/AsExpected status/True etcd is True",
+		"Warning clusteroperator/etcd condition/Degraded status/False",
+		"Info clusteroperator/etcd managers/etcd-operator reason/ResourceUpdated updated",
+		"Info clusteroperator/etcd managers/etcd-operator reason/GenerationChanged generation changed from 1 to 2",
+		"Error clusteroperator/etcd condition/Available reason/AsExpected status/False etcd is False",
+		"Info namespace/openshift-console deployment/console managers/console-operator reason/ResourceCreated created",
+		"Warning namespace/openshift-console deployment/console managers/unknown reason/ResourceDeleted deleted",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected intervals\n%v\ngot\n%v", expected, actual)
+	}
+
+	recorder := monitor.NewRecorder()
+	RecordHistory(changes, recorder)
+	if intervals := recorder.Intervals(time.Time{}, time.Time{}); len(intervals) != len(expected) {
+		t.Errorf("expected %d recorded intervals, got %d", len(expected), len(intervals))
+	}
+
+	recorded := recorder.CurrentResourceState()["clusteroperators.config.openshift.io"][monitorapi.InstanceKey{Name: "etcd", UID: "uid"}]
+	if annotations := recorded.(*unstructured.Unstructured).GetAnnotations(); annotations[monitorapi.ObservedUpdateCountAnnotation] != "2" {
+		t.Errorf("expected the ClusterOperator to be tracked with two updates, got %v", annotations)
+	}
+	if _, ok := recorder.CurrentResourceState()["deployments.apps"]; !ok {
+		t.Errorf("expected the last state of deleted resources to be tracked")
+	}
+}
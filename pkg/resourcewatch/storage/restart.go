@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// RestartAuthor is the author of the commits made when a restarted resourcewatch catches up with the changes it did not
+// observe while it was down.
+const RestartAuthor = "resourcewatch-restart"
+
+// resourceVersionsFilename is inside .git so the state is never committed with the resources.
+const resourceVersionsFilename = "resourcewatch-resource-versions.json"
+
+// resourceVersions are the last resourceVersions observed per resource, so that a restarted resourcewatch can resume its
+// watches from where it stopped.
+type resourceVersions struct {
+	path     string
+	versions map[string]string
+	dirty    bool
+	lock     sync.Mutex
+}
+
+func loadResourceVersions(repositoryPath string) (*resourceVersions, error) {
+	ret := &resourceVersions{
+		path:     filepath.Join(repositoryPath, ".git", resourceVersionsFilename),
+		versions: map[string]string{},
+	}
+	content, err := os.ReadFile(ret.path)
+	switch {
+	case os.IsNotExist(err):
+		return ret, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &ret.versions); err != nil {
+		// the state is only an optimization, the reconcile on startup catches up without it.
+		klog.Warningf("Ignoring unreadable %s: %v", ret.path, err)
+		ret.versions = map[string]string{}
+	}
+	return ret, nil
+}
+
+func (r *resourceVersions) get(gvr schema.GroupVersionResource) string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.versions[gvr.String()]
+}
+
+func (r *resourceVersions) observe(gvr schema.GroupVersionResource, resourceVersion string) {
+	if len(resourceVersion) == 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.versions[gvr.String()] == resourceVersion {
+		return
+	}
+	r.versions[gvr.String()] = resourceVersion
+	r.dirty = true
+}
+
+// save writes the resourceVersions when they changed.  They are written to a temporary file first, so a crash while
+// writing cannot leave a truncated file behind.
+func (r *resourceVersions) save() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.dirty {
+		return nil
+	}
+	content, err := json.MarshalIndent(r.versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path+".tmp", content, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(r.path+".tmp", r.path); err != nil {
+		return err
+	}
+	r.dirty = false
+	return nil
+}
+
+// ResourceVersion is the last resourceVersion of the resource observed by this or a previous run, empty when it was
+// never observed.
+func (s *GitStorage) ResourceVersion(gvr schema.GroupVersionResource) string {
+	return s.resourceVersions.get(gvr)
+}
+
+// ObserveResourceVersion records that every change of the resource up to resourceVersion was observed.
+func (s *GitStorage) ObserveResourceVersion(gvr schema.GroupVersionResource, resourceVersion string) {
+	s.resourceVersions.observe(gvr, resourceVersion)
+}
+
+// SaveResourceVersions persists the observed resourceVersions in the repository.  The pending changes are committed
+// first, so a restart never resumes after changes that were not committed.
+func (s *GitStorage) SaveResourceVersions() error {
+	s.Flush()
+	return s.resourceVersions.save()
+}
+
+// Record synchronously commits a change of a resource.  Changes that were not observed by the informers, like those
+// made while resourcewatch was down, are committed by author instead of the managers of the fields.
+func (s *GitStorage) Record(gvr schema.GroupVersionResource, oldObj, obj *unstructured.Unstructured, deleted bool, author string) {
+	s.handle(gvr, oldObj, obj, deleted, author)
+	s.Flush()
+}
+
+// RecordedObjects returns the last recorded state of every object of the resource in the repository, by their
+// filename.
+func (s *GitStorage) RecordedObjects(gvr schema.GroupVersionResource) (map[string]*unstructured.Unstructured, error) {
+	// any namespace and name are replaced to find where the objects of the resource are stored.
+	clusterScopedDir := filepath.Dir(resourceFilename(gvr, "", "name"))
+	namespacedPattern := filepath.Join(s.path, filepath.Dir(resourceFilename(gvr, "*", "name")), "*.yaml")
+
+	filenames, err := filepath.Glob(filepath.Join(s.path, clusterScopedDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	namespacedFilenames, err := filepath.Glob(namespacedPattern)
+	if err != nil {
+		return nil, err
+	}
+	filenames = append(filenames, namespacedFilenames...)
+
+	ret := map[string]*unstructured.Unstructured{}
+	for _, filename := range filenames {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		obj, err := decodeRecordedObject(content)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %w", filename, err)
+		}
+		ret[strings.TrimPrefix(filename, s.path+string(filepath.Separator))] = obj
+	}
+	return ret, nil
+}
+
+// Reconcile commits the difference between the recorded and the current objects of a resource: objects that were
+// added, updated or deleted while resourcewatch was not watching.  Objects whose resourceVersion did not change were
+// already committed.
+func (s *GitStorage) Reconcile(gvr schema.GroupVersionResource, recorded map[string]*unstructured.Unstructured, current []*unstructured.Unstructured) {
+	currentFilenames := map[string]bool{}
+	for _, obj := range current {
+		filename := resourceFilename(gvr, obj.GetNamespace(), obj.GetName())
+		currentFilenames[filename] = true
+		recordedObj, ok := recorded[filename]
+		switch {
+		case !ok:
+			s.handle(gvr, nil, obj, false, RestartAuthor)
+		case recordedObj.GetResourceVersion() != obj.GetResourceVersion():
+			s.handle(gvr, recordedObj, obj, false, RestartAuthor)
+		}
+	}
+	for filename, recordedObj := range recorded {
+		if !currentFilenames[filename] {
+			// the resourceVersion of the deletion is unknown, the recorded one must not be observed.
+			deletedObj := recordedObj.DeepCopy()
+			deletedObj.SetResourceVersion("")
+			s.handle(gvr, nil, deletedObj, true, RestartAuthor)
+		}
+	}
+	s.Flush()
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestResourceVersionsSurviveRestart(t *testing.T) {
+	repositoryPath := t.TempDir()
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	gitStorage, err := NewGitStorage(repositoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitStorage.ObserveResourceVersion(gvr, "42")
+	if err := gitStorage.SaveResourceVersions(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := NewGitStorage(repositoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := restarted.ResourceVersion(gvr); actual != "42" {
+		t.Errorf("expected the resourceVersion to be restored, got %q", actual)
+	}
+	if actual := restarted.ResourceVersion(schema.GroupVersionResource{Version: "v1", Resource: "pods"}); len(actual) > 0 {
+		t.Errorf("expected pods to never have been observed, got %q", actual)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	deployment := func(name, resourceVersion string) *unstructured.Unstructured {
+		obj := operatorState("console-operator", 2, "True")
+		obj.SetName(name)
+		obj.SetResourceVersion(resourceVersion)
+		return obj
+	}
+
+	repositoryPath := t.TempDir()
+	gitStorage, err := NewGitStorage(repositoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, obj := range []*unstructured.Unstructured{deployment("unchanged", "1"), deployment("updated", "2"), deployment("deleted", "3")} {
+		gitStorage.Record(gvr, nil, obj, false, "")
+	}
+	recorded, err := gitStorage.RecordedObjects(gvr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 3 {
+		t.Fatalf("expected 3 recorded deployments, got %v", recorded)
+	}
+
+	gitStorage.Reconcile(gvr, recorded, []*unstructured.Unstructured{deployment("unchanged", "1"), deployment("updated", "5"), deployment("added", "6")})
+
+	actual := []string{}
+	for _, line := range commitLog(t, repositoryPath) {
+		if strings.HasPrefix(line, RestartAuthor) {
+			actual = append(actual, line)
+		}
+	}
+	sort.Strings(actual)
+	expected := []string{
+		"resourcewatch-restart added deployments.apps/added -n openshift-console",
+		"resourcewatch-restart modifed deployments.apps/updated -n openshift-console",
+		"resourcewatch-restart removed deployments.apps/deleted -n openshift-console",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected restart commits\n%v\ngot\n%v", expected, actual)
+	}
+	if actual := gitStorage.ResourceVersion(gvr); actual != "6" {
+		t.Errorf("expected the last recorded resourceVersion to be observed, got %q", actual)
+	}
+}
@@ -29,13 +29,16 @@ const (
 type Options struct {
 	JUnitDir string
 	SippyURL string
+	// PassRateHistory is a file with the pass rates of tests, see ReadTestPassRates.  When set, the failures are
+	// analyzed locally instead of by sippy.
+	PassRateHistory string
 }
 
 const testFailureSummaryFilePrefix = "test-failures-summary"
 const sippyURL = "https://sippy.dptools.openshift.org/sippy-ng/"
 
-// Run performs the test risk analysis by reading the output files from the test run, submitting them to sippy,
-// and writing out the analysis result as a new artifact.
+// Run performs the test risk analysis by reading the output files from the test run, submitting them to sippy, or
+// analyzing them locally against PassRateHistory, and writing out the analysis result as a new artifact.
 func (opt *Options) Run() error {
 	logrus.Infof("Scanning for %s files in: %s", testFailureSummaryFilePrefix, opt.JUnitDir)
 
@@ -86,50 +89,14 @@ func (opt *Options) Run() error {
 		finalProwJobRun.TestCount += pjr.TestCount
 	}
 
-	inputBytes, err := json.Marshal(finalProwJobRun)
-	if err != nil {
-		logrus.WithError(err).Error("Error marshalling results")
-		return nil
-	}
-
-	req, err := http.NewRequest("GET", opt.SippyURL, bytes.NewBuffer(inputBytes))
-	if err != nil {
-		logrus.WithError(err).Error("Error creating GET request during risk analysis")
-		return nil
-	}
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
-
-	var resp *http.Response
-	clientDoSuccess := false
-	for i := 1; i <= maxRetries; i++ {
-		ctx, cancelFn := context.WithTimeout(req.Context(), 20*time.Second)
-		defer cancelFn()
-		startTime := time.Now()
-		logrus.Infof("Requesting risk analysis (attempt %d/%d) from: %s", i, maxRetries, sippyURL)
-		resp, err = client.Do(req.WithContext(ctx))
-		endTime := time.Now()
-		duration := endTime.Sub(startTime)
-		logrus.Infof("Call to sippy finished after: %s", duration)
-		if err == nil {
-			clientDoSuccess = true
-			break
-		}
-		logrus.WithError(err).Warn("error requesting risk analysis from sippy, sleeping 30s")
-
-		// cancel the context we just used.
-		cancelFn()
-		time.Sleep(time.Duration(i*30) * time.Second)
+	var riskAnalysisBytes []byte
+	if len(opt.PassRateHistory) > 0 {
+		riskAnalysisBytes, err = analyzeLocally(opt.PassRateHistory, finalProwJobRun)
+	} else {
+		riskAnalysisBytes, err = requestRiskAnalysis(opt.SippyURL, finalProwJobRun)
 	}
-	if !clientDoSuccess {
-		logrus.WithError(err).Error("Unable to obtain risk analysis from sippy after retries")
-		return nil
-	}
-	defer resp.Body.Close()
-
-	riskAnalysisBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		logrus.WithError(err).Error("Error reading risk analysis request body from sippy")
+		logrus.WithError(err).Error("Unable to obtain risk analysis")
 		return nil
 	}
 	logrus.Info("response Body:", string(riskAnalysisBytes))
@@ -168,6 +135,61 @@ func (opt *Options) Run() error {
 	return nil
 }
 
+// analyzeLocally scores the failures of the job run against the pass rate history, see LocalRiskAnalyzer.
+func analyzeLocally(passRateHistory string, jobRun *ProwJobRun) ([]byte, error) {
+	passRates, err := ReadTestPassRates(passRateHistory)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("Analyzing risk locally against %d pass rates from: %s", len(passRates), passRateHistory)
+	return json.Marshal(NewLocalRiskAnalyzer(passRates).Analyze(jobRun))
+}
+
+// requestRiskAnalysis submits the job run to sippy, or a stand-in serving the same API, and returns its analysis.
+func requestRiskAnalysis(url string, jobRun *ProwJobRun) ([]byte, error) {
+	inputBytes, err := json.Marshal(jobRun)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling results: %w", err)
+	}
+
+	req, err := http.NewRequest("GET", url, bytes.NewBuffer(inputBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating GET request during risk analysis: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{}
+
+	var resp *http.Response
+	for i := 1; i <= maxRetries; i++ {
+		ctx, cancelFn := context.WithTimeout(req.Context(), 20*time.Second)
+		defer cancelFn()
+		startTime := time.Now()
+		logrus.Infof("Requesting risk analysis (attempt %d/%d) from: %s", i, maxRetries, url)
+		resp, err = client.Do(req.WithContext(ctx))
+		endTime := time.Now()
+		duration := endTime.Sub(startTime)
+		logrus.Infof("Call to sippy finished after: %s", duration)
+		if err == nil {
+			break
+		}
+		logrus.WithError(err).Warn("error requesting risk analysis from sippy, sleeping 30s")
+
+		// cancel the context we just used.
+		cancelFn()
+		time.Sleep(time.Duration(i*30) * time.Second)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to obtain risk analysis from sippy after retries: %w", err)
+	}
+	defer resp.Body.Close()
+
+	riskAnalysisBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading risk analysis request body from sippy: %w", err)
+	}
+	return riskAnalysisBytes, nil
+}
+
 type disruptionBackendAnalysis struct {
 	BackendName        string
 	ObservedDisruption int
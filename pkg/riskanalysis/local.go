@@ -0,0 +1,249 @@
+package riskanalysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// highRiskPassRate is the pass rate above which a failure is unusual, as in sippy.
+	highRiskPassRate = 0.98
+	// mediumRiskPassRate is the pass rate above which a failure is somewhat unusual, as in sippy.
+	mediumRiskPassRate = 0.80
+	// minRuns is the number of runs below which a pass rate is too noisy to score a failure.
+	minRuns = 10
+	// highRiskFailedTests is the number of failed tests above which a job run is high risk regardless of the tests.
+	highRiskFailedTests = 20
+)
+
+// TestPassRate is the pass rate of a test in the job runs of a JobType.  Empty JobType fields match every job run, so
+// that history without some variants can be used.
+type TestPassRate struct {
+	TestName                       string
+	platformidentification.JobType `json:",inline"`
+	Runs                           int
+	Passes                         int
+}
+
+// specificity is the number of JobType fields the pass rate is for.
+func (p TestPassRate) specificity() int {
+	count := 0
+	for _, field := range []string{p.Release, p.FromRelease, p.Platform, p.Architecture, p.Network, p.Topology} {
+		if len(field) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (p TestPassRate) matches(jobType platformidentification.JobType) bool {
+	fieldMatches := func(historical, actual string) bool {
+		return len(historical) == 0 || historical == actual
+	}
+	return fieldMatches(p.Release, jobType.Release) &&
+		fieldMatches(p.FromRelease, jobType.FromRelease) &&
+		fieldMatches(p.Platform, jobType.Platform) &&
+		fieldMatches(p.Architecture, jobType.Architecture) &&
+		fieldMatches(p.Network, jobType.Network) &&
+		fieldMatches(p.Topology, jobType.Topology)
+}
+
+// ReadTestPassRates reads a pass rate history from a .json file, a list of TestPassRate, or a .csv file with a header
+// naming the TestPassRate fields, for instance:
+//
+//	TestName,Release,Platform,Runs,Passes
+//	"[sig-network] Services should serve endpoints",4.15,openstack,300,297
+func ReadTestPassRates(filename string) ([]TestPassRate, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(filepath.Ext(filename), ".csv") {
+		passRates := []TestPassRate{}
+		if err := json.Unmarshal(data, &passRates); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", filename, err)
+		}
+		return passRates, nil
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", filename, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s has no header", filename)
+	}
+	passRates := []TestPassRate{}
+	header := records[0]
+	for i, record := range records[1:] {
+		passRate := TestPassRate{}
+		for column, value := range record {
+			var err error
+			switch strings.ToLower(header[column]) {
+			case "testname":
+				passRate.TestName = value
+			case "release":
+				passRate.Release = value
+			case "fromrelease":
+				passRate.FromRelease = value
+			case "platform":
+				passRate.Platform = value
+			case "architecture":
+				passRate.Architecture = value
+			case "network":
+				passRate.Network = value
+			case "topology":
+				passRate.Topology = value
+			case "runs":
+				passRate.Runs, err = strconv.Atoi(value)
+			case "passes":
+				passRate.Passes, err = strconv.Atoi(value)
+			default:
+				err = fmt.Errorf("unknown column %q", header[column])
+			}
+			if err != nil {
+				// the header is the first line of the file.
+				return nil, fmt.Errorf("%s line %d: %w", filename, i+2, err)
+			}
+		}
+		passRates = append(passRates, passRate)
+	}
+	return passRates, nil
+}
+
+// LocalRiskAnalyzer scores test failures against a pass rate history the way sippy does, for environments without
+// access to sippy.  It serves the sippy risk analysis API, so it can also stand in for sippy.
+type LocalRiskAnalyzer struct {
+	passRatesByTest map[string][]TestPassRate
+}
+
+func NewLocalRiskAnalyzer(passRates []TestPassRate) *LocalRiskAnalyzer {
+	analyzer := &LocalRiskAnalyzer{passRatesByTest: map[string][]TestPassRate{}}
+	for _, passRate := range passRates {
+		analyzer.passRatesByTest[passRate.TestName] = append(analyzer.passRatesByTest[passRate.TestName], passRate)
+	}
+	return analyzer
+}
+
+// passRate adds up the most specific pass rates of the test that match the job type.
+func (a *LocalRiskAnalyzer) passRate(testName string, jobType platformidentification.JobType) (runs, passes int) {
+	mostSpecific := -1
+	for _, passRate := range a.passRatesByTest[testName] {
+		if !passRate.matches(jobType) {
+			continue
+		}
+		switch specificity := passRate.specificity(); {
+		case specificity > mostSpecific:
+			mostSpecific = specificity
+			runs, passes = passRate.Runs, passRate.Passes
+		case specificity == mostSpecific:
+			runs += passRate.Runs
+			passes += passRate.Passes
+		}
+	}
+	return runs, passes
+}
+
+func (a *LocalRiskAnalyzer) testRisk(testName string, jobType platformidentification.JobType) FailureRisk {
+	runs, passes := a.passRate(testName, jobType)
+	if runs < minRuns {
+		return FailureRisk{
+			Level:   FailureRiskLevelUnknown,
+			Reasons: []string{fmt.Sprintf("Only %d runs of this test in the pass rate history, %d are required.", runs, minRuns)},
+		}
+	}
+
+	passRate := float64(passes) / float64(runs)
+	reason := fmt.Sprintf("This test has passed %.2f%% of %d runs on jobs like this one in the pass rate history.", passRate*100, runs)
+	switch {
+	case passRate >= highRiskPassRate:
+		return FailureRisk{Level: FailureRiskLevelHigh, Reasons: []string{reason}}
+	case passRate >= mediumRiskPassRate:
+		return FailureRisk{Level: FailureRiskLevelMedium, Reasons: []string{reason}}
+	default:
+		return FailureRisk{Level: FailureRiskLevelLow, Reasons: []string{reason}}
+	}
+}
+
+// Analyze scores every failed test of the job run by its pass rate, the overall risk is the highest risk of a test.
+func (a *LocalRiskAnalyzer) Analyze(jobRun *ProwJobRun) *ProwJobRunRiskAnalysis {
+	jobType := jobRun.ClusterData.JobType
+	analysis := &ProwJobRunRiskAnalysis{
+		ProwJobName:    jobRun.ProwJob.Name,
+		ProwJobRunID:   jobRun.ID,
+		Release:        jobType.Release,
+		CompareRelease: jobType.Release,
+		Tests:          []ProwJobRunTestRiskAnalysis{},
+		OverallRisk: FailureRisk{
+			Level:   FailureRiskLevelNone,
+			Reasons: []string{},
+		},
+		OpenBugs: []Bug{},
+	}
+
+	failedTests := 0
+	for _, test := range jobRun.Tests {
+		if test.Status != sippyStatusFailure {
+			continue
+		}
+		failedTests++
+		risk := a.testRisk(test.Test.Name, jobType)
+		analysis.Tests = append(analysis.Tests, ProwJobRunTestRiskAnalysis{
+			Name:     test.Test.Name,
+			Risk:     risk,
+			OpenBugs: []Bug{},
+		})
+		if risk.Level.Level > analysis.OverallRisk.Level.Level {
+			analysis.OverallRisk.Level = risk.Level
+		}
+	}
+	sort.SliceStable(analysis.Tests, func(i, j int) bool {
+		return analysis.Tests[i].Risk.Level.Level > analysis.Tests[j].Risk.Level.Level
+	})
+
+	if failedTests > highRiskFailedTests {
+		analysis.OverallRisk.Level = FailureRiskLevelHigh
+		analysis.OverallRisk.Reasons = append(analysis.OverallRisk.Reasons,
+			fmt.Sprintf("%d tests failed in this job run, more than %d is unusual.", failedTests, highRiskFailedTests))
+	}
+	for _, test := range analysis.Tests {
+		if test.Risk.Level == analysis.OverallRisk.Level {
+			analysis.OverallRisk.Reasons = append(analysis.OverallRisk.Reasons, fmt.Sprintf("%s: %s", test.Name, strings.Join(test.Risk.Reasons, " ")))
+		}
+	}
+	return analysis
+}
+
+// ServeHTTP answers requests with a ProwJobRun body like sippy does.
+func (a *LocalRiskAnalyzer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	jobRun := &ProwJobRun{}
+	if err := json.Unmarshal(body, jobRun); err != nil {
+		http.Error(w, fmt.Sprintf("unable to parse the ProwJobRun: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	analysis := a.Analyze(jobRun)
+	logrus.Infof("analyzed %d failed tests of %s/%d, overall risk %s",
+		len(analysis.Tests), analysis.ProwJobName, analysis.ProwJobRunID, analysis.OverallRisk.Level.Name)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(analysis); err != nil {
+		logrus.WithError(err).Error("error writing the risk analysis")
+	}
+}
@@ -0,0 +1,73 @@
+package riskanalysis
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const passRatesCSV = `TestName,Release,Platform,Network,Runs,Passes
+reliable,4.15,openstack,ovn,200,199
+reliable,4.15,openstack,sdn,100,50
+flaky,4.15,openstack,,100,90
+broken,4.15,,,100,10
+rare,4.15,openstack,ovn,5,5
+`
+
+func TestLocalRiskAnalyzer(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "pass-rates.csv")
+	require.NoError(t, os.WriteFile(filename, []byte(passRatesCSV), 0644))
+	passRates, err := ReadTestPassRates(filename)
+	require.NoError(t, err)
+	require.Len(t, passRates, 5)
+
+	jobRun := &ProwJobRun{
+		ID:      1234,
+		ProwJob: ProwJob{Name: "periodic-openstack"},
+		ClusterData: platformidentification.ClusterData{
+			JobType: platformidentification.JobType{Release: "4.15", Platform: "openstack", Architecture: "amd64", Network: "ovn", Topology: "ha"},
+		},
+	}
+	for _, name := range []string{"broken", "flaky", "reliable", "rare", "unknown"} {
+		jobRun.Tests = append(jobRun.Tests, ProwJobRunTest{Test: Test{Name: name}, Status: sippyStatusFailure})
+	}
+	analysis := NewLocalRiskAnalyzer(passRates).Analyze(jobRun)
+
+	levels := map[string]RiskLevel{}
+	for _, test := range analysis.Tests {
+		levels[test.Name] = test.Risk.Level
+	}
+	assert.Equal(t, map[string]RiskLevel{
+		"reliable": FailureRiskLevelHigh,
+		"flaky":    FailureRiskLevelMedium,
+		"broken":   FailureRiskLevelLow,
+		"rare":     FailureRiskLevelUnknown,
+		"unknown":  FailureRiskLevelUnknown,
+	}, levels)
+	assert.Equal(t, "reliable", analysis.Tests[0].Name)
+	assert.Equal(t, FailureRiskLevelHigh, analysis.OverallRisk.Level)
+	assert.Equal(t, []string{"reliable: This test has passed 99.50% of 200 runs on jobs like this one in the pass rate history."}, analysis.OverallRisk.Reasons)
+
+	// the same analysis is served to risk-analysis pointed at a stand-in for sippy.
+	server := httptest.NewServer(NewLocalRiskAnalyzer(passRates))
+	defer server.Close()
+	served, err := requestRiskAnalysis(server.URL, jobRun)
+	require.NoError(t, err)
+	servedAnalysis := &ProwJobRunRiskAnalysis{}
+	require.NoError(t, json.Unmarshal(served, servedAnalysis))
+	assert.Equal(t, analysis, servedAnalysis)
+}
+
+func TestReadTestPassRatesUnknownColumn(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "pass-rates.csv")
+	require.NoError(t, os.WriteFile(filename, []byte("TestName,PassRate\nfoo,0.5\n"), 0644))
+	_, err := ReadTestPassRates(filename)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 2: unknown column "PassRate"`)
+}
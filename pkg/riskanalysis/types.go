@@ -34,3 +34,46 @@ type ProwJobRunTest struct {
 	Suite  Suite
 	Status int // would like to use smallint here, but gorm auto-migrate breaks trying to change the type every start
 }
+
+// ProwJobRunRiskAnalysis is the risk analysis sippy returns for a ProwJobRun, written to risk-analysis.json and
+// rendered by e2echart/test-risk-analysis.html.
+type ProwJobRunRiskAnalysis struct {
+	ProwJobName    string
+	ProwJobRunID   int
+	Release        string
+	CompareRelease string
+	Tests          []ProwJobRunTestRiskAnalysis
+	OverallRisk    FailureRisk
+	OpenBugs       []Bug
+}
+
+type ProwJobRunTestRiskAnalysis struct {
+	Name     string
+	Risk     FailureRisk
+	OpenBugs []Bug
+}
+
+type FailureRisk struct {
+	Level   RiskLevel
+	Reasons []string
+}
+
+type RiskLevel struct {
+	Name  string
+	Level int
+}
+
+type Bug struct {
+	Key     string `json:"key"`
+	Summary string `json:"summary"`
+	URL     string `json:"url"`
+}
+
+// The risk levels sippy uses, the report colors 5 and above yellow and 10 and above red.
+var (
+	FailureRiskLevelNone    = RiskLevel{Name: "None", Level: 0}
+	FailureRiskLevelLow     = RiskLevel{Name: "Low", Level: 1}
+	FailureRiskLevelUnknown = RiskLevel{Name: "Unknown", Level: 25}
+	FailureRiskLevelMedium  = RiskLevel{Name: "Medium", Level: 50}
+	FailureRiskLevelHigh    = RiskLevel{Name: "High", Level: 100}
+)
@@ -83,13 +83,19 @@ type passFail struct {
 	Failed bool
 }
 
+// The codes sippy uses internally for each type of failure.
+const (
+	sippyStatusFailure = 12
+	sippyStatusFlake   = 13
+)
+
 // getSippyStatusCode returns the code sippy uses internally for each type of failure.
 func getSippyStatusCode(pf *passFail) int {
 	switch {
 	case pf.Failed && pf.Passed:
-		return 13 // flake
+		return sippyStatusFlake
 	case pf.Failed && !pf.Passed:
-		return 12 // fail
+		return sippyStatusFailure
 	}
 	// we should not hit this given the above filtering
 	return 0
@@ -71,6 +71,7 @@ type GinkgoRunSuiteOptions struct {
 
 	ExactMonitorTests   []string
 	DisableMonitorTests []string
+	EnableMonitorTests  []string
 }
 
 func NewGinkgoRunSuiteOptions(streams genericclioptions.IOStreams) *GinkgoRunSuiteOptions {
@@ -95,6 +96,8 @@ func (o *GinkgoRunSuiteOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.StringSliceVar(&o.ExactMonitorTests, "monitor", o.ExactMonitorTests,
 		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
 	flags.StringSliceVar(&o.DisableMonitorTests, "disable-monitor", o.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
+	flags.StringSliceVar(&o.EnableMonitorTests, "enable-monitor", o.EnableMonitorTests,
+		fmt.Sprintf("list of opt-in monitors to enable in addition to the defaults.  Opt-in monitors are: [%s]", strings.Join(defaultmonitortests.ListOptInMonitorTests(), ", ")))
 }
 
 func (o *GinkgoRunSuiteOptions) Validate() error {
@@ -40,6 +40,7 @@ type TestOptions struct {
 
 	ExactMonitorTests   []string
 	DisableMonitorTests []string
+	EnableMonitorTests  []string
 }
 
 var _ ginkgo.GinkgoTestingT = &TestOptions{}
@@ -89,6 +90,7 @@ func (o *TestOptions) Run(args []string) error {
 		ClusterStabilityDuringTest: monitortestframework.Stable,
 		ExactMonitorTests:          o.ExactMonitorTests,
 		DisableMonitorTests:        o.DisableMonitorTests,
+		EnableMonitorTests:         o.EnableMonitorTests,
 	}
 	var m monitor.Interface
 	if o.EnableMonitor {
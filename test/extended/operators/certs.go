@@ -40,7 +40,6 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
-	"k8s.io/apimachinery/pkg/labels"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/watch"
 	watchtools "k8s.io/client-go/tools/watch"
@@ -102,12 +101,16 @@ var _ = g.Describe(fmt.Sprintf("[sig-arch][Late][Jira:%q]", "kube-apiserver"), g
 		jobType, err = platformidentification.GetJobType(context.TODO(), oc.AdminConfig())
 		o.Expect(err).NotTo(o.HaveOccurred())
 
-		controlPlaneLabel := labels.SelectorFromSet(map[string]string{"node-role.kubernetes.io/control-plane": ""})
-		nodeList, err = kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: controlPlaneLabel.String()})
+		// certificates are collected from the disks of every node, but only control plane nodes are named in secrets.
+		nodeList, err = kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 		o.Expect(err).NotTo(o.HaveOccurred())
+		nodes := []*corev1.Node{}
 		masters := []*corev1.Node{}
 		for i := range nodeList.Items {
-			masters = append(masters, &nodeList.Items[i])
+			nodes = append(nodes, &nodeList.Items[i])
+			if certs.NodeRole(&nodeList.Items[i]) == "master" {
+				masters = append(masters, &nodeList.Items[i])
+			}
 		}
 
 		_, bootstrapHostname, err := certgraphanalysis.GetBootstrapIPAndHostname(ctx, kubeClient)
@@ -119,7 +122,7 @@ var _ = g.Describe(fmt.Sprintf("[sig-arch][Late][Jira:%q]", "kube-apiserver"), g
 		// Skip metal jobs if test image pullspec cannot be determined
 		if jobType.Platform != "metal" || err == nil {
 			o.Expect(err).NotTo(o.HaveOccurred())
-			onDiskPKIContent, err = fetchOnDiskCertificates(ctx, kubeClient, oc.AdminConfig(), masters, openshiftTestImagePullSpec)
+			onDiskPKIContent, err = fetchOnDiskCertificates(ctx, kubeClient, oc.AdminConfig(), nodes, openshiftTestImagePullSpec)
 			o.Expect(err).NotTo(o.HaveOccurred())
 		}
 
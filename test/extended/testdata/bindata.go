@@ -53474,6 +53474,31 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         return durationString;
     }
 
+    const alertDetailAnnotations = ["expr", "expr-value", "runbook_url"];
+
+    function escapeHTML(text) {
+        return text.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;").replace(/"/g, "&quot;");
+    }
+
+    function alertDetailsToolTip(item) {
+        if (item.source !== 'Alert' || !item.message || !item.message.annotations) {
+            return '';
+        }
+        const annotations = item.message.annotations;
+        let tt = '';
+        if (annotations["expr"]) {
+            tt += '<strong>Expression: </strong><code>' + escapeHTML(annotations["expr"]) + '</code><br>';
+        }
+        if (annotations["expr-value"]) {
+            tt += '<strong>Value: </strong>' + escapeHTML(annotations["expr-value"]) + '<br>';
+        }
+        if (annotations["runbook_url"]) {
+            const runbookURL = escapeHTML(annotations["runbook_url"]);
+            tt += '<strong>Runbook: </strong><a href="' + runbookURL + '" target="_blank">' + runbookURL + '</a><br>';
+        }
+        return tt;
+    }
+
     function defaultToolTip(item) {
         if (!item.message || !item.message.annotations) {
             return '';
@@ -53482,7 +53507,8 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         const structuredMessage = item.message;
         const annotations = structuredMessage.annotations;
 
-        const keyValuePairs = Object.entries(annotations).map(([key, value]) => {
+        // alert details are long, they are rendered on their own lines by alertDetailsToolTip.
+        const keyValuePairs = Object.entries(annotations).filter(([key]) => !alertDetailAnnotations.includes(key)).map(([key, value]) => {
             return ` + "`" + `${key}/${value}` + "`" + `;
         });
 
@@ -53558,6 +53584,7 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
     function segmentTooltipFunc(d) {
         return '<span style="max-inline-size: min-content; display: inline-block;">'
         + '<strong>' + d.labelVal + '</strong><br/>'
+        + (d.details || '')
         + '<strong>From: </strong>' + new Date(d.timeRange[0]).toUTCString() + '<br>'
         + '<strong>To: </strong>' + new Date(d.timeRange[1]).toUTCString() + '</span>';
     }
@@ -53604,7 +53631,8 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
             ranges.push({
                 timeRange: [startDate, endDate],
                 val: val,
-                labelVal: defaultToolTip(item)
+                labelVal: defaultToolTip(item),
+                details: alertDetailsToolTip(item)
             });
         });
         for (const label in data) {
@@ -54368,6 +54396,31 @@ var _e2echartNonSpyglassE2eChartTemplateHtml = []byte(`<html lang="en">
         return durationString;
     }
 
+    const alertDetailAnnotations = ["expr", "expr-value", "runbook_url"];
+
+    function escapeHTML(text) {
+        return text.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;").replace(/"/g, "&quot;");
+    }
+
+    function alertDetailsToolTip(item) {
+        if (item.source !== 'Alert' || !item.message || !item.message.annotations) {
+            return '';
+        }
+        const annotations = item.message.annotations;
+        let tt = '';
+        if (annotations["expr"]) {
+            tt += '<strong>Expression: </strong><code>' + escapeHTML(annotations["expr"]) + '</code><br>';
+        }
+        if (annotations["expr-value"]) {
+            tt += '<strong>Value: </strong>' + escapeHTML(annotations["expr-value"]) + '<br>';
+        }
+        if (annotations["runbook_url"]) {
+            const runbookURL = escapeHTML(annotations["runbook_url"]);
+            tt += '<strong>Runbook: </strong><a href="' + runbookURL + '" target="_blank">' + runbookURL + '</a><br>';
+        }
+        return tt;
+    }
+
     function defaultToolTip(item) {
         if (!item.message || !item.message.annotations) {
             return '';
@@ -54376,7 +54429,8 @@ var _e2echartNonSpyglassE2eChartTemplateHtml = []byte(`<html lang="en">
         const structuredMessage = item.message;
         const annotations = structuredMessage.annotations;
 
-        const keyValuePairs = Object.entries(annotations).map(([key, value]) => {
+        // alert details are long, they are rendered on their own lines by alertDetailsToolTip.
+        const keyValuePairs = Object.entries(annotations).filter(([key]) => !alertDetailAnnotations.includes(key)).map(([key, value]) => {
             return ` + "`" + `${key}/${value}` + "`" + `;
         });
 
@@ -54432,6 +54486,7 @@ var _e2echartNonSpyglassE2eChartTemplateHtml = []byte(`<html lang="en">
     function segmentTooltipFunc(d) {
         return '<span style="max-inline-size: min-content; display: inline-block;">'
         + '<strong>' + d.labelVal + '</strong><br/>'
+        + (d.details || '')
         + '<strong>From: </strong>' + new Date(d.timeRange[0]).toUTCString() + '<br>'
         + '<strong>To: </strong>' + new Date(d.timeRange[1]).toUTCString() + '</span>';
     }
@@ -54478,7 +54533,8 @@ var _e2echartNonSpyglassE2eChartTemplateHtml = []byte(`<html lang="en">
             ranges.push({
                 timeRange: [startDate, endDate],
                 val: val,
-                labelVal: defaultToolTip(item)
+                labelVal: defaultToolTip(item),
+                details: alertDetailsToolTip(item)
             });
         });
         for (const label in data) {
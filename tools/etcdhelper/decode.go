@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/server/options/encryptionconfig"
+	"k8s.io/apiserver/pkg/storage/value"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+// encryptedPrefix starts every value written by an encryption provider of the kube-apiserver, identity excepted.
+const encryptedPrefix = "k8s:enc:"
+
+// valueDecoder decodes the values stored by the kube-apiserver and the openshift-apiserver to JSON.
+type valueDecoder struct {
+	// transformers decrypt the values, the provider of a value is tried until one succeeds.
+	transformers []value.Transformer
+	decoder      runtime.Decoder
+	encoder      runtime.Encoder
+}
+
+// newValueDecoder returns a decoder for the values of the kube and openshift APIs.  Encrypted values are decrypted with
+// the providers of the kube-apiserver EncryptionConfiguration in encryptionConfigFile, when it is set.
+func newValueDecoder(encryptionConfigFile string) (*valueDecoder, error) {
+	d := &valueDecoder{
+		decoder: scheme.Codecs.UniversalDeserializer(),
+		encoder: jsonserializer.NewSerializer(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, false),
+	}
+	if len(encryptionConfigFile) == 0 {
+		return d, nil
+	}
+	encryptionConfig, err := encryptionconfig.LoadEncryptionConfig(context.Background(), encryptionConfigFile, false, "etcdhelper")
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the encryption config: %w", err)
+	}
+	for _, transformer := range encryptionConfig.Transformers {
+		d.transformers = append(d.transformers, transformer)
+	}
+	return d, nil
+}
+
+// decode returns the object stored under key as JSON, with its group version kind.  Protobuf values of the known
+// schemes and JSON values, like custom resources, are decoded.
+func (d *valueDecoder) decode(key string, data []byte) (string, []byte, error) {
+	if bytes.HasPrefix(data, []byte(encryptedPrefix)) {
+		decrypted, err := d.decrypt(key, data)
+		if err != nil {
+			return "", nil, err
+		}
+		data = decrypted
+	}
+
+	obj, gvk, err := d.decoder.Decode(data, nil, nil)
+	if err == nil {
+		objJSON := &bytes.Buffer{}
+		if err := d.encoder.Encode(obj, objJSON); err != nil {
+			return "", nil, fmt.Errorf("unable to encode %s as JSON: %w", key, err)
+		}
+		return gvk.String(), bytes.TrimSpace(objJSON.Bytes()), nil
+	}
+	if !json.Valid(data) {
+		return "", nil, fmt.Errorf("unable to decode %s: %w", key, err)
+	}
+	// the schemes only know the built in APIs, custom resources are stored as JSON.
+	unstructuredObj := &unstructured.Unstructured{}
+	if err := unstructuredObj.UnmarshalJSON(data); err != nil {
+		return "", nil, fmt.Errorf("unable to decode %s: %w", key, err)
+	}
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, data); err != nil {
+		return "", nil, fmt.Errorf("unable to decode %s: %w", key, err)
+	}
+	return unstructuredObj.GroupVersionKind().String(), compacted.Bytes(), nil
+}
+
+func (d *valueDecoder) decrypt(key string, data []byte) ([]byte, error) {
+	if len(d.transformers) == 0 {
+		return nil, fmt.Errorf("%s is encrypted, the encryption config of the kube-apiserver is required to decrypt it", key)
+	}
+	// the key is the authenticated data of the encryption providers.
+	errs := []string{}
+	for _, transformer := range d.transformers {
+		decrypted, _, err := transformer.TransformFromStorage(context.Background(), data, value.DefaultContext(key))
+		if err == nil {
+			return decrypted, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("unable to decrypt %s: %s", key, strings.Join(sets.NewString(errs...).List(), ", "))
+}
+
+// etcdKey is the location of an object in the keys written by the apiservers:
+// /<prefix>/[<group>/]<resource>/[<namespace>/]<name>, unless the resource is in storagePaths.
+type etcdKey struct {
+	Group     string
+	Resource  string
+	Namespace string
+	Name      string
+}
+
+// storagePaths are the resources that are not stored under their own name, by the prefix of their apiserver and the
+// path that replaces /[<group>/]<resource>.  The kube-apiserver stores the built in resources without their group,
+// the openshift-apiserver and the oauth-apiserver store theirs without their group under /openshift.io.
+var storagePaths = map[string]map[string]etcdKey{
+	"kubernetes.io": {
+		"services/specs":     {Resource: "services"},
+		"services/endpoints": {Resource: "endpoints"},
+		"minions":            {Resource: "nodes"},
+		"controllers":        {Resource: "replicationcontrollers"},
+		"ingress":            {Resource: "ingresses"},
+	},
+	"openshift.io": {
+		"buildconfigs":               {Group: "build.openshift.io", Resource: "buildconfigs"},
+		"builds":                     {Group: "build.openshift.io", Resource: "builds"},
+		"deploymentconfigs":          {Group: "apps.openshift.io", Resource: "deploymentconfigs"},
+		"images":                     {Group: "image.openshift.io", Resource: "images"},
+		"imagestreams":               {Group: "image.openshift.io", Resource: "imagestreams"},
+		"oauth/accesstokens":         {Group: "oauth.openshift.io", Resource: "oauthaccesstokens"},
+		"oauth/authorizetokens":      {Group: "oauth.openshift.io", Resource: "oauthauthorizetokens"},
+		"oauth/clientauthorizations": {Group: "oauth.openshift.io", Resource: "oauthclientauthorizations"},
+		"oauth/clients":              {Group: "oauth.openshift.io", Resource: "oauthclients"},
+		"rangeallocations":           {Group: "security.openshift.io", Resource: "rangeallocations"},
+		"routes":                     {Group: "route.openshift.io", Resource: "routes"},
+		"brokertemplateinstances":    {Group: "template.openshift.io", Resource: "brokertemplateinstances"},
+		"templateinstances":          {Group: "template.openshift.io", Resource: "templateinstances"},
+		"templates":                  {Group: "template.openshift.io", Resource: "templates"},
+		"groups":                     {Group: "user.openshift.io", Resource: "groups"},
+		"useridentities":             {Group: "user.openshift.io", Resource: "identities"},
+		"users":                      {Group: "user.openshift.io", Resource: "users"},
+	},
+}
+
+// parseKey returns the location of the object stored under key.  Custom resources have their group in the key, it is
+// recognized by its dots.  Keys that are not objects, like /kubernetes.io/health, are not parsed.
+func parseKey(key string) (etcdKey, bool) {
+	segments := strings.Split(strings.Trim(key, "/"), "/")
+	if len(segments) < 3 {
+		return etcdKey{}, false
+	}
+	prefix, segments := segments[0], segments[1:]
+	ret, ok := etcdKey{}, false
+	for _, pathLength := range []int{2, 1} {
+		if pathLength >= len(segments) {
+			continue
+		}
+		if ret, ok = storagePaths[prefix][strings.Join(segments[:pathLength], "/")]; ok {
+			segments = segments[pathLength:]
+			break
+		}
+	}
+	if !ok {
+		if strings.Contains(segments[0], ".") {
+			ret.Group = segments[0]
+			segments = segments[1:]
+		}
+		if len(segments) == 0 {
+			return etcdKey{}, false
+		}
+		ret.Resource, segments = segments[0], segments[1:]
+	}
+	switch len(segments) {
+	case 1:
+		ret.Name = segments[0]
+	case 2:
+		ret.Namespace, ret.Name = segments[0], segments[1]
+	default:
+		return etcdKey{}, false
+	}
+	return ret, true
+}
+
+// keyFilter selects keys by the resource and the namespace of their object.
+type keyFilter struct {
+	// resources are like secrets, or clusteroperators.config.openshift.io for custom resources.
+	resources sets.String
+	namespace string
+}
+
+func newKeyFilter(resources, namespace string) keyFilter {
+	ret := keyFilter{resources: sets.NewString(), namespace: namespace}
+	for _, resource := range strings.Split(resources, ",") {
+		if resource = strings.TrimSpace(resource); len(resource) > 0 {
+			ret.resources.Insert(resource)
+		}
+	}
+	return ret
+}
+
+func (f keyFilter) matches(key string) bool {
+	if len(f.resources) == 0 && len(f.namespace) == 0 {
+		return true
+	}
+	parsed, ok := parseKey(key)
+	if !ok {
+		// we cannot tell what is stored under the key, it is kept rather than silently dropped.
+		return true
+	}
+	if len(f.namespace) > 0 && parsed.Namespace != f.namespace {
+		return false
+	}
+	if len(f.resources) == 0 {
+		return true
+	}
+	resource := parsed.Resource
+	if len(parsed.Group) > 0 {
+		resource += "." + parsed.Group
+	}
+	return f.resources.Has(resource)
+}
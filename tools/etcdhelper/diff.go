@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// readDump reads the output of dump in JSON or YAML.  Its values are normalized to compare them regardless of their
+// format, indentation and key order.  Dumps written before the values were nested objects hold them as JSON strings.
+func readDump(filename string) (map[string]etcd3kv, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is YAML, so both outputs of dump are read the same way.
+	content, err = yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the dump %s: %w", filename, err)
+	}
+	kvData := []etcd3kv{}
+	if err := json.Unmarshal(content, &kvData); err != nil {
+		return nil, fmt.Errorf("unable to read the dump %s: %w", filename, err)
+	}
+	ret := map[string]etcd3kv{}
+	for _, kv := range kvData {
+		var stringValue string
+		if err := json.Unmarshal(kv.Value, &stringValue); err == nil {
+			kv.Value = json.RawMessage(stringValue)
+		}
+		if len(kv.Value) > 0 {
+			normalized, err := normalizeValue(kv.Value)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the value of %s in %s: %w", kv.Key, filename, err)
+			}
+			kv.Value = normalized
+		}
+		ret[kv.Key] = kv
+	}
+	return ret, nil
+}
+
+// normalizeValue compacts the value and sorts its keys, numbers are kept as they are.
+func normalizeValue(value json.RawMessage) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	var obj interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// diffDumps writes the keys added, removed and changed between two dumps, with their revisions.  The values of changed
+// keys are compared field by field.
+func diffDumps(out io.Writer, oldKVs, newKVs map[string]etcd3kv, filter keyFilter) {
+	keys := sets.StringKeySet(oldKVs).Union(sets.StringKeySet(newKVs))
+	for _, key := range keys.List() {
+		if !filter.matches(key) {
+			continue
+		}
+		oldKV, inOld := oldKVs[key]
+		newKV, inNew := newKVs[key]
+		switch {
+		case !inOld:
+			fmt.Fprintf(out, "+ %s %s\n", key, revisions(newKV))
+		case !inNew:
+			fmt.Fprintf(out, "- %s %s\n", key, revisions(oldKV))
+		case oldKV.ModRevision == newKV.ModRevision && string(oldKV.Value) == string(newKV.Value):
+			continue
+		default:
+			changes := []string{}
+			if oldKV.CreateRevision != newKV.CreateRevision {
+				// the key was deleted and created again between the dumps.
+				changes = append(changes, fmt.Sprintf("create_revision=%d->%d", oldKV.CreateRevision, newKV.CreateRevision))
+			}
+			changes = append(changes,
+				fmt.Sprintf("mod_revision=%d->%d", oldKV.ModRevision, newKV.ModRevision),
+				fmt.Sprintf("version=%d->%d", oldKV.Version, newKV.Version))
+			if oldKV.Lease != newKV.Lease {
+				changes = append(changes, fmt.Sprintf("lease=%d->%d", oldKV.Lease, newKV.Lease))
+			}
+			fmt.Fprintf(out, "~ %s %s\n", key, strings.Join(changes, " "))
+
+			valueDiff, err := diffValues(oldKV.Value, newKV.Value)
+			if err != nil {
+				// the revisions are still worth seeing, and so are the other keys.
+				valueDiff = fmt.Sprintf("unable to compare the values: %v", err)
+			}
+			for _, line := range strings.Split(strings.TrimRight(valueDiff, "\n"), "\n") {
+				if len(line) > 0 {
+					fmt.Fprintf(out, "    %s\n", line)
+				}
+			}
+		}
+	}
+}
+
+func revisions(kv etcd3kv) string {
+	ret := fmt.Sprintf("create_revision=%d mod_revision=%d version=%d", kv.CreateRevision, kv.ModRevision, kv.Version)
+	if kv.Lease != 0 {
+		ret += fmt.Sprintf(" lease=%d", kv.Lease)
+	}
+	return ret
+}
+
+// diffValues compares the values field by field.  A key without a value, which dump writes when the value is empty, is
+// compared as null.
+func diffValues(oldValue, newValue json.RawMessage) (string, error) {
+	var oldObj, newObj interface{}
+	if len(oldValue) > 0 {
+		if err := json.Unmarshal(oldValue, &oldObj); err != nil {
+			return "", err
+		}
+	}
+	if len(newValue) > 0 {
+		if err := json.Unmarshal(newValue, &newObj); err != nil {
+			return "", err
+		}
+	}
+	return cmp.Diff(oldObj, newObj), nil
+}
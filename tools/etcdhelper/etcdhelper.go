@@ -10,8 +10,8 @@ import (
 	"os"
 	"time"
 
-	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/yaml"
 
 	"go.etcd.io/etcd/client/pkg/v3/transport"
 	"go.etcd.io/etcd/client/v3"
@@ -25,15 +25,19 @@ func init() {
 }
 
 func main() {
-	var endpoint, keyFile, certFile, caFile string
+	var endpoint, keyFile, certFile, caFile, encryptionConfigFile, output, resources, namespace string
 	flag.StringVar(&endpoint, "endpoint", "https://127.0.0.1:2379", "etcd endpoint.")
 	flag.StringVar(&keyFile, "key", "", "TLS client key.")
 	flag.StringVar(&certFile, "cert", "", "TLS client certificate.")
 	flag.StringVar(&caFile, "cacert", "", "Server TLS CA certificate.")
+	flag.StringVar(&encryptionConfigFile, "encryption-config", "", "EncryptionConfiguration of the kube-apiserver to decrypt encrypted values with.")
+	flag.StringVar(&output, "output", "json", "Output format of get and dump: json or yaml.")
+	flag.StringVar(&resources, "resource", "", "Comma separated resources to ls, dump or diff, like secrets or clusteroperators.config.openshift.io.")
+	flag.StringVar(&namespace, "namespace", "", "Namespace to ls, dump or diff.")
 	flag.Parse()
 
 	if flag.NArg() == 0 {
-		fmt.Fprint(os.Stderr, "ERROR: you need to specify action: dump or ls [<key>] or get <key>\n")
+		fmt.Fprint(os.Stderr, "ERROR: you need to specify action: dump or ls [<key>] or get <key> or diff <old dump> <new dump>\n")
 		os.Exit(1)
 	}
 	if flag.Arg(0) == "get" && flag.NArg() == 1 {
@@ -44,11 +48,35 @@ func main() {
 		fmt.Fprint(os.Stderr, "ERROR: you cannot specify positional arguments with dump\n")
 		os.Exit(1)
 	}
+	if flag.Arg(0) == "diff" && flag.NArg() != 3 {
+		fmt.Fprint(os.Stderr, "ERROR: you need to specify <old dump> and <new dump> for diff operation\n")
+		os.Exit(1)
+	}
+	if output != "json" && output != "yaml" {
+		fmt.Fprintf(os.Stderr, "ERROR: invalid output: %s\n", output)
+		os.Exit(1)
+	}
 	action := flag.Arg(0)
 	key := ""
 	if flag.NArg() > 1 {
 		key = flag.Arg(1)
 	}
+	filter := newKeyFilter(resources, namespace)
+
+	// dumps are compared without connecting to etcd.
+	if action == "diff" {
+		if err := diff(flag.Arg(1), flag.Arg(2), filter); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: diff-ing %s and %s: %v\n", flag.Arg(1), flag.Arg(2), err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	decoder, err := newValueDecoder(encryptionConfigFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
 
 	var tlsConfig *tls.Config
 	if len(certFile) != 0 || len(keyFile) != 0 || len(caFile) != 0 {
@@ -79,11 +107,11 @@ func main() {
 
 	switch action {
 	case "ls":
-		err = listKeys(client, key)
+		err = listKeys(client, key, filter)
 	case "get":
-		err = getKey(client, key)
+		err = getKey(client, key, decoder, output)
 	case "dump":
-		err = dump(client)
+		err = dump(client, decoder, output, filter)
 	default:
 		fmt.Fprintf(os.Stderr, "ERROR: invalid action: %s\n", action)
 		os.Exit(1)
@@ -94,7 +122,7 @@ func main() {
 	}
 }
 
-func listKeys(client *clientv3.Client, key string) error {
+func listKeys(client *clientv3.Client, key string, filter keyFilter) error {
 	var resp *clientv3.GetResponse
 	var err error
 	if len(key) == 0 {
@@ -107,65 +135,59 @@ func listKeys(client *clientv3.Client, key string) error {
 	}
 
 	for _, kv := range resp.Kvs {
-		fmt.Println(string(kv.Key))
+		if filter.matches(string(kv.Key)) {
+			fmt.Println(string(kv.Key))
+		}
 	}
 
 	return nil
 }
 
-func getKey(client *clientv3.Client, key string) error {
+func getKey(client *clientv3.Client, key string, decoder *valueDecoder, output string) error {
 	resp, err := clientv3.NewKV(client).Get(context.Background(), key)
 	if err != nil {
 		return err
 	}
 
-	decoder := scheme.Codecs.UniversalDeserializer()
-	encoder := jsonserializer.NewSerializer(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, true)
-
 	for _, kv := range resp.Kvs {
-		obj, gvk, err := decoder.Decode(kv.Value, nil, nil)
+		gvk, objJSON, err := decoder.decode(string(kv.Key), kv.Value)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "WARN: unable to decode %s: %v\n", kv.Key, err)
+			fmt.Fprintf(os.Stderr, "WARN: %v\n", err)
 			continue
 		}
 		fmt.Println(gvk)
-		err = encoder.Encode(obj, os.Stdout)
+		formatted, err := format(objJSON, output)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "WARN: unable to encode %s: %v\n", kv.Key, err)
 			continue
 		}
+		fmt.Println(string(formatted))
 	}
 
 	return nil
 }
 
-func dump(client *clientv3.Client) error {
+func dump(client *clientv3.Client, decoder *valueDecoder, output string, filter keyFilter) error {
 	response, err := clientv3.NewKV(client).Get(context.Background(), "/", clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend))
 	if err != nil {
 		return err
 	}
 
 	kvData := []etcd3kv{}
-	decoder := scheme.Codecs.UniversalDeserializer()
-	encoder := jsonserializer.NewSerializer(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, false)
-	objJSON := &bytes.Buffer{}
-
 	for _, kv := range response.Kvs {
-		obj, _, err := decoder.Decode(kv.Value, nil, nil)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "WARN: error decoding value %q: %v\n", string(kv.Value), err)
+		if !filter.matches(string(kv.Key)) {
 			continue
 		}
-		objJSON.Reset()
-		if err := encoder.Encode(obj, objJSON); err != nil {
-			fmt.Fprintf(os.Stderr, "WARN: error encoding object %#v as JSON: %v", obj, err)
+		_, objJSON, err := decoder.decode(string(kv.Key), kv.Value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: %v\n", err)
 			continue
 		}
 		kvData = append(
 			kvData,
 			etcd3kv{
 				Key:            string(kv.Key),
-				Value:          string(objJSON.Bytes()),
+				Value:          objJSON,
 				CreateRevision: kv.CreateRevision,
 				ModRevision:    kv.ModRevision,
 				Version:        kv.Version,
@@ -178,17 +200,47 @@ func dump(client *clientv3.Client) error {
 	if err != nil {
 		return err
 	}
+	formatted, err := format(jsonData, output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(formatted))
 
-	fmt.Println(string(jsonData))
+	return nil
+}
 
+func diff(oldDumpFile, newDumpFile string, filter keyFilter) error {
+	oldKVs, err := readDump(oldDumpFile)
+	if err != nil {
+		return err
+	}
+	newKVs, err := readDump(newDumpFile)
+	if err != nil {
+		return err
+	}
+	diffDumps(os.Stdout, oldKVs, newKVs, filter)
 	return nil
 }
 
+// format returns the JSON in the output format.
+func format(objJSON []byte, output string) ([]byte, error) {
+	if output == "yaml" {
+		return yaml.JSONToYAML(objJSON)
+	}
+	formatted := &bytes.Buffer{}
+	if err := json.Indent(formatted, objJSON, "", "  "); err != nil {
+		return nil, err
+	}
+	return formatted.Bytes(), nil
+}
+
+// etcd3kv is a key of a dump, its value is the decoded object.
 type etcd3kv struct {
-	Key            string `json:"key,omitempty"`
-	Value          string `json:"value,omitempty"`
-	CreateRevision int64  `json:"create_revision,omitempty"`
-	ModRevision    int64  `json:"mod_revision,omitempty"`
-	Version        int64  `json:"version,omitempty"`
-	Lease          int64  `json:"lease,omitempty"`
+	Key            string          `json:"key,omitempty"`
+	Value          json.RawMessage `json:"value,omitempty"`
+	CreateRevision int64           `json:"create_revision,omitempty"`
+	ModRevision    int64           `json:"mod_revision,omitempty"`
+	Version        int64           `json:"version,omitempty"`
+	Lease          int64           `json:"lease,omitempty"`
 }
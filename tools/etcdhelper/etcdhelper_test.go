@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+	"k8s.io/apiserver/pkg/storage/value"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected etcdKey
+		ok       bool
+	}{
+		{key: "/kubernetes.io/secrets/openshift-config/pull-secret", expected: etcdKey{Resource: "secrets", Namespace: "openshift-config", Name: "pull-secret"}, ok: true},
+		{key: "/kubernetes.io/namespaces/openshift-config", expected: etcdKey{Resource: "namespaces", Name: "openshift-config"}, ok: true},
+		{key: "/kubernetes.io/config.openshift.io/clusteroperators/etcd", expected: etcdKey{Group: "config.openshift.io", Resource: "clusteroperators", Name: "etcd"}, ok: true},
+		{key: "/kubernetes.io/services/specs/default/kubernetes", expected: etcdKey{Resource: "services", Namespace: "default", Name: "kubernetes"}, ok: true},
+		{key: "/kubernetes.io/services/endpoints/default/kubernetes", expected: etcdKey{Resource: "endpoints", Namespace: "default", Name: "kubernetes"}, ok: true},
+		{key: "/kubernetes.io/minions/master-0", expected: etcdKey{Resource: "nodes", Name: "master-0"}, ok: true},
+		{key: "/openshift.io/routes/openshift-console/console", expected: etcdKey{Group: "route.openshift.io", Resource: "routes", Namespace: "openshift-console", Name: "console"}, ok: true},
+		{key: "/openshift.io/oauth/accesstokens/sha256~token", expected: etcdKey{Group: "oauth.openshift.io", Resource: "oauthaccesstokens", Name: "sha256~token"}, ok: true},
+		{key: "/kubernetes.io/health"},
+		{key: "/kubernetes.io/a/b/c/d"},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			actual, ok := parseKey(test.key)
+			if ok != test.ok || actual != test.expected {
+				t.Errorf("expected %v %v, got %v %v", test.expected, test.ok, actual, ok)
+			}
+		})
+	}
+}
+
+func TestKeyFilter(t *testing.T) {
+	keys := []string{
+		"/kubernetes.io/secrets/openshift-config/pull-secret",
+		"/kubernetes.io/secrets/openshift-etcd/etcd-client",
+		"/kubernetes.io/configmaps/openshift-config/admin-kubeconfig-client-ca",
+		"/kubernetes.io/config.openshift.io/clusteroperators/etcd",
+		"/kubernetes.io/services/specs/openshift-config/api",
+		"/kubernetes.io/services/endpoints/openshift-config/api",
+		"/kubernetes.io/minions/master-0",
+		"/openshift.io/routes/openshift-config/console",
+		"/kubernetes.io/health",
+	}
+	tests := []struct {
+		name      string
+		resources string
+		namespace string
+		expected  []string
+	}{
+		{name: "everything", expected: keys},
+		{name: "resource", resources: "secrets", expected: []string{keys[0], keys[1], keys[8]}},
+		{name: "custom resource", resources: "clusteroperators.config.openshift.io", expected: []string{keys[3], keys[8]}},
+		{name: "resources", resources: "secrets, configmaps", expected: []string{keys[0], keys[1], keys[2], keys[8]}},
+		{name: "services", resources: "services,endpoints", expected: []string{keys[4], keys[5], keys[8]}},
+		{name: "nodes", resources: "nodes", expected: []string{keys[6], keys[8]}},
+		{name: "openshift resource", resources: "routes.route.openshift.io", expected: []string{keys[7], keys[8]}},
+		{name: "namespace", namespace: "openshift-config", expected: []string{keys[0], keys[2], keys[4], keys[5], keys[7], keys[8]}},
+		{name: "resource in namespace", resources: "configmaps", namespace: "openshift-config", expected: []string{keys[2], keys[8]}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter := newKeyFilter(test.resources, test.namespace)
+			actual := []string{}
+			for _, key := range keys {
+				if filter.matches(key) {
+					actual = append(actual, key)
+				}
+			}
+			if !reflect.DeepEqual(test.expected, actual) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+const encryptionConfig = `apiVersion: apiserver.config.k8s.io/v1
+kind: EncryptionConfiguration
+resources:
+- resources:
+  - secrets
+  providers:
+  - aesgcm:
+      keys:
+      - name: key1
+        secret: %s
+  - identity: {}
+`
+
+func TestDecode(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "openshift-config"},
+		Data:       map[string][]byte{".dockerconfigjson": []byte("{}")},
+	}
+	protobufData, err := runtime.Encode(scheme.Codecs.EncoderForVersion(protobuf.NewSerializer(scheme.Scheme, scheme.Scheme), corev1.SchemeGroupVersion), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encryptionConfigFile := filepath.Join(t.TempDir(), "encryption-config")
+	encryptionKey := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	if err := os.WriteFile(encryptionConfigFile, []byte(strings.Replace(encryptionConfig, "%s", encryptionKey, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := newValueDecoder(encryptionConfigFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "/kubernetes.io/secrets/openshift-config/pull-secret"
+	encryptedData, err := decoder.transformers[0].TransformToStorage(context.Background(), protobufData, value.DefaultContext(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(encryptedData, []byte(encryptedPrefix)) {
+		t.Fatalf("expected the secret to be encrypted, got %q", encryptedData)
+	}
+
+	tests := []struct {
+		name        string
+		decoder     *valueDecoder
+		key         string
+		data        []byte
+		expectedGVK string
+		expected    map[string]interface{}
+		expectedErr string
+	}{
+		{
+			name:        "protobuf",
+			decoder:     decoder,
+			key:         key,
+			data:        protobufData,
+			expectedGVK: "/v1, Kind=Secret",
+			expected:    map[string]interface{}{"name": "pull-secret", "namespace": "openshift-config"},
+		},
+		{
+			name:        "encrypted",
+			decoder:     decoder,
+			key:         key,
+			data:        encryptedData,
+			expectedGVK: "/v1, Kind=Secret",
+			expected:    map[string]interface{}{"name": "pull-secret", "namespace": "openshift-config"},
+		},
+		{
+			name:        "encrypted without encryption config",
+			decoder:     &valueDecoder{decoder: decoder.decoder, encoder: decoder.encoder},
+			key:         key,
+			data:        encryptedData,
+			expectedErr: "the encryption config of the kube-apiserver is required",
+		},
+		{
+			name:        "encrypted under another key",
+			decoder:     decoder,
+			key:         "/kubernetes.io/secrets/openshift-config/other",
+			data:        encryptedData,
+			expectedErr: "unable to decrypt",
+		},
+		{
+			name:        "custom resource",
+			decoder:     decoder,
+			key:         "/kubernetes.io/config.openshift.io/clusterversions/version",
+			data:        []byte(`{"apiVersion": "config.openshift.io/v1", "kind": "ClusterVersion", "metadata": {"name": "version"}}` + "\n"),
+			expectedGVK: "config.openshift.io/v1, Kind=ClusterVersion",
+			expected:    map[string]interface{}{"name": "version"},
+		},
+		{
+			name:        "garbage",
+			decoder:     decoder,
+			key:         "/kubernetes.io/health",
+			data:        []byte("not an object"),
+			expectedErr: "unable to decode /kubernetes.io/health",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gvk, objJSON, err := test.decoder.decode(test.key, test.data)
+			if len(test.expectedErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gvk != test.expectedGVK {
+				t.Errorf("expected %s, got %s", test.expectedGVK, gvk)
+			}
+			obj := map[string]interface{}{}
+			if err := json.Unmarshal(objJSON, &obj); err != nil {
+				t.Fatalf("expected JSON, got %s: %v", objJSON, err)
+			}
+			metadata, _ := obj["metadata"].(map[string]interface{})
+			for field, expected := range test.expected {
+				if metadata[field] != expected {
+					t.Errorf("expected metadata.%s %v, got %s", field, expected, objJSON)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffDumps(t *testing.T) {
+	dir := t.TempDir()
+	// dumps written before the values were nested objects hold them as JSON strings.
+	oldDump := `[
+  {"key": "/kubernetes.io/secrets/ns/removed", "value": "{\"kind\":\"Secret\"}\n", "create_revision": 2, "mod_revision": 2, "version": 1},
+  {"key": "/kubernetes.io/configmaps/ns/unchanged", "value": "{\"kind\":\"ConfigMap\"}", "create_revision": 3, "mod_revision": 3, "version": 1},
+  {"key": "/kubernetes.io/configmaps/ns/changed", "value": "{\"kind\":\"ConfigMap\",\"data\":{\"a\":\"1\"}}", "create_revision": 4, "mod_revision": 4, "version": 1},
+  {"key": "/kubernetes.io/configmaps/ns/empty", "create_revision": 5, "mod_revision": 5, "version": 1}
+]`
+	newDump := `[
+  {"key": "/kubernetes.io/configmaps/ns/unchanged", "value": {"kind": "ConfigMap"}, "create_revision": 3, "mod_revision": 3, "version": 1},
+  {"key": "/kubernetes.io/configmaps/ns/changed", "value": {"kind": "ConfigMap", "data": {"a": "2"}}, "create_revision": 4, "mod_revision": 7, "version": 2},
+  {"key": "/kubernetes.io/configmaps/ns/empty", "value": {"kind": "ConfigMap"}, "create_revision": 5, "mod_revision": 9, "version": 2},
+  {"key": "/kubernetes.io/configmaps/other/added", "value": {"kind": "ConfigMap"}, "create_revision": 8, "mod_revision": 8, "version": 1, "lease": 5}
+]`
+	// dump -output yaml writes the same dump as YAML.
+	newDumpYAML, err := yaml.JSONToYAML([]byte(newDump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldDumpFile := filepath.Join(dir, "old.json")
+	if err := os.WriteFile(oldDumpFile, []byte(oldDump), 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldKVs, err := readDump(oldDumpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKVsByFormat := map[string]map[string]etcd3kv{}
+	for format, content := range map[string][]byte{"json": []byte(newDump), "yaml": newDumpYAML} {
+		newDumpFile := filepath.Join(dir, "new."+format)
+		if err := os.WriteFile(newDumpFile, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if newKVsByFormat[format], err = readDump(newDumpFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		filter   keyFilter
+		expected []string
+	}{
+		{
+			name:   "everything",
+			filter: newKeyFilter("", ""),
+			expected: []string{
+				"~ /kubernetes.io/configmaps/ns/changed mod_revision=4->7 version=1->2",
+				"~ /kubernetes.io/configmaps/ns/empty mod_revision=5->9 version=1->2",
+				"+ /kubernetes.io/configmaps/other/added create_revision=8 mod_revision=8 version=1 lease=5",
+				"- /kubernetes.io/secrets/ns/removed create_revision=2 mod_revision=2 version=1",
+			},
+		},
+		{
+			name:   "namespace",
+			filter: newKeyFilter("", "other"),
+			expected: []string{
+				"+ /kubernetes.io/configmaps/other/added create_revision=8 mod_revision=8 version=1 lease=5",
+			},
+		},
+		{
+			name:   "resource",
+			filter: newKeyFilter("secrets", ""),
+			expected: []string{
+				"- /kubernetes.io/secrets/ns/removed create_revision=2 mod_revision=2 version=1",
+			},
+		},
+	}
+	for _, test := range tests {
+		for format, newKVs := range newKVsByFormat {
+			t.Run(test.name+" "+format, func(t *testing.T) {
+				out := &bytes.Buffer{}
+				diffDumps(out, oldKVs, newKVs, test.filter)
+				actual := []string{}
+				valueChanged := false
+				for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+					if strings.HasPrefix(line, "    ") {
+						valueChanged = valueChanged || strings.Contains(line, `"2"`)
+						if strings.Contains(line, "unable to compare") {
+							t.Errorf("expected the values to be compared, got %s", line)
+						}
+						continue
+					}
+					actual = append(actual, line)
+				}
+				if !reflect.DeepEqual(test.expected, actual) {
+					t.Errorf("expected\n%s\ngot\n%s", strings.Join(test.expected, "\n"), out.String())
+				}
+				if strings.HasPrefix(test.expected[0], "~") && !valueChanged {
+					t.Errorf("expected the changed value to be shown, got\n%s", out.String())
+				}
+			})
+		}
+	}
+}